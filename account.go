@@ -0,0 +1,531 @@
+package main
+
+import (
+	"cosmetics_catalog/auth"
+	"cosmetics_catalog/mailer"
+	"cosmetics_catalog/models"
+	"cosmetics_catalog/repositories"
+	"cosmetics_catalog/session"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// customerSessionKey ключ ID вошедшего покупателя в данных сессии
+const customerSessionKey = "customer_id"
+
+// Сроки действия одноразовых ссылок из писем
+const (
+	verifyEmailTTL   = 24 * time.Hour
+	resetPasswordTTL = time.Hour
+)
+
+// Страница аккаунта: профиль, адреса, заказы и отзывы
+func (app *App) handleAccount(w http.ResponseWriter, r *http.Request) {
+	customer := app.requireCustomer(w, r)
+	if customer == nil {
+		return
+	}
+
+	addresses, err := app.customers.GetAddresses(customer.ID)
+	if err != nil {
+		http.Error(w, "Ошибка получения адресов", http.StatusInternalServerError)
+		return
+	}
+
+	orders, err := app.orders.GetByCustomer(customer.ID)
+	if err != nil {
+		http.Error(w, "Ошибка получения заказов", http.StatusInternalServerError)
+		return
+	}
+
+	reviews, err := app.reviews.GetByCustomer(customer.ID)
+	if err == nil {
+		err = app.loadReviewProducts(reviews)
+	}
+	if err != nil {
+		http.Error(w, "Ошибка получения отзывов", http.StatusInternalServerError)
+		return
+	}
+
+	// Сообщения после перенаправления
+	var message string
+	switch r.URL.Query().Get("done") {
+	case "verified":
+		message = "Email подтвержден"
+	case "profile":
+		message = "Профиль сохранен"
+	case "address":
+		message = "Адресная книга обновлена"
+	case "order":
+		message = "Заказ оформлен"
+	}
+
+	data := struct {
+		Customer  *models.Customer
+		Addresses []models.Address
+		Orders    []models.Order
+		Reviews   []models.Review
+		Message   string
+	}{
+		Customer:  customer,
+		Addresses: addresses,
+		Orders:    orders,
+		Reviews:   reviews,
+		Message:   message,
+	}
+
+	app.renderTemplate(w, "account.html", data)
+}
+
+// loadReviewProducts подставляет в отзывы продукты из каталога
+func (app *App) loadReviewProducts(reviews []models.Review) error {
+	ids := make([]uint, len(reviews))
+	for i, review := range reviews {
+		ids[i] = review.ProductID
+	}
+	products, err := app.products.GetByIDs(ids)
+	if err != nil {
+		return err
+	}
+
+	byID := make(map[uint]models.Product, len(products))
+	for _, product := range products {
+		byID[product.ID] = product
+	}
+	for i := range reviews {
+		reviews[i].Product = byID[reviews[i].ProductID]
+	}
+	return nil
+}
+
+// Регистрация покупателя
+func (app *App) handleRegister(w http.ResponseWriter, r *http.Request) {
+	type form struct {
+		Email string
+		Name  string
+		Error string
+	}
+
+	if r.Method != http.MethodPost {
+		app.renderTemplate(w, "account_register.html", form{})
+		return
+	}
+
+	data := form{
+		Email: strings.TrimSpace(r.FormValue("email")),
+		Name:  strings.TrimSpace(r.FormValue("name")),
+	}
+	password := r.FormValue("password")
+
+	// Проверяем введенные данные
+	if _, err := mail.ParseAddress(data.Email); err != nil {
+		data.Error = "Введите корректный email"
+	} else if password != r.FormValue("password_confirm") {
+		data.Error = "Пароли не совпадают"
+	}
+	if data.Error != "" {
+		app.renderTemplate(w, "account_register.html", data)
+		return
+	}
+
+	hash, err := auth.HashPassword(password)
+	if errors.Is(err, auth.ErrPasswordTooShort) {
+		data.Error = fmt.Sprintf("Пароль должен быть не короче %d символов", auth.MinPasswordLength)
+		app.renderTemplate(w, "account_register.html", data)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Ошибка регистрации", http.StatusInternalServerError)
+		return
+	}
+
+	customer, err := app.customers.GetByEmail(data.Email)
+	switch {
+	case err == nil && customer.IsVerified():
+		data.Error = "Пользователь с таким email уже зарегистрирован"
+		app.renderTemplate(w, "account_register.html", data)
+		return
+
+	case err == nil:
+		// Email не подтвержден: только отправляем письмо повторно. Имя и пароль
+		// не меняются, иначе любой мог бы задать пароль для чужой почты
+		// до того, как владелец перейдет по ссылке
+
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		customer = &models.Customer{Email: data.Email, Name: data.Name, PasswordHash: hash}
+		if err := app.customers.Create(customer); err != nil {
+			http.Error(w, "Ошибка регистрации", http.StatusInternalServerError)
+			return
+		}
+
+	default:
+		http.Error(w, "Ошибка регистрации", http.StatusInternalServerError)
+		return
+	}
+
+	if err := app.sendVerificationEmail(customer); err != nil {
+		log.Printf("Ошибка отправки письма подтверждения покупателю %d: %v", customer.ID, err)
+		http.Error(w, "Не удалось отправить письмо", http.StatusInternalServerError)
+		return
+	}
+
+	app.renderMessage(w, "Подтвердите email",
+		"Мы отправили письмо на "+customer.Email+". Перейдите по ссылке из письма, чтобы завершить регистрацию.")
+}
+
+// Подтверждение email по ссылке из письма
+func (app *App) handleVerifyEmail(w http.ResponseWriter, r *http.Request) {
+	customer, err := app.customers.UseToken(r.URL.Query().Get("token"), models.TokenPurposeVerifyEmail)
+	if errors.Is(err, repositories.ErrInvalidToken) {
+		app.renderMessage(w, "Ссылка недействительна", "Ссылка устарела или уже использована. Зарегистрируйтесь еще раз, чтобы получить новое письмо.")
+		return
+	}
+	if err != nil {
+		http.Error(w, "Ошибка подтверждения email", http.StatusInternalServerError)
+		return
+	}
+
+	if err := app.customers.MarkVerified(customer.ID); err != nil {
+		http.Error(w, "Ошибка подтверждения email", http.StatusInternalServerError)
+		return
+	}
+
+	if err := app.loginCustomer(w, r, customer); err != nil {
+		http.Error(w, "Ошибка входа", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, "/account?done=verified", http.StatusSeeOther)
+}
+
+// Вход в аккаунт
+func (app *App) handleLogin(w http.ResponseWriter, r *http.Request) {
+	type form struct {
+		Email string
+		Next  string
+		Error string
+	}
+
+	data := form{Next: safeRedirect(r.FormValue("next"), "/account")}
+
+	if r.Method != http.MethodPost {
+		app.renderTemplate(w, "account_login.html", data)
+		return
+	}
+
+	data.Email = strings.TrimSpace(r.FormValue("email"))
+
+	customer, err := app.customers.GetByEmail(data.Email)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		http.Error(w, "Ошибка входа", http.StatusInternalServerError)
+		return
+	}
+	if err != nil || !auth.CheckPassword(customer.PasswordHash, r.FormValue("password")) {
+		data.Error = "Неверный email или пароль"
+		app.renderTemplate(w, "account_login.html", data)
+		return
+	}
+
+	if !customer.IsVerified() {
+		if err := app.sendVerificationEmail(customer); err != nil {
+			log.Printf("Ошибка отправки письма подтверждения покупателю %d: %v", customer.ID, err)
+		}
+		data.Error = "Email не подтвержден. Мы отправили письмо со ссылкой повторно"
+		app.renderTemplate(w, "account_login.html", data)
+		return
+	}
+
+	if err := app.loginCustomer(w, r, customer); err != nil {
+		http.Error(w, "Ошибка входа", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, data.Next, http.StatusSeeOther)
+}
+
+// Выход из аккаунта
+func (app *App) handleLogout(w http.ResponseWriter, r *http.Request) {
+	if err := app.sessions.Delete(r, customerSessionKey); err != nil {
+		http.Error(w, "Ошибка выхода", http.StatusInternalServerError)
+		return
+	}
+	if err := app.sessions.Regenerate(w, r); err != nil {
+		http.Error(w, "Ошибка выхода", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, "/catalog/", http.StatusSeeOther)
+}
+
+// Запрос ссылки для сброса пароля
+func (app *App) handleForgotPassword(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		app.renderTemplate(w, "account_forgot.html", nil)
+		return
+	}
+
+	email := strings.TrimSpace(r.FormValue("email"))
+
+	customer, err := app.customers.GetByEmail(email)
+	switch {
+	case err == nil:
+		token, err := app.customers.CreateToken(customer.ID, models.TokenPurposeResetPassword, resetPasswordTTL)
+		if err != nil {
+			http.Error(w, "Ошибка сброса пароля", http.StatusInternalServerError)
+			return
+		}
+
+		err = app.mailer.Send(mailer.Message{
+			To:      customer.Email,
+			Subject: "Сброс пароля",
+			Body: fmt.Sprintf("Здравствуйте!\n\nЧтобы задать новый пароль, перейдите по ссылке:\n%s\n\nСсылка действует 1 час. Если вы не запрашивали сброс пароля, просто проигнорируйте это письмо.",
+				app.absoluteURL("/account/password/reset?token="+url.QueryEscape(token))),
+		})
+		if err != nil {
+			log.Printf("Ошибка отправки письма сброса пароля покупателю %d: %v", customer.ID, err)
+		}
+
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		http.Error(w, "Ошибка сброса пароля", http.StatusInternalServerError)
+		return
+	}
+
+	// Не сообщаем, существует ли аккаунт с таким email
+	app.renderMessage(w, "Проверьте почту",
+		"Если аккаунт с адресом "+email+" существует, мы отправили на него ссылку для сброса пароля.")
+}
+
+// Установка нового пароля по ссылке из письма
+func (app *App) handleResetPassword(w http.ResponseWriter, r *http.Request) {
+	type form struct {
+		Token string
+		Error string
+	}
+
+	data := form{Token: r.FormValue("token")}
+
+	if r.Method != http.MethodPost {
+		app.renderTemplate(w, "account_reset.html", data)
+		return
+	}
+
+	// Пароль проверяем до погашения токена, чтобы ошибка ввода не сжигала ссылку
+	password := r.FormValue("password")
+	if password != r.FormValue("password_confirm") {
+		data.Error = "Пароли не совпадают"
+		app.renderTemplate(w, "account_reset.html", data)
+		return
+	}
+
+	hash, err := auth.HashPassword(password)
+	if errors.Is(err, auth.ErrPasswordTooShort) {
+		data.Error = fmt.Sprintf("Пароль должен быть не короче %d символов", auth.MinPasswordLength)
+		app.renderTemplate(w, "account_reset.html", data)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Ошибка сброса пароля", http.StatusInternalServerError)
+		return
+	}
+
+	customer, err := app.customers.UseToken(data.Token, models.TokenPurposeResetPassword)
+	if errors.Is(err, repositories.ErrInvalidToken) {
+		app.renderMessage(w, "Ссылка недействительна", "Ссылка устарела или уже использована. Запросите сброс пароля еще раз.")
+		return
+	}
+	if err != nil {
+		http.Error(w, "Ошибка сброса пароля", http.StatusInternalServerError)
+		return
+	}
+
+	if err := app.customers.UpdatePassword(customer.ID, hash); err != nil {
+		http.Error(w, "Ошибка сброса пароля", http.StatusInternalServerError)
+		return
+	}
+
+	// Переход по ссылке из письма подтверждает владение адресом
+	if err := app.customers.MarkVerified(customer.ID); err != nil {
+		http.Error(w, "Ошибка сброса пароля", http.StatusInternalServerError)
+		return
+	}
+
+	app.renderMessage(w, "Пароль изменен", "Теперь вы можете войти с новым паролем.")
+}
+
+// Сохранение профиля покупателя
+func (app *App) handleProfile(w http.ResponseWriter, r *http.Request) {
+	customer := app.requireCustomer(w, r)
+	if customer == nil {
+		return
+	}
+
+	customer.Name = strings.TrimSpace(r.FormValue("name"))
+	customer.Phone = strings.TrimSpace(r.FormValue("phone"))
+	if err := app.customers.UpdateProfile(customer); err != nil {
+		http.Error(w, "Ошибка сохранения профиля", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/account?done=profile", http.StatusSeeOther)
+}
+
+// Изменение адресной книги: добавление, удаление и выбор адреса по умолчанию
+func (app *App) handleAddresses(w http.ResponseWriter, r *http.Request) {
+	customer := app.requireCustomer(w, r)
+	if customer == nil {
+		return
+	}
+
+	var err error
+	switch r.FormValue("action") {
+	case "add":
+		address := models.Address{
+			CustomerID: customer.ID,
+			Recipient:  strings.TrimSpace(r.FormValue("recipient")),
+			Phone:      strings.TrimSpace(r.FormValue("phone")),
+			City:       strings.TrimSpace(r.FormValue("city")),
+			Street:     strings.TrimSpace(r.FormValue("street")),
+			PostalCode: strings.TrimSpace(r.FormValue("postal_code")),
+			IsDefault:  r.FormValue("is_default") != "",
+		}
+		if address.Recipient == "" || address.City == "" || address.Street == "" {
+			http.Error(w, "Заполните получателя, город и адрес", http.StatusBadRequest)
+			return
+		}
+		err = app.customers.CreateAddress(&address)
+
+	case "default", "delete":
+		addressID, parseErr := strconv.ParseUint(r.FormValue("address_id"), 10, 64)
+		if parseErr != nil {
+			http.Error(w, "Некорректный идентификатор адреса", http.StatusBadRequest)
+			return
+		}
+		if r.FormValue("action") == "default" {
+			err = app.customers.SetDefaultAddress(customer.ID, uint(addressID))
+		} else {
+			err = app.customers.DeleteAddress(customer.ID, uint(addressID))
+		}
+
+	default:
+		http.Error(w, "Неизвестное действие", http.StatusBadRequest)
+		return
+	}
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Ошибка сохранения адреса", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/account?done=address", http.StatusSeeOther)
+}
+
+// currentCustomer возвращает вошедшего покупателя или nil для гостя
+func (app *App) currentCustomer(r *http.Request) *models.Customer {
+	var customerID uint
+	if err := app.sessions.Get(r, customerSessionKey, &customerID); err != nil {
+		log.Printf("Ошибка чтения сессии: %v", err)
+		return nil
+	}
+	if customerID == 0 {
+		return nil
+	}
+
+	customer, err := app.customers.GetByID(customerID)
+	if err != nil {
+		return nil
+	}
+	return customer
+}
+
+// requireCustomer возвращает вошедшего покупателя,
+// а гостя перенаправляет на страницу входа и возвращает nil
+func (app *App) requireCustomer(w http.ResponseWriter, r *http.Request) *models.Customer {
+	customer := app.currentCustomer(r)
+	if customer == nil {
+		next := "/account"
+		if r.Method == http.MethodGet {
+			next = r.URL.RequestURI()
+		}
+		http.Redirect(w, r, "/account/login?next="+url.QueryEscape(next), http.StatusSeeOther)
+	}
+	return customer
+}
+
+// loginCustomer привязывает сессию к покупателю и переносит в аккаунт избранное гостя
+func (app *App) loginCustomer(w http.ResponseWriter, r *http.Request, customer *models.Customer) error {
+	if sessionID := session.Peek(r); sessionID != "" {
+		if err := app.wishlist.AssignToCustomer(sessionID, customer.ID); err != nil {
+			return err
+		}
+	}
+
+	if err := app.sessions.Set(w, r, customerSessionKey, customer.ID); err != nil {
+		return err
+	}
+	return app.sessions.Regenerate(w, r)
+}
+
+// sendVerificationEmail отправляет покупателю ссылку для подтверждения email
+func (app *App) sendVerificationEmail(customer *models.Customer) error {
+	token, err := app.customers.CreateToken(customer.ID, models.TokenPurposeVerifyEmail, verifyEmailTTL)
+	if err != nil {
+		return err
+	}
+
+	return app.mailer.Send(mailer.Message{
+		To:      customer.Email,
+		Subject: "Подтверждение регистрации",
+		Body: fmt.Sprintf("Здравствуйте!\n\nЧтобы подтвердить email и завершить регистрацию, перейдите по ссылке:\n%s\n\nСсылка действует 24 часа.",
+			app.absoluteURL("/account/verify?token="+url.QueryEscape(token))),
+	})
+}
+
+// safeRedirect возвращает путь для перенаправления после входа.
+// Допускаются только пути внутри сайта
+func safeRedirect(next, fallback string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		return fallback
+	}
+	return next
+}
+
+// backURL возвращает путь страницы, с которой пришел посетитель.
+// Адрес из Referer принимается, только если он на этом же сайте, иначе fallback
+func backURL(r *http.Request, fallback string) string {
+	referer, err := url.Parse(r.Referer())
+	if err != nil || referer.Host != r.Host {
+		return fallback
+	}
+	return safeRedirect(referer.RequestURI(), fallback)
+}
+
+// absoluteURL строит абсолютную ссылку на страницу сайта только по настройкам.
+// Заголовок Host присылает клиент: по нему поддельный запрос получил бы письмо
+// со ссылкой на чужой сайт, а канонические ссылки и карта сайта зависели бы
+// от того, по какому имени пришел запрос. Без base_url письма выводятся
+// только в журнал, config.Validate это проверяет
+func (app *App) absoluteURL(path string) string {
+	return app.config.SiteURL() + path
+}
+
+// renderMessage показывает страницу с информационным сообщением
+func (app *App) renderMessage(w http.ResponseWriter, title, text string) {
+	data := struct {
+		Title string
+		Text  string
+	}{
+		Title: title,
+		Text:  text,
+	}
+	app.renderTemplate(w, "account_message.html", data)
+}
@@ -0,0 +1,458 @@
+package main
+
+import (
+	"cosmetics_catalog/auth"
+	"cosmetics_catalog/export"
+	"cosmetics_catalog/models"
+	"cosmetics_catalog/pricing"
+	"cosmetics_catalog/repositories"
+	"cosmetics_catalog/slug"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// requireAdmin пропускает к обработчику только администраторов.
+// Вход выполняется по HTTP Basic Auth с логином и паролем администратора.
+// Браузер отправляет Basic Auth и с чужих сайтов, поэтому формы,
+// пришедшие не со страниц каталога, отклоняются
+func (app *App) requireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !sameOrigin(r) {
+			http.Error(w, "Запрос отправлен с другого сайта", http.StatusForbidden)
+			return
+		}
+
+		login, password, ok := r.BasicAuth()
+		if ok {
+			admin, err := app.admins.GetByLogin(login)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				http.Error(w, "Ошибка входа", http.StatusInternalServerError)
+				return
+			}
+			if err == nil && auth.CheckPassword(admin.PasswordHash, password) {
+				next(w, r)
+				return
+			}
+		}
+
+		w.Header().Set("WWW-Authenticate", `Basic realm="admin", charset="UTF-8"`)
+		http.Error(w, "Требуется вход администратора", http.StatusUnauthorized)
+	}
+}
+
+// sameOrigin проверяет, что изменяющий запрос отправлен со страницы этого же сайта.
+// Браузеры сообщают источник в Sec-Fetch-Site, Origin или Referer. Запрос
+// без этих заголовков отклоняется: источник не подтвержден, а браузер
+// с политикой no-referrer мог отправить его со страницы другого сайта
+func sameOrigin(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+
+	if site := r.Header.Get("Sec-Fetch-Site"); site != "" {
+		return site == "same-origin" || site == "none"
+	}
+	source := r.Header.Get("Origin")
+	if source == "" {
+		source = r.Referer()
+	}
+	if source == "" {
+		return false
+	}
+	u, err := url.Parse(source)
+	return err == nil && u.Host == r.Host
+}
+
+// Выгрузка каталога в CSV или JSON Lines
+func (app *App) handleAdminExport(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = export.FormatCSV
+	}
+
+	contentType := map[string]string{
+		export.FormatCSV:   "text/csv; charset=utf-8",
+		export.FormatJSONL: "application/x-ndjson; charset=utf-8",
+	}[format]
+	if contentType == "" {
+		http.Error(w, "Неизвестный формат выгрузки", http.StatusBadRequest)
+		return
+	}
+
+	filename := "catalog-" + time.Now().Format("2006-01-02") + "." + format
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+
+	// Заголовки уже отправлены, поэтому ошибку посреди выгрузки можно только записать в лог
+	if err := export.Write(w, app.products, app.subcategories, format, app.absoluteURL("")); err != nil {
+		log.Printf("Ошибка выгрузки каталога: %v", err)
+	}
+}
+
+// Массовое изменение цен. Кнопка предпросмотра показывает новые цены,
+// кнопка применения меняет их одной транзакцией
+func (app *App) handleAdminPrices(w http.ResponseWriter, r *http.Request) {
+	type form struct {
+		BrandID       uint
+		CategoryID    uint
+		SubcategoryID uint
+		Mode          string
+		Amount        string
+		Rounding      string
+	}
+
+	data := struct {
+		Form          form
+		Brands        []models.Brand
+		Categories    []models.Category
+		Subcategories []models.Subcategory
+		Lines         []pricing.Line
+		Applied       bool
+		Error         string
+	}{Form: form{Mode: pricing.ModePercent}}
+
+	var err error
+	if data.Brands, err = app.brands.GetAll(); err != nil {
+		http.Error(w, "Ошибка загрузки брендов", http.StatusInternalServerError)
+		return
+	}
+	if data.Categories, err = app.categories.GetAll(); err != nil {
+		http.Error(w, "Ошибка загрузки категорий", http.StatusInternalServerError)
+		return
+	}
+	if data.Subcategories, err = app.subcategories.GetAll(); err != nil {
+		http.Error(w, "Ошибка загрузки подкатегорий", http.StatusInternalServerError)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		app.renderTemplate(w, "admin_prices.html", data)
+		return
+	}
+
+	data.Form = form{
+		BrandID:       formID(r, "brand_id"),
+		CategoryID:    formID(r, "category_id"),
+		SubcategoryID: formID(r, "subcategory_id"),
+		Mode:          r.FormValue("mode"),
+		Amount:        r.FormValue("amount"),
+		Rounding:      r.FormValue("rounding"),
+	}
+
+	amount, err := strconv.ParseFloat(data.Form.Amount, 64)
+	if err != nil {
+		data.Error = "Укажите величину изменения цены"
+		app.renderTemplate(w, "admin_prices.html", data)
+		return
+	}
+	update := pricing.Update{
+		Filter:   repositories.ProductFilter{BrandID: data.Form.BrandID, CategoryID: data.Form.CategoryID},
+		Mode:     data.Form.Mode,
+		Amount:   amount,
+		Rounding: data.Form.Rounding,
+	}
+
+	if data.Form.SubcategoryID != 0 {
+		subcategory, err := app.subcategories.GetByID(data.Form.SubcategoryID)
+		if err != nil {
+			data.Error = "Подкатегория не найдена"
+			app.renderTemplate(w, "admin_prices.html", data)
+			return
+		}
+		if update.Filter.SubcategoryIDs, err = app.subcategories.GetDescendantIDs(subcategory); err != nil {
+			http.Error(w, "Ошибка загрузки подкатегорий", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if r.FormValue("action") == "apply" {
+		// Применяются те изменения, которые администратор видел в предпросмотре
+		data.Lines, err = previewedLines(r, app.products, update)
+		if err == nil {
+			err = pricing.Apply(app.products, update, data.Lines)
+		}
+		data.Applied = err == nil
+		if errors.Is(err, repositories.ErrPriceChanged) {
+			data.Lines, _ = pricing.Preview(app.products, update)
+			data.Error = "Цены изменились после предпросмотра, проверьте изменение еще раз"
+			app.renderTemplate(w, "admin_prices.html", data)
+			return
+		}
+	} else {
+		data.Lines, err = pricing.Preview(app.products, update)
+	}
+	if err != nil {
+		data.Error = err.Error()
+	}
+	app.renderTemplate(w, "admin_prices.html", data)
+}
+
+// previewedLines восстанавливает изменения из полей line формы предпросмотра
+// в виде "ID:цена:цена со скидкой". Новые цены вычисляются от цен, которые
+// видел администратор: если с тех пор цены изменились, Apply их не запишет.
+// Удаленный после предпросмотра продукт дает repositories.ErrPriceChanged
+func previewedLines(r *http.Request, products repositories.Products, update pricing.Update) ([]pricing.Line, error) {
+	values := r.PostForm["line"]
+	ids := make([]uint, 0, len(values))
+	prices := make(map[uint][2]float64, len(values))
+	for _, value := range values {
+		parts := strings.Split(value, ":")
+		if len(parts) != 3 {
+			return nil, errors.New("неверные данные предпросмотра")
+		}
+		id, err := strconv.ParseUint(parts[0], 10, 64)
+		if err != nil {
+			return nil, errors.New("неверные данные предпросмотра")
+		}
+		price, err1 := strconv.ParseFloat(parts[1], 64)
+		salePrice, err2 := strconv.ParseFloat(parts[2], 64)
+		if err1 != nil || err2 != nil {
+			return nil, errors.New("неверные данные предпросмотра")
+		}
+		ids = append(ids, uint(id))
+		prices[uint(id)] = [2]float64{price, salePrice}
+	}
+
+	selected, err := products.GetByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(selected) != len(ids) {
+		return nil, repositories.ErrPriceChanged
+	}
+	for i := range selected {
+		selected[i].Price, selected[i].SalePrice = prices[selected[i].ID][0], prices[selected[i].ID][1]
+	}
+	return pricing.Plan(selected, update)
+}
+
+// formID разбирает идентификатор из поля формы. Пустое поле дает 0
+func formID(r *http.Request, name string) uint {
+	id, _ := strconv.ParseUint(r.FormValue(name), 10, 64)
+	return uint(id)
+}
+
+// slugTarget запись каталога, слаг которой редактируется в разделе администрирования
+type slugTarget struct {
+	Entity string
+	ID     uint
+	Name   string
+	Slug   string
+	URL    string
+
+	// taken сообщает, занят ли слаг другой записью того же уровня
+	taken func(candidate string) (bool, error)
+	// save сохраняет запись с новым слагом и возвращает новый адрес страницы.
+	// Прежний слаг попадает в историю через хуки моделей
+	save func(slug string) (string, error)
+}
+
+// Список брендов, категорий, подкатегорий и продуктов со ссылками на правку слагов
+func (app *App) handleAdminSlugs(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		Brands        []models.Brand
+		Categories    []models.Category
+		Subcategories []models.Subcategory
+		Products      []models.Product
+	}
+
+	var err error
+	if data.Brands, err = app.brands.GetAll(); err != nil {
+		http.Error(w, "Ошибка загрузки брендов", http.StatusInternalServerError)
+		return
+	}
+	if data.Categories, err = app.categories.GetAll(); err != nil {
+		http.Error(w, "Ошибка загрузки категорий", http.StatusInternalServerError)
+		return
+	}
+	if data.Subcategories, err = app.subcategories.GetAll(); err != nil {
+		http.Error(w, "Ошибка загрузки подкатегорий", http.StatusInternalServerError)
+		return
+	}
+	if data.Products, err = app.products.GetAll(); err != nil {
+		http.Error(w, "Ошибка загрузки продуктов", http.StatusInternalServerError)
+		return
+	}
+	app.renderTemplate(w, "admin_slugs.html", data)
+}
+
+// Правка слага записи каталога. Кнопка предпросмотра показывает слаг,
+// который будет сохранен: введенный вручную или, если поле пустое,
+// сгенерированный из названия. Кнопка сохранения записывает его
+func (app *App) handleAdminSlug(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	target, err := app.slugTarget(r.PathValue("entity"), uint(id))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Ошибка загрузки записи", http.StatusInternalServerError)
+		return
+	}
+
+	data := struct {
+		Target    *slugTarget
+		Input     string
+		Generated string
+		Preview   string
+		Saved     bool
+		Error     string
+	}{Target: target, Input: target.Slug}
+
+	// Слаг из названия показывается всегда, чтобы его можно было сравнить с текущим
+	if data.Generated, err = slug.Unique(slug.Make(target.Name), target.taken); err != nil {
+		http.Error(w, "Ошибка проверки слага", http.StatusInternalServerError)
+		return
+	}
+	if r.Method != http.MethodPost {
+		app.renderTemplate(w, "admin_slug.html", data)
+		return
+	}
+
+	data.Input = strings.ToLower(strings.TrimSpace(r.FormValue("slug")))
+	switch {
+	case data.Input == "":
+		data.Preview = data.Generated
+	case slug.Make(data.Input) != data.Input:
+		data.Error = "Слаг может содержать только латинские буквы, цифры и дефисы, например «" + slug.Make(data.Input) + "»"
+	default:
+		taken, err := target.taken(data.Input)
+		if err != nil {
+			http.Error(w, "Ошибка проверки слага", http.StatusInternalServerError)
+			return
+		}
+		if taken {
+			data.Error = "Слаг «" + data.Input + "» уже занят"
+		} else {
+			data.Preview = data.Input
+		}
+	}
+
+	if r.FormValue("action") == "save" && data.Error == "" {
+		if target.URL, err = target.save(data.Preview); err != nil {
+			data.Error = err.Error()
+		} else {
+			target.Slug = data.Preview
+			data.Input = data.Preview
+			data.Saved = true
+		}
+	}
+	app.renderTemplate(w, "admin_slug.html", data)
+}
+
+// slugTarget загружает запись каталога для правки слага.
+// entity принимает значения models.SlugEntity*
+func (app *App) slugTarget(entity string, id uint) (*slugTarget, error) {
+	target := &slugTarget{Entity: entity, ID: id}
+
+	switch entity {
+	case models.SlugEntityProduct:
+		product, err := app.products.GetByID(id)
+		if err != nil {
+			return nil, err
+		}
+		target.Name, target.Slug, target.URL = product.Name, product.Slug, product.URL()
+		target.taken = func(candidate string) (bool, error) {
+			other, err := app.products.GetBySlug(candidate)
+			return slugOwner(other.ID, err, id)
+		}
+		target.save = func(slug string) (string, error) {
+			updated := *product
+			updated.Slug = slug
+			updated.Brand, updated.Subcategory = models.Brand{}, models.Subcategory{}
+			if err := app.products.Update(&updated); err != nil {
+				return "", err
+			}
+			product.Slug = slug
+			return product.URL(), nil
+		}
+
+	case models.SlugEntityBrand:
+		brand, err := app.brands.GetByID(id)
+		if err != nil {
+			return nil, err
+		}
+		target.Name, target.Slug, target.URL = brand.Name, brand.Slug, brand.URL()
+		target.taken = func(candidate string) (bool, error) {
+			other, err := app.brands.GetBySlug(candidate)
+			return slugOwner(other.ID, err, id)
+		}
+		target.save = func(slug string) (string, error) {
+			brand.Slug = slug
+			return brand.URL(), app.brands.Update(brand)
+		}
+
+	case models.SlugEntityCategory:
+		category, err := app.categories.GetByID(id)
+		if err != nil {
+			return nil, err
+		}
+		target.Name, target.Slug, target.URL = category.Name, category.Slug, category.URL()
+		target.taken = func(candidate string) (bool, error) {
+			if app.categories.IsReserved(candidate) {
+				return true, nil
+			}
+			other, err := app.categories.GetBySlug(candidate)
+			return slugOwner(other.ID, err, id)
+		}
+		target.save = func(slug string) (string, error) {
+			category.Slug = slug
+			return category.URL(), app.categories.Update(category)
+		}
+
+	case models.SlugEntitySubcategory:
+		subcategory, err := app.subcategories.GetByID(id)
+		if err != nil {
+			return nil, err
+		}
+		// Слаг уникален среди соседних узлов, поэтому проверяется путь с тем же родителем
+		parentPath := ""
+		if i := strings.LastIndexByte(subcategory.Path, '/'); i >= 0 {
+			parentPath = subcategory.Path[:i+1]
+		}
+		target.Name, target.Slug, target.URL = subcategory.Name, subcategory.Slug, subcategory.URL()
+		target.taken = func(candidate string) (bool, error) {
+			other, err := app.subcategories.GetByPath(subcategory.CategoryID, parentPath+candidate)
+			return slugOwner(other.ID, err, id)
+		}
+		target.save = func(slug string) (string, error) {
+			updated := *subcategory
+			updated.Slug = slug
+			updated.Category, updated.Children, updated.Products = models.Category{}, nil, nil
+			if err := app.subcategories.Update(&updated); err != nil {
+				return "", err
+			}
+			subcategory.Slug, subcategory.Path = updated.Slug, updated.Path
+			return subcategory.URL(), nil
+		}
+
+	default:
+		return nil, gorm.ErrRecordNotFound
+	}
+	return target, nil
+}
+
+// slugOwner переводит результат поиска по слагу в признак занятости:
+// слаг занят, если найдена другая запись, а не редактируемая id
+func slugOwner(ownerID uint, err error, id uint) (bool, error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return ownerID != id, nil
+}
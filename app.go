@@ -0,0 +1,98 @@
+package main
+
+import (
+	"cosmetics_catalog/config"
+	"cosmetics_catalog/feeds"
+	"cosmetics_catalog/mailer"
+	"cosmetics_catalog/models"
+	"cosmetics_catalog/repositories"
+	"cosmetics_catalog/router"
+	"cosmetics_catalog/session"
+	"fmt"
+	"net/http"
+
+	"gorm.io/gorm"
+)
+
+// App веб-приложение каталога. Хранит настройки, подключение к базе,
+// репозитории и маршруты, обработчики запросов являются его методами.
+// Несколько экземпляров App могут работать в одном процессе: у каждого свои
+// настройки, сессии и кэш фидов
+type App struct {
+	config    config.Config
+	db        *gorm.DB
+	routes    *router.Router
+	sessions  *session.Store
+	mailer    mailer.Mailer
+	feedCache *feeds.Cache
+
+	products      repositories.Products
+	brands        repositories.Brands
+	categories    repositories.Categories
+	subcategories repositories.Subcategories
+	slugHistory   *repositories.SlugHistoryRepository
+	wishlist      *repositories.WishlistRepository
+	reviews       *repositories.ReviewRepository
+	customers     *repositories.CustomerRepository
+	orders        *repositories.OrderRepository
+	promoCodes    *repositories.PromoCodeRepository
+	admins        *repositories.AdminRepository
+}
+
+// NewApp создает приложение с настройками cfg поверх подключения db.
+// Каталог читается из репозиториев catalog, обычно repositories.NewCatalog(db).
+// Письма покупателям отправляются через mail
+func NewApp(cfg config.Config, db *gorm.DB, catalog repositories.Catalog, mail mailer.Mailer) (*App, error) {
+	app := &App{
+		config:    cfg,
+		db:        db,
+		sessions:  session.NewStore(db),
+		mailer:    mail,
+		feedCache: feeds.NewCache(),
+
+		products:      catalog.Products,
+		brands:        catalog.Brands,
+		categories:    catalog.Categories,
+		subcategories: catalog.Subcategories,
+		slugHistory:   repositories.NewSlugHistoryRepository(db),
+		wishlist:      repositories.NewWishlistRepository(db),
+		reviews:       repositories.NewReviewRepository(db),
+		customers:     repositories.NewCustomerRepository(db),
+		orders:        repositories.NewOrderRepository(db),
+		promoCodes:    repositories.NewPromoCodeRepository(db),
+		admins:        repositories.NewAdminRepository(db),
+	}
+
+	// Покупатели узнают о скидках на товары из избранного. Хук в контексте
+	// подключения срабатывает и при изменениях в обход репозитория продуктов:
+	// в импорте и изменении цен из раздела администрирования
+	notifier := saleNotifier{wishlist: app.wishlist, mailer: app.mailer}
+	app.products.OnSale(notifier.notify)
+	app.db = db.WithContext(models.WithSaleHook(db.Statement.Context, notifier.notify))
+
+	// Кэш товарных фидов сбрасывается при любом изменении каталога
+	if err := app.feedCache.Watch(db, &models.Product{}, &models.Brand{}, &models.Category{}, &models.Subcategory{}); err != nil {
+		return nil, fmt.Errorf("ошибка настройки кэша фидов: %w", err)
+	}
+
+	// Маршруты. Служебные разделы каталога занимают слова, недоступные для слагов
+	// категорий: их проверяют репозиторий категорий и хуки моделей в подключении app.db
+	app.routes = app.newRouter()
+	reserved := app.routes.Reserved("/catalog/")
+	app.categories.Reserve(reserved...)
+	app.db = app.db.WithContext(models.WithReservedCategorySlugs(app.db.Statement.Context, reserved...))
+
+	return app, nil
+}
+
+// reservedCategorySlugs возвращает слова, которые маршруты приложения с настройками
+// cfg занимают после /catalog/, например sales и brands. Маршруты строятся
+// без подключения к базе, поэтому список доступен и командам, не создающим App
+func reservedCategorySlugs(cfg config.Config) []string {
+	return (&App{config: cfg}).newRouter().Reserved("/catalog/")
+}
+
+// ServeHTTP передает запрос маршрутам приложения
+func (app *App) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	app.routes.ServeHTTP(w, r)
+}
@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MinPasswordLength минимальная длина пароля
+const MinPasswordLength = 8
+
+const (
+	hashScheme     = "pbkdf2-sha256"
+	hashIterations = 600000
+	saltLength     = 16
+	keyLength      = 32
+)
+
+// ErrPasswordTooShort возвращается, если пароль короче MinPasswordLength
+var ErrPasswordTooShort = fmt.Errorf("пароль должен быть не короче %d символов", MinPasswordLength)
+
+// HashPassword вычисляет хеш пароля для хранения в базе.
+// Формат: pbkdf2-sha256$<итерации>$<соль>$<хеш>
+func HashPassword(password string) (string, error) {
+	if len([]rune(password)) < MinPasswordLength {
+		return "", ErrPasswordTooShort
+	}
+
+	salt := make([]byte, saltLength)
+	rand.Read(salt)
+
+	key, err := pbkdf2.Key(sha256.New, password, salt, hashIterations, keyLength)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.Join([]string{
+		hashScheme,
+		strconv.Itoa(hashIterations),
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	}, "$"), nil
+}
+
+// CheckPassword проверяет, соответствует ли пароль сохраненному хешу
+func CheckPassword(hash, password string) bool {
+	iterations, salt, key, err := parseHash(hash)
+	if err != nil {
+		return false
+	}
+
+	actual, err := pbkdf2.Key(sha256.New, password, salt, iterations, len(key))
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare(actual, key) == 1
+}
+
+// parseHash разбирает строку хеша на составные части
+func parseHash(hash string) (int, []byte, []byte, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 4 || parts[0] != hashScheme {
+		return 0, nil, nil, errors.New("неизвестный формат хеша")
+	}
+
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil || iterations <= 0 {
+		return 0, nil, nil, errors.New("некорректное число итераций")
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	return iterations, salt, key, nil
+}
@@ -0,0 +1,27 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// tokenLength длина одноразового токена в байтах
+const tokenLength = 32
+
+// NewToken генерирует одноразовый токен для ссылки в письме.
+// Возвращает сам токен и его хеш, который сохраняется в базе
+func NewToken() (token, hash string) {
+	b := make([]byte, tokenLength)
+	rand.Read(b)
+
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, HashToken(token)
+}
+
+// HashToken вычисляет хеш токена для поиска в базе
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,385 @@
+package main
+
+import (
+	"cosmetics_catalog/models"
+	"cosmetics_catalog/promo"
+	"cosmetics_catalog/seo"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// Ключи корзины в данных сессии
+const (
+	cartSessionKey  = "cart"
+	promoSessionKey = "promo_code"
+)
+
+// maxCartQuantity максимальное количество одного товара в корзине
+const maxCartQuantity = 99
+
+// cartEntry позиция корзины, хранящаяся в сессии
+type cartEntry struct {
+	ProductID uint `json:"product_id"`
+	Quantity  int  `json:"quantity"`
+}
+
+// cartView содержимое корзины для отображения и оформления заказа
+type cartView struct {
+	Lines      []promo.Line
+	Subtotal   float64
+	Discount   float64
+	Total      float64
+	PromoCode  string
+	PromoError string
+
+	promoCodeID uint
+}
+
+// Страница корзины
+func (app *App) handleCart(w http.ResponseWriter, r *http.Request) {
+	app.renderCart(w, r, "")
+}
+
+// Добавление товара в корзину
+func (app *App) handleCartAdd(w http.ResponseWriter, r *http.Request) {
+	productID, err := strconv.ParseUint(r.FormValue("product_id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Некорректный идентификатор товара", http.StatusBadRequest)
+		return
+	}
+	if _, err := app.products.GetByID(uint(productID)); err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	quantity := 1
+	if value := r.FormValue("quantity"); value != "" {
+		if quantity, err = strconv.Atoi(value); err != nil || quantity < 1 {
+			http.Error(w, "Некорректное количество", http.StatusBadRequest)
+			return
+		}
+	}
+
+	entries, err := app.cartEntries(r)
+	if err != nil {
+		http.Error(w, "Ошибка получения корзины", http.StatusInternalServerError)
+		return
+	}
+
+	found := false
+	for i := range entries {
+		if entries[i].ProductID == uint(productID) {
+			entries[i].Quantity = min(entries[i].Quantity+quantity, maxCartQuantity)
+			found = true
+			break
+		}
+	}
+	if !found {
+		entries = append(entries, cartEntry{ProductID: uint(productID), Quantity: min(quantity, maxCartQuantity)})
+	}
+
+	if err := app.sessions.Set(w, r, cartSessionKey, entries); err != nil {
+		http.Error(w, "Ошибка сохранения корзины", http.StatusInternalServerError)
+		return
+	}
+
+	// Возвращаем посетителя на страницу, с которой он пришел
+	http.Redirect(w, r, backURL(r, "/cart"), http.StatusSeeOther)
+}
+
+// Изменение количества товара в корзине. Нулевое количество удаляет позицию
+func (app *App) handleCartUpdate(w http.ResponseWriter, r *http.Request) {
+	productID, err := strconv.ParseUint(r.FormValue("product_id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Некорректный идентификатор товара", http.StatusBadRequest)
+		return
+	}
+	quantity, err := strconv.Atoi(r.FormValue("quantity"))
+	if err != nil || quantity < 0 {
+		http.Error(w, "Некорректное количество", http.StatusBadRequest)
+		return
+	}
+
+	entries, err := app.cartEntries(r)
+	if err != nil {
+		http.Error(w, "Ошибка получения корзины", http.StatusInternalServerError)
+		return
+	}
+
+	updated := entries[:0]
+	for _, entry := range entries {
+		if entry.ProductID == uint(productID) {
+			entry.Quantity = min(quantity, maxCartQuantity)
+		}
+		if entry.Quantity > 0 {
+			updated = append(updated, entry)
+		}
+	}
+
+	if err := app.sessions.Set(w, r, cartSessionKey, updated); err != nil {
+		http.Error(w, "Ошибка сохранения корзины", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, "/cart", http.StatusSeeOther)
+}
+
+// Применение или отмена промокода
+func (app *App) handleCartPromo(w http.ResponseWriter, r *http.Request) {
+	if r.FormValue("action") == "remove" {
+		if err := app.sessions.Delete(r, promoSessionKey); err != nil {
+			http.Error(w, "Ошибка сохранения корзины", http.StatusInternalServerError)
+			return
+		}
+		http.Redirect(w, r, "/cart", http.StatusSeeOther)
+		return
+	}
+
+	code := promo.NormalizeCode(r.FormValue("code"))
+	if code == "" {
+		app.renderCart(w, r, "Введите промокод")
+		return
+	}
+
+	entries, err := app.cartEntries(r)
+	if err != nil {
+		http.Error(w, "Ошибка получения корзины", http.StatusInternalServerError)
+		return
+	}
+	lines, err := app.cartLines(entries)
+	if err != nil {
+		http.Error(w, "Ошибка получения корзины", http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := app.checkPromoCode(r, code, lines); err != nil {
+		if promo.IsValidationError(err) {
+			app.renderCart(w, r, userMessage(err))
+			return
+		}
+		http.Error(w, "Ошибка проверки промокода", http.StatusInternalServerError)
+		return
+	}
+
+	if err := app.sessions.Set(w, r, promoSessionKey, code); err != nil {
+		http.Error(w, "Ошибка сохранения корзины", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, "/cart", http.StatusSeeOther)
+}
+
+// Оформление заказа из корзины
+func (app *App) handleCheckout(w http.ResponseWriter, r *http.Request) {
+	customer := app.requireCustomer(w, r)
+	if customer == nil {
+		return
+	}
+
+	cart, err := app.loadCart(r)
+	if err != nil {
+		http.Error(w, "Ошибка получения корзины", http.StatusInternalServerError)
+		return
+	}
+	if len(cart.Lines) == 0 {
+		http.Redirect(w, r, "/cart", http.StatusSeeOther)
+		return
+	}
+	// Промокод перестал действовать с момента применения
+	if cart.PromoError != "" {
+		app.renderCart(w, r, "")
+		return
+	}
+
+	addresses, err := app.customers.GetAddresses(customer.ID)
+	if err != nil {
+		http.Error(w, "Ошибка получения адресов", http.StatusInternalServerError)
+		return
+	}
+	if len(addresses) == 0 {
+		app.renderMessage(w, "Нет адреса доставки", "Добавьте адрес доставки в личном кабинете, чтобы оформить заказ.")
+		return
+	}
+	address := addresses[0]
+
+	// Собираем адрес доставки в одну строку, пропуская пустые поля
+	var addressParts []string
+	for _, part := range []string{address.Recipient, address.Phone, address.PostalCode, address.City, address.Street} {
+		if part != "" {
+			addressParts = append(addressParts, part)
+		}
+	}
+
+	order := models.Order{
+		CustomerID: &customer.ID,
+		Status:     models.OrderStatusNew,
+		Total:      cart.Total,
+		Discount:   cart.Discount,
+		PromoCode:  cart.PromoCode,
+		Address:    strings.Join(addressParts, ", "),
+	}
+	for _, line := range cart.Lines {
+		order.Items = append(order.Items, models.OrderItem{
+			ProductID: line.Product.ID,
+			Name:      line.Product.Name,
+			Price:     line.UnitPrice(),
+			Quantity:  line.Quantity,
+		})
+	}
+
+	err = app.orders.Place(&order, cart.promoCodeID)
+	// Промокод исчерпали другие покупатели, пока оформлялся заказ
+	if promo.IsValidationError(err) {
+		app.renderCart(w, r, userMessage(err))
+		return
+	}
+	if err != nil {
+		http.Error(w, "Ошибка оформления заказа", http.StatusInternalServerError)
+		return
+	}
+
+	// Очищаем корзину после оформления
+	if err := app.sessions.Delete(r, cartSessionKey); err != nil {
+		http.Error(w, "Ошибка сохранения корзины", http.StatusInternalServerError)
+		return
+	}
+	if err := app.sessions.Delete(r, promoSessionKey); err != nil {
+		http.Error(w, "Ошибка сохранения корзины", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/account?done=order", http.StatusSeeOther)
+}
+
+// renderCart отображает корзину. promoError заменяет ошибку примененного промокода
+func (app *App) renderCart(w http.ResponseWriter, r *http.Request, promoError string) {
+	cart, err := app.loadCart(r)
+	if err != nil {
+		http.Error(w, "Ошибка получения корзины", http.StatusInternalServerError)
+		return
+	}
+	if promoError != "" {
+		cart.PromoError = promoError
+	}
+
+	data := struct {
+		Cart        cartView
+		LoggedIn    bool
+		Breadcrumbs seo.Breadcrumbs
+	}{
+		Cart:        cart,
+		LoggedIn:    app.currentCustomer(r) != nil,
+		Breadcrumbs: app.newBreadcrumbs().Add("Корзина", "/cart"),
+	}
+
+	app.renderTemplate(w, "cart.html", data)
+}
+
+// loadCart загружает корзину посетителя и применяет сохраненный промокод.
+// Если промокод перестал действовать, скидка не начисляется, а причина попадает в PromoError
+func (app *App) loadCart(r *http.Request) (cartView, error) {
+	var cart cartView
+
+	entries, err := app.cartEntries(r)
+	if err != nil {
+		return cart, err
+	}
+	if cart.Lines, err = app.cartLines(entries); err != nil {
+		return cart, err
+	}
+	cart.Subtotal = promo.Subtotal(cart.Lines)
+	cart.Total = cart.Subtotal
+
+	var code string
+	if err := app.sessions.Get(r, promoSessionKey, &code); err != nil {
+		return cart, err
+	}
+	if code == "" {
+		return cart, nil
+	}
+	cart.PromoCode = code
+
+	promoCode, err := app.checkPromoCode(r, code, cart.Lines)
+	if err != nil {
+		if !promo.IsValidationError(err) {
+			return cart, err
+		}
+		cart.PromoError = userMessage(err)
+		return cart, nil
+	}
+
+	cart.promoCodeID = promoCode.ID
+	cart.Discount = promo.Discount(promoCode, cart.Lines)
+	cart.Total = cart.Subtotal - cart.Discount
+	return cart, nil
+}
+
+// checkPromoCode находит промокод и проверяет, можно ли применить его к корзине
+func (app *App) checkPromoCode(r *http.Request, code string, lines []promo.Line) (*models.PromoCode, error) {
+	promoCode, err := app.promoCodes.GetByCode(code)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, promo.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var usage promo.Usage
+	if customer := app.currentCustomer(r); customer != nil {
+		usage.CustomerID = customer.ID
+	}
+	usage.Total, usage.Customer, err = app.promoCodes.CountRedemptions(promoCode.ID, usage.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := promo.Validate(promoCode, lines, usage, time.Now()); err != nil {
+		return nil, err
+	}
+	return promoCode, nil
+}
+
+// cartEntries возвращает позиции корзины из сессии
+func (app *App) cartEntries(r *http.Request) ([]cartEntry, error) {
+	var entries []cartEntry
+	err := app.sessions.Get(r, cartSessionKey, &entries)
+	return entries, err
+}
+
+// cartLines загружает продукты для позиций корзины.
+// Позиции с удаленными продуктами пропускаются
+func (app *App) cartLines(entries []cartEntry) ([]promo.Line, error) {
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]uint, 0, len(entries))
+	quantities := make(map[uint]int, len(entries))
+	for _, entry := range entries {
+		ids = append(ids, entry.ProductID)
+		quantities[entry.ProductID] = entry.Quantity
+	}
+
+	products, err := app.products.GetByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]promo.Line, 0, len(products))
+	for _, product := range products {
+		lines = append(lines, promo.Line{Product: product, Quantity: quantities[product.ID]})
+	}
+	return lines, nil
+}
+
+// userMessage превращает текст ошибки в сообщение для покупателя
+func userMessage(err error) string {
+	text := err.Error()
+	first, size := utf8.DecodeRuneInString(text)
+	return string(unicode.ToUpper(first)) + text[size:]
+}
@@ -0,0 +1,505 @@
+package main
+
+import (
+	"bufio"
+	"cosmetics_catalog/auth"
+	"cosmetics_catalog/config"
+	"cosmetics_catalog/database"
+	"cosmetics_catalog/export"
+	"cosmetics_catalog/importer"
+	"cosmetics_catalog/models"
+	"cosmetics_catalog/pricing"
+	"cosmetics_catalog/repositories"
+	"cosmetics_catalog/repositories/memory"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// command подкоманда приложения
+type command struct {
+	name        string
+	description string
+	run         func(args []string) error
+}
+
+// commands подкоманды в порядке вывода в справке
+var commands []command
+
+func init() {
+	// Список заполняется в init: команда help сама обращается к списку,
+	// и инициализация при объявлении дала бы цикл
+	commands = []command{
+		{"serve", "запустить веб-сервер (команда по умолчанию)", runServe},
+		{"migrate", "применить, откатить или показать миграции схемы базы", runMigrate},
+		{"seed", "заполнить пустую базу тестовым каталогом", runSeed},
+		{"import", "загрузить ассортимент из таблицы CSV или XLSX", runImport},
+		{"export", "выгрузить каталог в CSV или JSON Lines", runExport},
+		{"reprice", "массово изменить цены продуктов", runReprice},
+		{"check-images", "найти продукты без файла изображения", runCheckImages},
+		{"create-admin", "создать администратора", runCreateAdmin},
+		{"help", "показать эту справку", runHelp},
+	}
+}
+
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+func printUsage() {
+	fmt.Fprintf(os.Stderr, "Использование: %s <команда> [флаги]\n\nКоманды:\n", filepath.Base(os.Args[0]))
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-14s %s\n", cmd.name, cmd.description)
+	}
+	fmt.Fprintf(os.Stderr, "\nФлаги команды: %s <команда> -h\n", filepath.Base(os.Args[0]))
+}
+
+func runHelp(args []string) error {
+	printUsage()
+	return nil
+}
+
+// connect загружает настройки с учетом флагов команды и подключается к базе
+func connect(settings *config.Flags) (config.Config, *gorm.DB, error) {
+	cfg, err := settings.Load()
+	if err != nil {
+		return cfg, nil, fmt.Errorf("ошибка в настройках: %w", err)
+	}
+
+	level, _ := cfg.SlogLevel()
+	slog.SetLogLoggerLevel(level)
+
+	db, err := database.Connect(cfg.DatabaseDriver, cfg.DatabaseDSN, level)
+	if err != nil {
+		return cfg, nil, fmt.Errorf("ошибка подключения к базе данных: %w", err)
+	}
+	// Команды создают категории в обход App, например seed,
+	// поэтому слова служебных разделов каталога резервируются в подключении
+	db = db.WithContext(models.WithReservedCategorySlugs(db.Statement.Context, reservedCategorySlugs(cfg)...))
+	return cfg, db, nil
+}
+
+// setup подключается к базе и проверяет, что схема совпадает с версией приложения.
+// Миграции применяет только команда migrate
+func setup(settings *config.Flags) (config.Config, *gorm.DB, error) {
+	cfg, db, err := connect(settings)
+	if err != nil {
+		return cfg, nil, err
+	}
+	return cfg, db, database.CheckSchema(db)
+}
+
+// setupDemo готовит демо-режим: пустая база SQLite в памяти с примененными
+// миграциями и тестовый каталог в репозиториях памяти. Настройки базы
+// из файла и окружения не используются, раздел администрирования выключен
+func setupDemo(settings *config.Flags) (config.Config, *gorm.DB, repositories.Catalog, error) {
+	var catalog repositories.Catalog
+	cfg, err := settings.Load()
+	if err != nil {
+		return cfg, nil, catalog, fmt.Errorf("ошибка в настройках: %w", err)
+	}
+	cfg.DatabaseDriver = database.DriverSQLite
+	cfg.DatabaseDSN = ":memory:"
+	cfg.Features.Admin = false
+
+	level, _ := cfg.SlogLevel()
+	slog.SetLogLoggerLevel(level)
+
+	db, err := database.Connect(cfg.DatabaseDriver, cfg.DatabaseDSN, level)
+	if err != nil {
+		return cfg, nil, catalog, fmt.Errorf("ошибка подключения к базе данных: %w", err)
+	}
+	// У каждого подключения к :memory: своя база, поэтому оно должно быть одно
+	sqlDB, err := db.DB()
+	if err != nil {
+		return cfg, nil, catalog, err
+	}
+	sqlDB.SetMaxOpenConns(1)
+
+	if _, err := database.Migrate(db); err != nil {
+		return cfg, nil, catalog, fmt.Errorf("ошибка миграции: %w", err)
+	}
+	if err := database.SeedPromoCodes(db); err != nil {
+		return cfg, nil, catalog, fmt.Errorf("ошибка при посеве данных: %w", err)
+	}
+
+	catalog = memory.NewCatalog().Repositories()
+	catalog.Categories.Reserve(reservedCategorySlugs(cfg)...)
+	if err := database.SeedCatalog(catalog); err != nil {
+		return cfg, nil, catalog, fmt.Errorf("ошибка при посеве данных: %w", err)
+	}
+	return cfg, db, catalog, nil
+}
+
+// runMigrate применяет и откатывает миграции схемы. Действие указывается
+// перед флагами: up по умолчанию, down или status
+func runMigrate(args []string) error {
+	action := "up"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		action, args = args[0], args[1:]
+	}
+
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	settings := config.BindFlags(flags, "db")
+	steps := flags.Int("steps", 1, "число откатываемых миграций для down")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Использование: migrate [up|down|status] [флаги]\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if action != "up" && action != "down" && action != "status" {
+		flags.Usage()
+		os.Exit(2)
+	}
+	_, db, err := connect(settings)
+	if err != nil {
+		return err
+	}
+
+	switch action {
+	case "down":
+		if *steps < 1 {
+			return errors.New("число откатываемых миграций должно быть больше нуля")
+		}
+		done, err := database.Rollback(db, *steps)
+		for _, migration := range done {
+			fmt.Printf("Откачена миграция %d %s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(done) == 0 {
+			fmt.Println("Нет примененных миграций")
+		}
+	case "status":
+		statuses, err := database.Status(db)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			state := "не применена"
+			switch {
+			case status.Unknown:
+				state = "неизвестна приложению, применена " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			case status.AppliedAt != nil:
+				state = "применена " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%4d  %-30s %s\n", status.Version, status.Name, state)
+		}
+	default:
+		done, err := database.Migrate(db)
+		for _, migration := range done {
+			fmt.Printf("Применена миграция %d %s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(done) == 0 {
+			fmt.Println("Схема базы данных актуальна")
+		}
+	}
+	return nil
+}
+
+func runSeed(args []string) error {
+	flags := flag.NewFlagSet("seed", flag.ExitOnError)
+	settings := config.BindFlags(flags, "db")
+	flags.Parse(args)
+
+	_, db, err := setup(settings)
+	if err != nil {
+		return err
+	}
+	var count int64
+	if err := db.Model(&models.Product{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("в базе уже есть продукты, тестовый каталог не загружен")
+	}
+
+	if err := database.SeedTestData(db); err != nil {
+		return fmt.Errorf("ошибка при посеве данных: %w", err)
+	}
+	fmt.Println("Тестовый каталог загружен")
+	return nil
+}
+
+// Колонки таблицы: артикул, слаг, название, бренд, подкатегория, цена,
+// цена со скидкой, акция, изображение, описание, объем, состав.
+// Заголовки можно писать по-английски: sku, slug, name и т.д.
+func runImport(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	settings := config.BindFlags(flags, "db")
+	dryRun := flags.Bool("dry-run", false, "показать изменения и ошибки без записи в базу")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Использование: import [флаги] файл.csv|файл.xlsx\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	rows, err := importer.ReadFile(flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("ошибка чтения таблицы: %w", err)
+	}
+	cfg, db, err := setup(settings)
+	if err != nil {
+		return err
+	}
+
+	// Покупатели узнают о товарах из избранного, попавших в акцию при импорте.
+	// Хук срабатывает после фиксации транзакции импорта, пробный запуск ничего не сохраняет
+	if !*dryRun {
+		mail, err := newMailer(cfg.Mail)
+		if err != nil {
+			return fmt.Errorf("ошибка настройки почты: %w", err)
+		}
+		notifier := saleNotifier{wishlist: repositories.NewWishlistRepository(db), mailer: mail}
+		db = db.WithContext(models.WithSaleHook(db.Statement.Context, notifier.notify))
+	}
+
+	result, err := importer.New(db).Import(rows, *dryRun, os.Stdout)
+	fmt.Printf("\nНовых: %d, изменено: %d, без изменений: %d, с ошибками: %d\n",
+		result.Created, result.Updated, result.Unchanged, result.Invalid)
+	if err != nil {
+		return err
+	}
+	if *dryRun {
+		fmt.Println("Пробный запуск, изменения не сохранены")
+	}
+	return nil
+}
+
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	settings := config.BindFlags(flags, "db", "base-url")
+	format := flags.String("format", export.FormatCSV, "формат выгрузки: csv или jsonl")
+	output := flags.String("o", "", "файл для выгрузки, по умолчанию стандартный вывод")
+	flags.Parse(args)
+
+	cfg, db, err := setup(settings)
+	if err != nil {
+		return err
+	}
+
+	out := os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("ошибка создания файла: %w", err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	// Без адреса сайта в настройках ссылки ведут на локальный сервер
+	for _, warning := range cfg.Warnings() {
+		slog.Warn(warning)
+	}
+	writer := bufio.NewWriter(out)
+	catalog := repositories.NewCatalog(db)
+	if err := export.Write(writer, catalog.Products, catalog.Subcategories, *format, cfg.SiteURL()); err != nil {
+		return fmt.Errorf("ошибка выгрузки каталога: %w", err)
+	}
+	return writer.Flush()
+}
+
+// Без флага -apply команда только показывает новые цены. Подкатегория
+// указывается путем вместе со слагом категории, например uxod/uvlazhnenie,
+// и включает вложенные подкатегории. Изменение всего каталога требует флага -all
+func runReprice(args []string) error {
+	flags := flag.NewFlagSet("reprice", flag.ExitOnError)
+	settings := config.BindFlags(flags, "db")
+	brandSlug := flags.String("brand", "", "слаг бренда")
+	categorySlug := flags.String("category", "", "слаг категории")
+	subcategoryPath := flags.String("subcategory", "", "путь подкатегории, например uxod/uvlazhnenie")
+	percent := flags.Float64("percent", 0, "изменение цены в процентах")
+	fixed := flags.Float64("fixed", 0, "изменение цены в рублях")
+	rounding := flags.String("round", pricing.RoundCents, "округление: whole, 90 или 99, по умолчанию до копеек")
+	reason := flags.String("reason", "", "причина изменения для истории цен")
+	all := flags.Bool("all", false, "изменить цены всего каталога")
+	apply := flags.Bool("apply", false, "применить изменения")
+	flags.Parse(args)
+
+	update := pricing.Update{Rounding: *rounding, Reason: *reason}
+	switch {
+	case *percent != 0 && *fixed != 0:
+		return errors.New("укажите только один из флагов -percent и -fixed")
+	case *percent != 0:
+		update.Mode, update.Amount = pricing.ModePercent, *percent
+	case *fixed != 0:
+		update.Mode, update.Amount = pricing.ModeFixed, *fixed
+	default:
+		flags.Usage()
+		os.Exit(2)
+	}
+	if *brandSlug == "" && *categorySlug == "" && *subcategoryPath == "" && !*all {
+		return errors.New("укажите бренд, категорию или подкатегорию либо флаг -all для всего каталога")
+	}
+
+	_, db, err := setup(settings)
+	if err != nil {
+		return err
+	}
+
+	catalog := repositories.NewCatalog(db)
+	if *brandSlug != "" {
+		brand, err := catalog.Brands.GetBySlug(strings.ToLower(*brandSlug))
+		if err != nil {
+			return fmt.Errorf("бренд %s не найден", *brandSlug)
+		}
+		update.Filter.BrandID = brand.ID
+	}
+	if *categorySlug != "" {
+		category, err := catalog.Categories.GetBySlug(strings.ToLower(*categorySlug))
+		if err != nil {
+			return fmt.Errorf("категория %s не найдена", *categorySlug)
+		}
+		update.Filter.CategoryID = category.ID
+	}
+	if *subcategoryPath != "" {
+		categoryPart, path, _ := strings.Cut(strings.Trim(*subcategoryPath, "/"), "/")
+		category, err := catalog.Categories.GetBySlug(strings.ToLower(categoryPart))
+		if err != nil {
+			return fmt.Errorf("категория подкатегории %s не найдена", *subcategoryPath)
+		}
+		subcategory, err := catalog.Subcategories.GetByPath(category.ID, path)
+		if err != nil {
+			return fmt.Errorf("подкатегория %s не найдена", *subcategoryPath)
+		}
+		if update.Filter.SubcategoryIDs, err = catalog.Subcategories.GetDescendantIDs(subcategory); err != nil {
+			return fmt.Errorf("ошибка загрузки подкатегорий: %w", err)
+		}
+	}
+
+	lines, err := pricing.Preview(catalog.Products, update)
+	if err == nil && *apply {
+		err = pricing.Apply(catalog.Products, update, lines)
+	}
+
+	for _, line := range lines {
+		fmt.Printf("%s: %.2f → %.2f", line.Product.Slug, line.OldPrice, line.NewPrice)
+		if line.OnSale() {
+			fmt.Printf(" (со скидкой %.2f → %.2f)", line.OldSalePrice, line.NewSalePrice)
+		}
+		fmt.Println()
+	}
+	if err != nil {
+		return err
+	}
+
+	if *apply {
+		fmt.Printf("\nЦены изменены у %d продуктов: %s\n", len(lines), update.Describe())
+	} else {
+		fmt.Printf("\nБудут изменены цены у %d продуктов: %s. Для применения добавьте флаг -apply\n", len(lines), update.Describe())
+	}
+	return nil
+}
+
+// Изображение считается доступным, если оно лежит в каталоге фотографий
+// и раздается по адресу /photos/. Внешние адреса не проверяются
+func runCheckImages(args []string) error {
+	flags := flag.NewFlagSet("check-images", flag.ExitOnError)
+	settings := config.BindFlags(flags, "db", "photos")
+	flags.Parse(args)
+
+	cfg, db, err := setup(settings)
+	if err != nil {
+		return err
+	}
+
+	var products []models.Product
+	if err := db.Order("id").Find(&products).Error; err != nil {
+		return err
+	}
+
+	missing := 0
+	for _, product := range products {
+		problem := imageProblem(cfg.PhotoDir, product.ImagePath)
+		if problem == "" {
+			continue
+		}
+		missing++
+		fmt.Printf("%s: %s (%s)\n", product.Slug, problem, product.ImagePath)
+	}
+
+	fmt.Printf("\nПроверено продуктов: %d, с проблемами: %d\n", len(products), missing)
+	if missing > 0 {
+		return fmt.Errorf("у %d продуктов нет доступного изображения", missing)
+	}
+	return nil
+}
+
+// imageProblem описывает, почему изображение продукта недоступно.
+// Пустая строка означает, что с изображением все в порядке
+func imageProblem(photoDir, imagePath string) string {
+	switch {
+	case imagePath == "":
+		return "изображение не задано"
+	case strings.Contains(imagePath, "://"):
+		return ""
+	case !strings.HasPrefix(imagePath, "/photos/"):
+		return "путь вне /photos/, сервер его не раздает"
+	}
+
+	file := filepath.Join(photoDir, filepath.FromSlash(strings.TrimPrefix(imagePath, "/photos/")))
+	info, err := os.Stat(file)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return "файл не найден"
+	case err != nil:
+		return err.Error()
+	case info.IsDir():
+		return "вместо файла каталог"
+	}
+	return ""
+}
+
+// Пароль читается из первой строки стандартного ввода
+func runCreateAdmin(args []string) error {
+	flags := flag.NewFlagSet("create-admin", flag.ExitOnError)
+	settings := config.BindFlags(flags, "db")
+	login := flags.String("login", "", "логин администратора")
+	flags.Parse(args)
+	if strings.TrimSpace(*login) == "" {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	fmt.Fprint(os.Stderr, "Пароль: ")
+	password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && password == "" {
+		return fmt.Errorf("ошибка чтения пароля: %w", err)
+	}
+	hash, err := auth.HashPassword(strings.TrimRight(password, "\r\n"))
+	if err != nil {
+		return err
+	}
+
+	_, db, err := setup(settings)
+	if err != nil {
+		return err
+	}
+	admin := &models.AdminUser{Login: *login, PasswordHash: hash}
+	if err := repositories.NewAdminRepository(db).Create(admin); err != nil {
+		return fmt.Errorf("ошибка создания администратора: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Администратор %s создан\n", admin.Login)
+	return nil
+}
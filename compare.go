@@ -0,0 +1,186 @@
+package main
+
+import (
+	"cosmetics_catalog/models"
+	"cosmetics_catalog/seo"
+	"encoding/json"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+)
+
+// compareLimit максимальное количество товаров в сравнении
+const compareLimit = 4
+
+// compareSessionKey ключ списка сравнения в данных сессии
+const compareSessionKey = "compare"
+
+// compareItem товар в сравнении вместе с его оценкой
+type compareItem struct {
+	Product models.Product
+	Rating  models.Rating
+}
+
+// Страница сравнения товаров
+func (app *App) handleCompare(w http.ResponseWriter, r *http.Request) {
+	items, err := app.loadCompareItems(r)
+	if err != nil {
+		http.Error(w, "Ошибка получения списка сравнения", http.StatusInternalServerError)
+		return
+	}
+
+	tmpl, err := app.parseTemplate("compare.html")
+	if err != nil {
+		http.Error(w, "Ошибка загрузки шаблона", http.StatusInternalServerError)
+		return
+	}
+
+	data := struct {
+		Items       []compareItem
+		Limit       int
+		Full        bool
+		Breadcrumbs seo.Breadcrumbs
+	}{
+		Items:       items,
+		Limit:       compareLimit,
+		Full:        r.URL.Query().Get("full") != "",
+		Breadcrumbs: app.newBreadcrumbs().Add("Сравнение", "/compare"),
+	}
+
+	if err := tmpl.Execute(w, data); err != nil {
+		http.Error(w, "Ошибка рендеринга", http.StatusInternalServerError)
+	}
+}
+
+// Сравнение товаров в формате JSON
+func (app *App) handleCompareJSON(w http.ResponseWriter, r *http.Request) {
+	items, err := app.loadCompareItems(r)
+	if err != nil {
+		http.Error(w, "Ошибка получения списка сравнения", http.StatusInternalServerError)
+		return
+	}
+
+	type compareProduct struct {
+		ID           uint    `json:"id"`
+		Name         string  `json:"name"`
+		URL          string  `json:"url"`
+		Price        float64 `json:"price"`
+		SalePrice    float64 `json:"sale_price,omitempty"`
+		IsOnSale     bool    `json:"is_on_sale"`
+		Brand        string  `json:"brand"`
+		Subcategory  string  `json:"subcategory"`
+		Volume       string  `json:"volume"`
+		Ingredients  string  `json:"ingredients"`
+		Rating       float64 `json:"rating"`
+		ReviewsCount int64   `json:"reviews_count"`
+	}
+
+	products := make([]compareProduct, 0, len(items))
+	for _, item := range items {
+		p := item.Product
+		products = append(products, compareProduct{
+			ID:           p.ID,
+			Name:         p.Name,
+			URL:          p.URL(),
+			Price:        p.Price,
+			SalePrice:    p.SalePrice,
+			IsOnSale:     p.IsOnSale,
+			Brand:        p.Brand.Name,
+			Subcategory:  p.Subcategory.Name,
+			Volume:       p.Volume,
+			Ingredients:  p.Ingredients,
+			Rating:       item.Rating.Average,
+			ReviewsCount: item.Rating.Count,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(products); err != nil {
+		log.Printf("Ошибка отправки сравнения: %v", err)
+	}
+}
+
+// Добавление товара в сравнение или удаление из него
+func (app *App) handleCompareToggle(w http.ResponseWriter, r *http.Request) {
+	productID, err := strconv.ParseUint(r.FormValue("product_id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Некорректный идентификатор товара", http.StatusBadRequest)
+		return
+	}
+	id := uint(productID)
+
+	ids, err := app.compareProductIDs(r)
+	if err != nil {
+		http.Error(w, "Ошибка получения списка сравнения", http.StatusInternalServerError)
+		return
+	}
+
+	if i := slices.Index(ids, id); i >= 0 {
+		ids = slices.Delete(ids, i, i+1)
+	} else {
+		// Список заполнен, предлагаем убрать один из товаров
+		if len(ids) >= compareLimit {
+			http.Redirect(w, r, "/compare?full=1", http.StatusSeeOther)
+			return
+		}
+		if _, err := app.products.GetByID(id); err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		ids = append(ids, id)
+	}
+
+	if err := app.sessions.Set(w, r, compareSessionKey, ids); err != nil {
+		http.Error(w, "Ошибка сохранения списка сравнения", http.StatusInternalServerError)
+		return
+	}
+
+	// Возвращаем посетителя на страницу, с которой он пришел
+	http.Redirect(w, r, backURL(r, "/compare"), http.StatusSeeOther)
+}
+
+// compareProductIDs возвращает ID товаров из списка сравнения посетителя
+func (app *App) compareProductIDs(r *http.Request) ([]uint, error) {
+	var ids []uint
+	err := app.sessions.Get(r, compareSessionKey, &ids)
+	return ids, err
+}
+
+// compareSet возвращает множество ID товаров из списка сравнения посетителя
+func (app *App) compareSet(r *http.Request) map[uint]bool {
+	ids, err := app.compareProductIDs(r)
+	if err != nil {
+		log.Printf("Ошибка получения списка сравнения: %v", err)
+	}
+
+	set := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
+
+// loadCompareItems загружает товары из списка сравнения вместе с оценками
+func (app *App) loadCompareItems(r *http.Request) ([]compareItem, error) {
+	ids, err := app.compareProductIDs(r)
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	products, err := app.products.GetByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	ratings, err := app.reviews.GetRatings(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]compareItem, 0, len(products))
+	for _, product := range products {
+		items = append(items, compareItem{Product: product, Rating: ratings[product.ID]})
+	}
+	return items, nil
+}
@@ -0,0 +1,59 @@
+package config
+
+import "flag"
+
+// Flags флаги командной строки, которые переопределяют настройки
+type Flags struct {
+	flags  *flag.FlagSet
+	path   *string
+	values map[string]*string
+}
+
+// flagFields флаги, которые может зарегистрировать команда, и поля настроек для них
+var flagFields = map[string]struct {
+	usage string
+	field func(*Config) *string
+}{
+	"db":        {"путь к файлу SQLite или строка подключения к PostgreSQL", func(c *Config) *string { return &c.DatabaseDSN }},
+	"addr":      {"адрес, на котором сервер принимает запросы", func(c *Config) *string { return &c.Addr }},
+	"base-url":  {"адрес сайта для абсолютных ссылок, например https://example.com", func(c *Config) *string { return &c.BaseURL }},
+	"templates": {"каталог шаблонов", func(c *Config) *string { return &c.TemplateDir }},
+	"photos":    {"каталог фотографий продуктов", func(c *Config) *string { return &c.PhotoDir }},
+	"log-level": {"уровень журнала: debug, info, warn или error", func(c *Config) *string { return &c.LogLevel }},
+}
+
+// BindFlags регистрирует флаг --config и перечисленные флаги настроек.
+// Значения по умолчанию в справке берутся из Default
+func BindFlags(flags *flag.FlagSet, names ...string) *Flags {
+	f := &Flags{
+		flags:  flags,
+		path:   flags.String("config", "", "файл конфигурации JSON, по умолчанию "+DefaultFile),
+		values: map[string]*string{},
+	}
+
+	defaults := Default()
+	for _, name := range names {
+		spec, ok := flagFields[name]
+		if !ok {
+			panic("config: неизвестный флаг " + name)
+		}
+		f.values[name] = flags.String(name, *spec.field(&defaults), spec.usage)
+	}
+	return f
+}
+
+// Load читает настройки и применяет к ним флаги, явно заданные в командной строке.
+// Вызывается после разбора флагов. Возвращает проверенные настройки
+func (f *Flags) Load() (Config, error) {
+	cfg, err := Load(*f.path)
+	if err != nil {
+		return cfg, err
+	}
+
+	f.flags.Visit(func(fl *flag.Flag) {
+		if value, ok := f.values[fl.Name]; ok {
+			*flagFields[fl.Name].field(&cfg) = *value
+		}
+	})
+	return cfg, cfg.Validate()
+}
@@ -0,0 +1,93 @@
+// Package aftercommit откладывает действия до фиксации транзакции базы.
+// Колбэки и хуки GORM выполняются внутри транзакции: в явной транзакции
+// db.Transaction шаг gorm:commit_or_rollback_transaction ничего не делает,
+// и действие, выполненное в нем, увидело бы незафиксированные данные
+// или сработало бы для транзакции, которая потом откатится.
+//
+// Install подменяет пул подключений GORM так, что каждая транзакция, явная
+// или неявная транзакция отдельного запроса, выполняет отложенные через Do
+// действия только после успешного COMMIT. При откате действия отбрасываются.
+// Точки сохранения вложенных транзакций не отслеживаются: действие, отложенное
+// во вложенной транзакции, выполнится после фиксации внешней
+package aftercommit
+
+import (
+	"context"
+	"database/sql"
+
+	"gorm.io/gorm"
+)
+
+// Install подключает отложенные действия к транзакциям db.
+// Вызывается один раз сразу после открытия подключения
+func Install(db *gorm.DB) {
+	db.ConnPool = &pool{ConnPool: db.ConnPool}
+	db.Statement.ConnPool = db.ConnPool
+}
+
+// Do выполняет fn после фиксации транзакции, в которой выполняется запрос tx.
+// Если транзакции нет или Install не вызывался, fn выполняется сразу
+func Do(tx *gorm.DB, fn func()) {
+	if t, ok := tx.Statement.ConnPool.(*txConn); ok {
+		t.pending = append(t.pending, fn)
+		return
+	}
+	fn()
+}
+
+// pool пул подключений, который открывает транзакции txConn
+type pool struct {
+	gorm.ConnPool
+}
+
+func (p *pool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	var (
+		tx  *sql.Tx
+		err error
+	)
+	switch beginner := p.ConnPool.(type) {
+	case gorm.TxBeginner:
+		tx, err = beginner.BeginTx(ctx, opts)
+	default:
+		return nil, gorm.ErrInvalidTransaction
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &txConn{Tx: tx}, nil
+}
+
+// GetDBConn возвращает *sql.DB для db.DB()
+func (p *pool) GetDBConn() (*sql.DB, error) {
+	if db, ok := p.ConnPool.(*sql.DB); ok {
+		return db, nil
+	}
+	if connector, ok := p.ConnPool.(gorm.GetDBConnector); ok {
+		return connector.GetDBConn()
+	}
+	return nil, gorm.ErrInvalidDB
+}
+
+// txConn транзакция с отложенными действиями
+type txConn struct {
+	*sql.Tx
+	pending []func()
+}
+
+func (t *txConn) Commit() error {
+	if err := t.Tx.Commit(); err != nil {
+		t.pending = nil
+		return err
+	}
+	pending := t.pending
+	t.pending = nil
+	for _, fn := range pending {
+		fn()
+	}
+	return nil
+}
+
+func (t *txConn) Rollback() error {
+	t.pending = nil
+	return t.Tx.Rollback()
+}
@@ -1,33 +1,69 @@
 package database
 
 import (
-	"cosmetics_catalog/models"
+	"cosmetics_catalog/database/aftercommit"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strings"
 
+	"github.com/mattn/go-sqlite3"
+	"gorm.io/driver/postgres"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
-var DB *gorm.DB
+// Драйверы баз данных
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+)
 
-func Connect() error {
-	// Подключение к SQLite (файл будет создан автоматически)
-	db, err := gorm.Open(sqlite.Open("cosmetics.db"), &gorm.Config{})
-	if err != nil {
-		return err
-	}
+// sqliteDriver драйвер SQLite с функцией unicode_lower. Встроенные LOWER и LIKE
+// в SQLite меняют регистр только у латиницы, поиск по названиям без учета
+// регистра приводит к нижнему регистру на стороне Go
+const sqliteDriver = "sqlite3_catalog"
+
+func init() {
+	sql.Register(sqliteDriver, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			return conn.RegisterFunc("unicode_lower", strings.ToLower, true)
+		},
+	})
+}
 
-	DB = db
+// Connect подключается к базе через указанный драйвер. Файл базы SQLite
+// будет создан автоматически, база PostgreSQL должна существовать.
+// На уровне журнала debug в журнал пишутся все SQL-запросы
+func Connect(driver, dsn string, level slog.Level) (*gorm.DB, error) {
+	var dialector gorm.Dialector
+	switch driver {
+	case DriverSQLite:
+		dialector = sqlite.New(sqlite.Config{DriverName: sqliteDriver, DSN: dsn})
+	case DriverPostgres:
+		dialector = postgres.Open(dsn)
+	default:
+		return nil, fmt.Errorf("неизвестный драйвер базы данных %q", driver)
+	}
 
-	// Автомиграция - создание таблиц
-	err = db.AutoMigrate(
-		&models.Brand{},
-		&models.Category{},
-		&models.Subcategory{},
-		&models.Product{},
-	)
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger: logger.Default.LogMode(gormLogLevel(level)),
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
+	// Уведомления и сброс кэшей выполняются после фиксации транзакций
+	aftercommit.Install(db)
+	return db, nil
+}
 
-	return nil
+func gormLogLevel(level slog.Level) logger.LogLevel {
+	switch {
+	case level <= slog.LevelDebug:
+		return logger.Info
+	case level >= slog.LevelError:
+		return logger.Error
+	}
+	return logger.Warn
 }
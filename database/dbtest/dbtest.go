@@ -0,0 +1,70 @@
+// Package dbtest открывает пустые базы данных для тестов. SQLite открывается
+// в памяти всегда, PostgreSQL — только если в переменной CATALOG_TEST_POSTGRES_DSN
+// задана строка подключения к локальной базе, иначе тесты PostgreSQL пропускаются.
+// Каждый тест получает в PostgreSQL отдельную схему, которая удаляется после теста
+package dbtest
+
+import (
+	"cosmetics_catalog/database"
+	"fmt"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// PostgresDSN переменная окружения со строкой подключения к тестовой базе PostgreSQL
+const PostgresDSN = "CATALOG_TEST_POSTGRES_DSN"
+
+// Drivers драйверы, с которыми запускаются тесты базы
+var Drivers = []string{database.DriverSQLite, database.DriverPostgres}
+
+// Open открывает пустую базу без таблиц
+func Open(t testing.TB, driver string) *gorm.DB {
+	t.Helper()
+	dsn := ":memory:"
+	if driver == database.DriverPostgres {
+		if dsn = os.Getenv(PostgresDSN); dsn == "" {
+			t.Skipf("тесты PostgreSQL пропущены: не задана переменная %s", PostgresDSN)
+		}
+	}
+
+	db, err := database.Connect(driver, dsn, slog.LevelError)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// У каждого подключения к :memory: своя база, а search_path в PostgreSQL
+	// задается для подключения, поэтому подключение должно быть одно
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	db = db.Session(&gorm.Session{Logger: logger.Discard})
+
+	if driver == database.DriverPostgres {
+		schema := fmt.Sprintf("catalog_test_%d", time.Now().UnixNano())
+		if err := db.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Exec("DROP SCHEMA " + schema + " CASCADE") })
+		if err := db.Exec("SET search_path TO " + schema).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db
+}
+
+// OpenMigrated открывает пустую базу со всеми миграциями
+func OpenMigrated(t testing.TB, driver string) *gorm.DB {
+	t.Helper()
+	db := Open(t, driver)
+	if _, err := database.Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
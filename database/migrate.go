@@ -0,0 +1,202 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migration версия схемы базы данных. Up переводит схему на эту версию,
+// Down возвращает к предыдущей. Обе функции выполняются в транзакции
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// SchemaMigration запись о примененной миграции в таблице schema_migrations
+type SchemaMigration struct {
+	Version   int    `gorm:"primaryKey;autoIncrement:false"`
+	Name      string `gorm:"not null;size:255"`
+	AppliedAt time.Time
+}
+
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// MigrationStatus состояние миграции. AppliedAt пустое у непримененных миграций,
+// Unknown отмечает миграции из базы, которых нет в приложении
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+	Unknown   bool
+}
+
+// ErrSchemaMismatch схема базы не совпадает с версией, которую ожидает приложение
+var ErrSchemaMismatch = errors.New("схема базы данных не совпадает с версией приложения")
+
+// Migrate применяет все непримененные миграции по порядку версий
+// и возвращает примененные
+func Migrate(db *gorm.DB) ([]Migration, error) {
+	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return nil, err
+	}
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkUnknown(applied); err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, migration := range migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := migration.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaMigration{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				AppliedAt: time.Now(),
+			}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("миграция %d %s: %w", migration.Version, migration.Name, err)
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// Rollback откатывает steps последних примененных миграций и возвращает откаченные
+func Rollback(db *gorm.DB, steps int) ([]Migration, error) {
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkUnknown(applied); err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for i := len(migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		migration := migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := migration.Down(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&SchemaMigration{Version: migration.Version}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("откат миграции %d %s: %w", migration.Version, migration.Name, err)
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// Status возвращает состояние всех миграций приложения и неизвестных миграций из базы
+func Status(db *gorm.DB) ([]MigrationStatus, error) {
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, migration := range migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if record, ok := applied[migration.Version]; ok {
+			status.AppliedAt = &record.AppliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	for version, record := range applied {
+		if findMigration(version) == nil {
+			statuses = append(statuses, MigrationStatus{
+				Version:   version,
+				Name:      record.Name,
+				AppliedAt: &record.AppliedAt,
+				Unknown:   true,
+			})
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, nil
+}
+
+// CheckSchema проверяет, что применены ровно миграции приложения.
+// Веб-сервер и остальные команды не работают с базой другой версии
+func CheckSchema(db *gorm.DB) error {
+	statuses, err := Status(db)
+	if err != nil {
+		return err
+	}
+
+	var pending, unknown []string
+	for _, status := range statuses {
+		switch {
+		case status.Unknown:
+			unknown = append(unknown, fmt.Sprint(status.Version))
+		case status.AppliedAt == nil:
+			pending = append(pending, fmt.Sprint(status.Version))
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("%w: база содержит миграции %s из более новой версии", ErrSchemaMismatch, strings.Join(unknown, ", "))
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: не применены миграции %s, выполните команду migrate", ErrSchemaMismatch, strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+// appliedMigrations читает таблицу schema_migrations. Если таблицы нет,
+// ни одна миграция еще не применялась
+func appliedMigrations(db *gorm.DB) (map[int]SchemaMigration, error) {
+	if !db.Migrator().HasTable(&SchemaMigration{}) {
+		return map[int]SchemaMigration{}, nil
+	}
+	var records []SchemaMigration
+	if err := db.Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]SchemaMigration, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
+
+// checkUnknown не дает менять схему, в которой есть миграции более новой версии приложения
+func checkUnknown(applied map[int]SchemaMigration) error {
+	for version := range applied {
+		if findMigration(version) == nil {
+			return fmt.Errorf("%w: миграция %d неизвестна приложению", ErrSchemaMismatch, version)
+		}
+	}
+	return nil
+}
+
+func findMigration(version int) *Migration {
+	for i := range migrations {
+		if migrations[i].Version == version {
+			return &migrations[i]
+		}
+	}
+	return nil
+}
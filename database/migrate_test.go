@@ -0,0 +1,174 @@
+package database_test
+
+import (
+	"cosmetics_catalog/database"
+	"cosmetics_catalog/database/dbtest"
+	"cosmetics_catalog/models"
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// forEachDriver запускает тест для SQLite и, если задана тестовая база, для PostgreSQL
+func forEachDriver(t *testing.T, test func(t *testing.T, driver string)) {
+	for _, driver := range dbtest.Drivers {
+		t.Run(driver, func(t *testing.T) {
+			test(t, driver)
+		})
+	}
+}
+
+func TestMigrateAndRollback(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, driver string) {
+		db := dbtest.Open(t, driver)
+
+		statuses, err := database.Status(db)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := database.CheckSchema(db); !errors.Is(err, database.ErrSchemaMismatch) {
+			t.Errorf("CheckSchema пустой базы error = %v", err)
+		}
+
+		done, err := database.Migrate(db)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(done) != len(statuses) {
+			t.Errorf("применено %d миграций, want %d", len(done), len(statuses))
+		}
+		if err := database.CheckSchema(db); err != nil {
+			t.Errorf("CheckSchema после миграций: %v", err)
+		}
+		if done, err := database.Migrate(db); err != nil || len(done) != 0 {
+			t.Errorf("повторный Migrate = %d, %v", len(done), err)
+		}
+		for _, table := range []string{"brands", "categories", "subcategories", "products", "price_histories"} {
+			if !db.Migrator().HasTable(table) {
+				t.Errorf("нет таблицы %s", table)
+			}
+		}
+
+		undone, err := database.Rollback(db, len(done)+len(statuses))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(undone) != len(statuses) {
+			t.Errorf("откачено %d миграций, want %d", len(undone), len(statuses))
+		}
+		if db.Migrator().HasTable("products") {
+			t.Error("таблица products осталась после отката")
+		}
+
+		// После отката схему можно создать заново
+		if _, err := database.Migrate(db); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestMigrateUnknownVersion(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, driver string) {
+		db := dbtest.OpenMigrated(t, driver)
+		if err := db.Create(&database.SchemaMigration{Version: 999, Name: "future"}).Error; err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := database.Migrate(db); !errors.Is(err, database.ErrSchemaMismatch) {
+			t.Errorf("Migrate с неизвестной миграцией error = %v", err)
+		}
+		if _, err := database.Rollback(db, 1); !errors.Is(err, database.ErrSchemaMismatch) {
+			t.Errorf("Rollback с неизвестной миграцией error = %v", err)
+		}
+		if err := database.CheckSchema(db); !errors.Is(err, database.ErrSchemaMismatch) {
+			t.Errorf("CheckSchema с неизвестной миграцией error = %v", err)
+		}
+	})
+}
+
+// Таблицы каталога в том виде, в котором их создавал AutoMigrate
+// до версионных миграций: без путей подкатегорий и уникальных слагов продуктов
+
+type legacyCategory struct {
+	gorm.Model
+	Name string `gorm:"unique;not null;size:100"`
+	Slug string `gorm:"unique;not null;size:110"`
+}
+
+func (legacyCategory) TableName() string { return "categories" }
+
+type legacySubcategory struct {
+	gorm.Model
+	Name       string `gorm:"not null;size:100"`
+	Slug       string `gorm:"not null;size:110"`
+	CategoryID uint   `gorm:"not null"`
+}
+
+func (legacySubcategory) TableName() string { return "subcategories" }
+
+type legacyProduct struct {
+	gorm.Model
+	Name          string  `gorm:"not null;size:255"`
+	Slug          string  `gorm:"not null;size:265"`
+	BrandID       uint    `gorm:"not null"`
+	SubcategoryID uint    `gorm:"not null"`
+	Price         float64 `gorm:"not null"`
+	ImagePath     string  `gorm:"not null;size:255"`
+}
+
+func (legacyProduct) TableName() string { return "products" }
+
+func TestMigrateLegacySchema(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, driver string) {
+		db := dbtest.Open(t, driver)
+		if err := db.AutoMigrate(&legacyCategory{}, &legacySubcategory{}, &legacyProduct{}); err != nil {
+			t.Fatal(err)
+		}
+
+		category := legacyCategory{Name: "Уход", Slug: "uxod"}
+		if err := db.Create(&category).Error; err != nil {
+			t.Fatal(err)
+		}
+		subcategories := []legacySubcategory{
+			{Name: "Кремы", Slug: "kremy", CategoryID: category.ID},
+			{Name: "Кремы для рук", Slug: "kremy", CategoryID: category.ID},
+		}
+		if err := db.Create(&subcategories).Error; err != nil {
+			t.Fatal(err)
+		}
+		products := []legacyProduct{
+			{Name: "Крем", Slug: "krem", BrandID: 1, SubcategoryID: subcategories[0].ID, Price: 100},
+			{Name: "Крем", Slug: "krem", BrandID: 1, SubcategoryID: subcategories[1].ID, Price: 200},
+		}
+		if err := db.Create(&products).Error; err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := database.Migrate(db); err != nil {
+			t.Fatal(err)
+		}
+
+		var upgraded []models.Subcategory
+		if err := db.Order("id").Find(&upgraded).Error; err != nil {
+			t.Fatal(err)
+		}
+		if len(upgraded) != 2 || upgraded[0].Path != "kremy" || upgraded[1].Slug == "kremy" || upgraded[1].Path != upgraded[1].Slug {
+			t.Errorf("подкатегории после миграции = %+v", upgraded)
+		}
+
+		var slugs []string
+		if err := db.Model(&models.Product{}).Order("id").Pluck("slug", &slugs).Error; err != nil {
+			t.Fatal(err)
+		}
+		if len(slugs) != 2 || slugs[0] != "krem" || slugs[1] == "krem" {
+			t.Errorf("слаги продуктов после миграции = %v", slugs)
+		}
+
+		// Слаг продукта стал уникальным
+		duplicate := legacyProduct{Name: "Крем", Slug: "krem", BrandID: 1, SubcategoryID: subcategories[0].ID, Price: 300}
+		if err := db.Create(&duplicate).Error; err == nil {
+			t.Error("повторяющийся слаг продукта сохранен после миграции")
+		}
+	})
+}
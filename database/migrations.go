@@ -0,0 +1,85 @@
+package database
+
+import (
+	"cosmetics_catalog/database/schemav1"
+
+	"gorm.io/gorm"
+)
+
+// migrations миграции схемы в порядке версий. Примененные миграции не меняются,
+// любое изменение схемы или данных добавляется новой миграцией в конец списка
+var migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "base_schema",
+		// Базы, созданные раньше через AutoMigrate, дополняются недостающими
+		// колонками и индексами, данные в них сохраняются. Перед этим
+		// заполняются пути подкатегорий и разводятся повторяющиеся слаги,
+		// иначе новые ограничения NOT NULL и UNIQUE не добавятся
+		Up: func(tx *gorm.DB) error {
+			if err := upgradeLegacySchema(tx); err != nil {
+				return err
+			}
+			return tx.AutoMigrate(schemav1.Models()...)
+		},
+		Down: func(tx *gorm.DB) error {
+			tables := make([]interface{}, 0, len(schemav1.JoinTables)+len(schemav1.Models()))
+			for _, table := range schemav1.JoinTables {
+				tables = append(tables, table)
+			}
+			// Таблицы удаляются в обратном порядке, сначала зависимые
+			models := schemav1.Models()
+			for i := len(models) - 1; i >= 0; i-- {
+				tables = append(tables, models[i])
+			}
+			return tx.Migrator().DropTable(tables...)
+		},
+	},
+	{
+		Version: 2,
+		Name:    "backfill_sale_started_at",
+		// Продукты, попавшие в акцию до появления даты начала акции,
+		// получают дату последнего изменения, чтобы лента акций была упорядочена
+		Up: func(tx *gorm.DB) error {
+			return tx.Exec(
+				"UPDATE products SET sale_started_at = updated_at WHERE is_on_sale = ? AND sale_started_at IS NULL",
+				true,
+			).Error
+		},
+		// Заполненные даты не отличить от настоящих, откат оставляет их как есть
+		Down: func(tx *gorm.DB) error {
+			return nil
+		},
+	},
+}
+
+// upgradeLegacySchema готовит базу, созданную до версионных миграций,
+// к схеме первой миграции. Признак такой базы — таблица подкатегорий без путей.
+// В прежней схеме подкатегории не были вложенными, поэтому путь совпадает
+// со слагом. Повторяющиеся слаги подкатегорий внутри категории и слаги
+// продуктов получают суффикс с ID записи, первая запись сохраняет свой слаг
+func upgradeLegacySchema(tx *gorm.DB) error {
+	migrator := tx.Migrator()
+	if !migrator.HasTable("subcategories") || migrator.HasColumn("subcategories", "path") {
+		return nil
+	}
+
+	statements := []string{
+		`UPDATE subcategories SET slug = slug || '-' || id
+			WHERE id NOT IN (SELECT MIN(id) FROM subcategories GROUP BY category_id, slug)`,
+		"ALTER TABLE subcategories ADD COLUMN path VARCHAR(500)",
+		"UPDATE subcategories SET path = slug",
+	}
+	if migrator.HasTable("products") {
+		statements = append(statements,
+			`UPDATE products SET slug = slug || '-' || id
+				WHERE id NOT IN (SELECT MIN(id) FROM products GROUP BY slug)`,
+		)
+	}
+	for _, statement := range statements {
+		if err := tx.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,223 @@
+// Package schemav1 копия моделей на момент перехода к версионным миграциям.
+// Первая миграция создает таблицы по этим структурам, поэтому изменения
+// в пакете models на нее не влияют. Структуры в этом пакете не меняются,
+// новые поля и таблицы добавляются следующими миграциями
+package schemav1
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type SEO struct {
+	MetaTitle       string `gorm:"size:255"`
+	MetaDescription string `gorm:"size:500"`
+	OGImage         string `gorm:"size:255"`
+}
+
+type Brand struct {
+	gorm.Model
+	Name     string    `gorm:"unique;not null;size:100"`
+	Slug     string    `gorm:"unique;not null;size:110"`
+	Products []Product `gorm:"foreignKey:BrandID"`
+
+	SEO
+}
+
+type Category struct {
+	gorm.Model
+	Name          string        `gorm:"unique;not null;size:100"`
+	Slug          string        `gorm:"unique;not null;size:110"`
+	Subcategories []Subcategory `gorm:"foreignKey:CategoryID"`
+
+	SEO
+}
+
+type Subcategory struct {
+	gorm.Model
+	Name       string        `gorm:"not null;size:100"`
+	Slug       string        `gorm:"not null;size:110"`
+	Path       string        `gorm:"not null;size:500;uniqueIndex:idx_subcategories_category_path,priority:2"`
+	CategoryID uint          `gorm:"not null;uniqueIndex:idx_subcategories_category_path,priority:1"`
+	ParentID   *uint         `gorm:"index"`
+	Children   []Subcategory `gorm:"foreignKey:ParentID"`
+	Products   []Product     `gorm:"foreignKey:SubcategoryID"`
+
+	SEO
+	Category Category
+}
+
+type Product struct {
+	gorm.Model
+	Name          string  `gorm:"not null;size:255"`
+	Slug          string  `gorm:"unique;not null;size:265"`
+	SKU           string  `gorm:"size:64;index"`
+	BrandID       uint    `gorm:"not null"`
+	SubcategoryID uint    `gorm:"not null"`
+	Price         float64 `gorm:"not null"`
+	ImagePath     string  `gorm:"not null;size:255"`
+	Description   string  `gorm:"type:text"`
+	Volume        string  `gorm:"size:50"`
+	Ingredients   string  `gorm:"type:text"`
+	IsOnSale      bool    `gorm:"default:false"`
+	SalePrice     float64
+	SaleStartedAt *time.Time
+
+	SEO
+	Brand       Brand
+	Subcategory Subcategory
+}
+
+type WishlistItem struct {
+	gorm.Model
+	SessionID  string `gorm:"not null;size:64;index"`
+	CustomerID *uint  `gorm:"index"`
+	ProductID  uint   `gorm:"not null;index"`
+	Product    Product
+}
+
+type Session struct {
+	ID        string `gorm:"primaryKey;size:64"`
+	Data      string `gorm:"type:text"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type Review struct {
+	gorm.Model
+	ProductID  uint   `gorm:"not null;index"`
+	CustomerID *uint  `gorm:"index"`
+	AuthorName string `gorm:"not null;size:100"`
+	Rating     int    `gorm:"not null"`
+	Text       string `gorm:"type:text"`
+
+	Product Product
+}
+
+type Customer struct {
+	gorm.Model
+	Email           string `gorm:"unique;not null;size:255"`
+	PasswordHash    string `gorm:"not null;size:255"`
+	Name            string `gorm:"size:100"`
+	Phone           string `gorm:"size:30"`
+	EmailVerifiedAt *time.Time
+	Addresses       []Address `gorm:"foreignKey:CustomerID"`
+}
+
+type CustomerToken struct {
+	gorm.Model
+	CustomerID uint      `gorm:"not null;index"`
+	Purpose    string    `gorm:"not null;size:30"`
+	TokenHash  string    `gorm:"unique;not null;size:64"`
+	ExpiresAt  time.Time `gorm:"not null"`
+	UsedAt     *time.Time
+}
+
+type Address struct {
+	gorm.Model
+	CustomerID uint   `gorm:"not null;index"`
+	Recipient  string `gorm:"not null;size:100"`
+	Phone      string `gorm:"size:30"`
+	City       string `gorm:"not null;size:100"`
+	Street     string `gorm:"not null;size:255"`
+	PostalCode string `gorm:"size:20"`
+	IsDefault  bool   `gorm:"default:false"`
+}
+
+type Order struct {
+	gorm.Model
+	CustomerID *uint       `gorm:"index"`
+	Status     string      `gorm:"not null;size:20;default:new"`
+	Total      float64     `gorm:"not null"`
+	Discount   float64     `gorm:"not null;default:0"`
+	PromoCode  string      `gorm:"size:50"`
+	Address    string      `gorm:"size:500"`
+	Items      []OrderItem `gorm:"foreignKey:OrderID"`
+}
+
+type OrderItem struct {
+	gorm.Model
+	OrderID   uint    `gorm:"not null;index"`
+	ProductID uint    `gorm:"not null"`
+	Name      string  `gorm:"not null;size:255"`
+	Price     float64 `gorm:"not null"`
+	Quantity  int     `gorm:"not null"`
+
+	Product Product
+}
+
+type PromoCode struct {
+	gorm.Model
+	Code               string  `gorm:"unique;not null;size:50"`
+	DiscountType       string  `gorm:"not null;size:10"`
+	DiscountValue      float64 `gorm:"not null"`
+	MinCartTotal       float64 `gorm:"not null;default:0"`
+	MaxUses            int     `gorm:"not null;default:0"`
+	MaxUsesPerCustomer int     `gorm:"not null;default:0"`
+	ValidFrom          *time.Time
+	ValidUntil         *time.Time
+	IsActive           bool       `gorm:"default:true"`
+	Brands             []Brand    `gorm:"many2many:promo_code_brands"`
+	Categories         []Category `gorm:"many2many:promo_code_categories"`
+}
+
+type PromoRedemption struct {
+	gorm.Model
+	PromoCodeID uint    `gorm:"not null;index"`
+	CustomerID  *uint   `gorm:"index"`
+	OrderID     uint    `gorm:"not null"`
+	Discount    float64 `gorm:"not null"`
+}
+
+type SlugHistory struct {
+	ID         uint   `gorm:"primaryKey"`
+	EntityType string `gorm:"not null;size:20;index:idx_slug_history_lookup"`
+	EntityID   uint   `gorm:"not null"`
+	Slug       string `gorm:"not null;size:500;index:idx_slug_history_lookup"`
+	CategoryID uint   `gorm:"not null;default:0"`
+	CreatedAt  time.Time
+}
+
+type AdminUser struct {
+	gorm.Model
+	Login        string `gorm:"unique;not null;size:100"`
+	PasswordHash string `gorm:"not null;size:255"`
+}
+
+type PriceHistory struct {
+	ID           uint `gorm:"primarykey"`
+	ProductID    uint `gorm:"not null;index"`
+	OldPrice     float64
+	NewPrice     float64
+	OldSalePrice float64
+	NewSalePrice float64
+	Reason       string `gorm:"size:255"`
+	CreatedAt    time.Time
+}
+
+// Models модели в порядке создания таблиц
+func Models() []interface{} {
+	return []interface{}{
+		&Brand{},
+		&Category{},
+		&Subcategory{},
+		&Product{},
+		&WishlistItem{},
+		&Session{},
+		&Review{},
+		&Customer{},
+		&CustomerToken{},
+		&Address{},
+		&Order{},
+		&OrderItem{},
+		&PromoCode{},
+		&PromoRedemption{},
+		&SlugHistory{},
+		&AdminUser{},
+		&PriceHistory{},
+	}
+}
+
+// JoinTables таблицы связей многие ко многим, которые создаются вместе с моделями
+var JoinTables = []string{"promo_code_brands", "promo_code_categories"}
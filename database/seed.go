@@ -2,25 +2,42 @@ package database
 
 import (
 	"cosmetics_catalog/models"
+	"cosmetics_catalog/repositories"
+	"fmt"
+
+	"gorm.io/gorm"
 )
 
-func SeedTestData() error {
+// SeedTestData заполняет базу тестовым каталогом и промокодами
+func SeedTestData(db *gorm.DB) error {
+	if err := SeedCatalog(repositories.NewCatalog(db)); err != nil {
+		return err
+	}
+	return SeedPromoCodes(db)
+}
+
+// SeedCatalog заполняет тестовыми брендами, категориями и продуктами
+// репозитории каталога, в базе или в памяти. Хранилище должно быть пустым:
+// продукты ссылаются на бренды по ID, начиная с 1. Слаги брендов, категорий
+// и подкатегорий генерируются из названий при создании, у продуктов заданы
+// короткие латинские слаги вместо транслитерации длинных названий
+func SeedCatalog(catalog repositories.Catalog) error {
 	// 1. Бренды
 	brands := []models.Brand{
-		{Name: "Bioderma", Slug: "bioderma"},
-		{Name: "L'Oréal Paris", Slug: "loreal-paris"},
-		{Name: "Pusy", Slug: "pusy"},
-		{Name: "Dior", Slug: "dior"},
-		{Name: "Dr. Jart+", Slug: "dr-jart"},
-		{Name: "Clarins", Slug: "clarins"},
-		{Name: "Catrice", Slug: "catrice"},
-		{Name: "Clinique", Slug: "clinique"},
-		{Name: "Shiseido", Slug: "shiseido"},
-		{Name: "Kiko Milano", Slug: "kiko-milano"},
-		{Name: "Erborian", Slug: "erborian"},
+		{Name: "Bioderma"},
+		{Name: "L'Oréal Paris"},
+		{Name: "Pusy"},
+		{Name: "Dior"},
+		{Name: "Dr. Jart+"},
+		{Name: "Clarins"},
+		{Name: "Catrice"},
+		{Name: "Clinique"},
+		{Name: "Shiseido"},
+		{Name: "Kiko Milano"},
+		{Name: "Erborian"},
 	}
-	for _, brand := range brands {
-		if err := DB.Create(&brand).Error; err != nil {
+	for i := range brands {
+		if err := catalog.Brands.Create(&brands[i]); err != nil {
 			return err
 		}
 	}
@@ -29,33 +46,50 @@ func SeedTestData() error {
 	categories := []models.Category{
 		{
 			Name: "Макияж",
-			Slug: "makiyazh",
 			Subcategories: []models.Subcategory{
-				{Name: "Лицо", Slug: "litso"},
-				{Name: "Глаза", Slug: "glaza"},
-				{Name: "Губы", Slug: "guby"},
+				{Name: "Лицо"},
+				{Name: "Глаза"},
+				{Name: "Губы"},
 			},
 		},
 		{
 			Name: "Уход",
-			Slug: "ukhod",
 			Subcategories: []models.Subcategory{
-				{Name: "Очищение", Slug: "ochishenie"},
-				{Name: "Увлажнение", Slug: "uvlazhnenie"},
-				{Name: "Тонизирование", Slug: "tonizirovanie"},
+				{Name: "Очищение"},
+				{
+					Name: "Увлажнение",
+					Children: []models.Subcategory{
+						{Name: "Маски"},
+					},
+				},
+				{Name: "Тонизирование"},
 			},
 		},
 	}
-	for _, category := range categories {
-		if err := DB.Create(&category).Error; err != nil {
+	for i := range categories {
+		if err := catalog.Categories.Create(&categories[i]); err != nil {
 			return err
 		}
 	}
 
-	// 3. Получение нужных подкатегорий из БД
+	// 3. Получение нужных подкатегорий. После создания у вложенных
+	// подкатегорий заполнены ID, ищем их по названию
+	created := map[string]models.Subcategory{}
+	var collect func(subcategories []models.Subcategory)
+	collect = func(subcategories []models.Subcategory) {
+		for _, subcategory := range subcategories {
+			created[subcategory.Name] = subcategory
+			collect(subcategory.Children)
+		}
+	}
+	for _, category := range categories {
+		collect(category.Subcategories)
+	}
+
 	var (
 		makeupFace, makeupEyes, makeupLips       models.Subcategory
 		careCleansing, careHydration, careToning models.Subcategory
+		careHydrationMasks                       models.Subcategory
 	)
 	subMap := map[string]*models.Subcategory{
 		"Лицо":          &makeupFace,
@@ -64,11 +98,14 @@ func SeedTestData() error {
 		"Очищение":      &careCleansing,
 		"Увлажнение":    &careHydration,
 		"Тонизирование": &careToning,
+		"Маски":         &careHydrationMasks,
 	}
 	for name, ptr := range subMap {
-		if err := DB.Where("name = ?", name).First(ptr).Error; err != nil {
-			return err
+		subcategory, ok := created[name]
+		if !ok {
+			return fmt.Errorf("подкатегория %q не создана", name)
 		}
+		*ptr = subcategory
 	}
 
 	// 4. Продукты
@@ -82,6 +119,8 @@ func SeedTestData() error {
 			Price:         1450.00,
 			ImagePath:     "/photos/photo_2025-06-03 11.29.24.jpeg",
 			Description:   "Мягкий пенящийся гель для чувствительной кожи",
+			Volume:        "200 мл",
+			Ingredients:   "Aqua, Sodium Laureth Sulfate, Cocamidopropyl Betaine, Glycerin, Sodium Chloride, Citric Acid",
 			IsOnSale:      false,
 		},
 		{
@@ -92,6 +131,8 @@ func SeedTestData() error {
 			Price:         1290.00,
 			ImagePath:     "/photos/photo_2025-06-03 11.29.27.jpeg",
 			Description:   "Легендарная мицеллярная вода для чувствительной кожи",
+			Volume:        "250 мл",
+			Ingredients:   "Aqua, PEG-6 Caprylic/Capric Glycerides, Fructooligosaccharides, Cucumis Sativus Fruit Extract, Propylene Glycol, Cetrimonium Bromide",
 			IsOnSale:      true,
 			SalePrice:     1099.00,
 		},
@@ -103,6 +144,8 @@ func SeedTestData() error {
 			Price:         1350.00,
 			ImagePath:     "/photos/photo_2025-06-03 11.29.30.jpeg",
 			Description:   "Очищающий гель для проблемной кожи с цинком",
+			Volume:        "200 мл",
+			Ingredients:   "Aqua, Sodium Laureth Sulfate, Cocamidopropyl Betaine, Glycerin, Sodium Chloride, Citric Acid",
 			IsOnSale:      false,
 		},
 		{
@@ -113,6 +156,8 @@ func SeedTestData() error {
 			Price:         1650.00,
 			ImagePath:     "/photos/photo_2025-06-03 11.29.29.jpeg",
 			Description:   "Нежный гель для сухой и атопичной кожи",
+			Volume:        "200 мл",
+			Ingredients:   "Aqua, Sodium Laureth Sulfate, Cocamidopropyl Betaine, Glycerin, Sodium Chloride, Citric Acid",
 			IsOnSale:      true,
 			SalePrice:     1399.00,
 		},
@@ -124,6 +169,8 @@ func SeedTestData() error {
 			Price:         1890.00,
 			ImagePath:     "images/bioderma/hydrabio-gel-creme.jpg",
 			Description:   "Легкий гель-крем для обезвоженной кожи",
+			Volume:        "50 мл",
+			Ingredients:   "Aqua, Glycerin, Dimethicone, Butylene Glycol, Sodium Hyaluronate, Tocopheryl Acetate",
 			IsOnSale:      false,
 		},
 		{
@@ -134,6 +181,8 @@ func SeedTestData() error {
 			Price:         1250.00,
 			ImagePath:     "images/bioderma/hydrabio-patch.jpg",
 			Description:   "Экспресс-уход для области вокруг глаз",
+			Volume:        "60 шт.",
+			Ingredients:   "Aqua, Glycerin, Carrageenan, Ceratonia Siliqua Gum, Sodium Hyaluronate, Caffeine",
 			IsOnSale:      true,
 			SalePrice:     999.00,
 		},
@@ -145,6 +194,8 @@ func SeedTestData() error {
 			Price:         2100.00,
 			ImagePath:     "images/bioderma/sebium-bb-cream.jpg",
 			Description:   "Тональное средство с матирующим эффектом",
+			Volume:        "40 мл",
+			Ingredients:   "Aqua, Cyclopentasiloxane, Titanium Dioxide, Zinc Oxide, Glycerin, Iron Oxides (CI 77491, CI 77492, CI 77499)",
 			IsOnSale:      false,
 		},
 		{
@@ -155,6 +206,8 @@ func SeedTestData() error {
 			Price:         1750.00,
 			ImagePath:     "images/bioderma/mineral-powder.jpg",
 			Description:   "Рассыпчатая пудра для чувствительной кожи",
+			Volume:        "10 г",
+			Ingredients:   "Talc, Mica, Zinc Stearate, Dimethicone, Silica, Iron Oxides (CI 77491, CI 77492, CI 77499)",
 			IsOnSale:      true,
 			SalePrice:     1490.00,
 		},
@@ -166,6 +219,8 @@ func SeedTestData() error {
 			Price:         890.00,
 			ImagePath:     "images/bioderma/atoderm-lip-stick.jpg",
 			Description:   "Восстанавливающий бальзам для сухих губ",
+			Volume:        "3,5 г",
+			Ingredients:   "Ricinus Communis Seed Oil, Butyrospermum Parkii Butter, Cera Alba, Tocopherol, Parfum",
 			IsOnSale:      false,
 		},
 		{
@@ -176,6 +231,8 @@ func SeedTestData() error {
 			Price:         1200.00,
 			ImagePath:     "images/bioderma/sensibio-lip.jpg",
 			Description:   "Уходовый бальзам с легким тонирующим эффектом",
+			Volume:        "3,5 г",
+			Ingredients:   "Ricinus Communis Seed Oil, Butyrospermum Parkii Butter, Cera Alba, Tocopherol, Parfum",
 			IsOnSale:      true,
 			SalePrice:     990.00,
 		},
@@ -188,6 +245,8 @@ func SeedTestData() error {
 			Price:         899.00,
 			ImagePath:     "images/loreal/true-match.jpg",
 			Description:   "Тональный крем с естественным покрытием",
+			Volume:        "30 мл",
+			Ingredients:   "Aqua, Cyclopentasiloxane, Isododecane, Glycerin, Titanium Dioxide, Iron Oxides (CI 77491, CI 77492, CI 77499)",
 			IsOnSale:      false,
 		},
 		{
@@ -198,6 +257,8 @@ func SeedTestData() error {
 			Price:         799.00,
 			ImagePath:     "/photos/photo_2025-06-03 11.52.29.jpeg",
 			Description:   "Объемная тушь для эффекта накладных ресниц",
+			Volume:        "9 мл",
+			Ingredients:   "Aqua, Cera Alba, Copernicia Cerifera Cera, Stearic Acid, Acacia Senegal Gum, Iron Oxides (CI 77499)",
 			IsOnSale:      true,
 			SalePrice:     699.00,
 		},
@@ -209,6 +270,8 @@ func SeedTestData() error {
 			Price:         1299.00,
 			ImagePath:     "images/loreal/infallible-24h-fresh.jpg",
 			Description:   "Стойкий тональный крем с эффектом свежести на 24 часа",
+			Volume:        "30 мл",
+			Ingredients:   "Aqua, Cyclopentasiloxane, Isododecane, Glycerin, Titanium Dioxide, Iron Oxides (CI 77491, CI 77492, CI 77499)",
 			IsOnSale:      false,
 		},
 		{
@@ -219,6 +282,8 @@ func SeedTestData() error {
 			Price:         899.00,
 			ImagePath:     "images/loreal/infallible-concealer.jpg",
 			Description:   "Высокопокрывающий консилер с матовым финишем",
+			Volume:        "7 мл",
+			Ingredients:   "Aqua, Dimethicone, Isododecane, Glycerin, Titanium Dioxide, Iron Oxides (CI 77491, CI 77492, CI 77499)",
 			IsOnSale:      true,
 			SalePrice:     749.00,
 		},
@@ -230,6 +295,8 @@ func SeedTestData() error {
 			Price:         599.00,
 			ImagePath:     "/photos/photo_2025-06-03 11.52.32.jpeg",
 			Description:   "Стик-тени с эффектом влажного сияния",
+			Volume:        "2,5 г",
+			Ingredients:   "Talc, Mica, Magnesium Stearate, Dimethicone, Caprylyl Glycol, Titanium Dioxide (CI 77891)",
 			IsOnSale:      false,
 		},
 		{
@@ -240,6 +307,8 @@ func SeedTestData() error {
 			Price:         699.00,
 			ImagePath:     "/photos/photo_2025-06-03 11.52.34.jpeg",
 			Description:   "Стойкая подводка с тонким аппликатором",
+			Volume:        "3 мл",
+			Ingredients:   "Aqua, Styrene/Acrylates Copolymer, Butylene Glycol, Phenoxyethanol, CI 77266",
 			IsOnSale:      true,
 			SalePrice:     599.00,
 		},
@@ -251,6 +320,8 @@ func SeedTestData() error {
 			Price:         899.00,
 			ImagePath:     "images/loreal/rouge-signature.jpg",
 			Description:   "Легкая стойкая помада с эффектом поцелуя",
+			Volume:        "7 мл",
+			Ingredients:   "Isododecane, Dimethicone, Trimethylsiloxysilicate, Silica, Tocopheryl Acetate, CI 15850",
 			IsOnSale:      false,
 		},
 		{
@@ -261,6 +332,8 @@ func SeedTestData() error {
 			Price:         799.00,
 			ImagePath:     "images/loreal/glow-paradise.jpg",
 			Description:   "Бальзам-блеск с уходовыми маслами",
+			Volume:        "6 мл",
+			Ingredients:   "Polybutene, Hydrogenated Polyisobutene, Simmondsia Chinensis Seed Oil, Tocopherol, Parfum",
 			IsOnSale:      true,
 			SalePrice:     699.00,
 		},
@@ -272,6 +345,8 @@ func SeedTestData() error {
 			Price:         599.00,
 			ImagePath:     "/photos/photo_2025-06-03 11.29.32.jpeg",
 			Description:   "Безспиртовая формула для чувствительной кожи",
+			Volume:        "250 мл",
+			Ingredients:   "Aqua, PEG-6 Caprylic/Capric Glycerides, Fructooligosaccharides, Cucumis Sativus Fruit Extract, Propylene Glycol, Cetrimonium Bromide",
 			IsOnSale:      false,
 		},
 		{
@@ -282,6 +357,8 @@ func SeedTestData() error {
 			Price:         499.00,
 			ImagePath:     "/photos/photo_2025-06-03 11.29.35.jpeg",
 			Description:   "Очищающий гель с глиной для проблемной кожи",
+			Volume:        "200 мл",
+			Ingredients:   "Aqua, Sodium Laureth Sulfate, Cocamidopropyl Betaine, Glycerin, Sodium Chloride, Citric Acid",
 			IsOnSale:      true,
 			SalePrice:     399.00,
 		},
@@ -294,6 +371,8 @@ func SeedTestData() error {
 			Price:         1890.00,
 			ImagePath:     "images/pusy/skin-like-foundation.jpg",
 			Description:   "Легкая тональная основа с эффектом второй кожи",
+			Volume:        "30 мл",
+			Ingredients:   "Aqua, Cyclopentasiloxane, Isododecane, Glycerin, Titanium Dioxide, Iron Oxides (CI 77491, CI 77492, CI 77499)",
 			IsOnSale:      false,
 		},
 		{
@@ -304,6 +383,8 @@ func SeedTestData() error {
 			Price:         990.00,
 			ImagePath:     "images/pusy/perfect-cover-concealer.jpg",
 			Description:   "Высокопокрывающий консилер с кремовой текстурой",
+			Volume:        "7 мл",
+			Ingredients:   "Aqua, Dimethicone, Isododecane, Glycerin, Titanium Dioxide, Iron Oxides (CI 77491, CI 77492, CI 77499)",
 			IsOnSale:      true,
 			SalePrice:     790.00,
 		},
@@ -315,6 +396,8 @@ func SeedTestData() error {
 			Price:         1190.00,
 			ImagePath:     "images/pusy/velvet-touch-lipstick.jpg",
 			Description:   "Стойкая матовая помада с комфортной текстурой",
+			Volume:        "7 мл",
+			Ingredients:   "Isododecane, Dimethicone, Trimethylsiloxysilicate, Silica, Tocopheryl Acetate, CI 15850",
 			IsOnSale:      false,
 		},
 		{
@@ -325,6 +408,8 @@ func SeedTestData() error {
 			Price:         790.00,
 			ImagePath:     "images/pusy/glass-shine-gloss.jpg",
 			Description:   "Блеск с эффектом стеклянных губ",
+			Volume:        "6 мл",
+			Ingredients:   "Polybutene, Hydrogenated Polyisobutene, Simmondsia Chinensis Seed Oil, Tocopherol, Parfum",
 			IsOnSale:      true,
 			SalePrice:     590.00,
 		},
@@ -336,6 +421,8 @@ func SeedTestData() error {
 			Price:         890.00,
 			ImagePath:     "images/pusy/3in1-micellar.jpg",
 			Description:   "Удаляет макияж, очищает и тонизирует кожу",
+			Volume:        "250 мл",
+			Ingredients:   "Aqua, PEG-6 Caprylic/Capric Glycerides, Fructooligosaccharides, Cucumis Sativus Fruit Extract, Propylene Glycol, Cetrimonium Bromide",
 			IsOnSale:      false,
 		},
 		{
@@ -346,6 +433,8 @@ func SeedTestData() error {
 			Price:         690.00,
 			ImagePath:     "images/pusy/pure-balance-gel.jpg",
 			Description:   "Мягкий гель для нормальной и комбинированной кожи",
+			Volume:        "200 мл",
+			Ingredients:   "Aqua, Sodium Laureth Sulfate, Cocamidopropyl Betaine, Glycerin, Sodium Chloride, Citric Acid",
 			IsOnSale:      true,
 			SalePrice:     490.00,
 		},
@@ -359,6 +448,8 @@ func SeedTestData() error {
 			Price:         3200.00,
 			ImagePath:     "images/dior/lip-glow.jpg",
 			Description:   "Бальзам для губ с эффектом сияния и уходом",
+			Volume:        "3,5 г",
+			Ingredients:   "Ricinus Communis Seed Oil, Butyrospermum Parkii Butter, Cera Alba, Tocopherol, Parfum",
 			IsOnSale:      false,
 		},
 		{
@@ -369,6 +460,8 @@ func SeedTestData() error {
 			Price:         3500.00,
 			ImagePath:     "images/dior/rouge-dior.jpg",
 			Description:   "Стойкая кремовая текстура с 16-часовым ношением",
+			Volume:        "7 мл",
+			Ingredients:   "Isododecane, Dimethicone, Trimethylsiloxysilicate, Silica, Tocopheryl Acetate, CI 15850",
 			IsOnSale:      true,
 			SalePrice:     3100.00,
 		},
@@ -380,6 +473,8 @@ func SeedTestData() error {
 			Price:         2500.00,
 			ImagePath:     "images/dior/lipliner.jpg",
 			Description:   "Мягкий карандаш для контура губ",
+			Volume:        "1,2 г",
+			Ingredients:   "Hydrogenated Vegetable Oil, Synthetic Wax, Mica, Tocopheryl Acetate, Iron Oxides (CI 77491, CI 77499)",
 			IsOnSale:      false,
 		},
 		{
@@ -390,6 +485,8 @@ func SeedTestData() error {
 			Price:         2900.00,
 			ImagePath:     "/photos/tush.jpg",
 			Description:   "Тушь для объема с инновационной щеточкой",
+			Volume:        "9 мл",
+			Ingredients:   "Aqua, Cera Alba, Copernicia Cerifera Cera, Stearic Acid, Acacia Senegal Gum, Iron Oxides (CI 77499)",
 			IsOnSale:      false,
 		},
 		{
@@ -400,6 +497,8 @@ func SeedTestData() error {
 			Price:         4500.00,
 			ImagePath:     "/photos/photo_2025-06-03 11.52.36.jpeg",
 			Description:   "Профессиональная палетка с 9 оттенками",
+			Volume:        "10 г",
+			Ingredients:   "Talc, Mica, Magnesium Stearate, Dimethicone, Caprylyl Glycol, Titanium Dioxide (CI 77891)",
 			IsOnSale:      true,
 			SalePrice:     4000.00,
 		},
@@ -411,6 +510,8 @@ func SeedTestData() error {
 			Price:         2700.00,
 			ImagePath:     "/photos/photo_2025-06-03 11.52.38.jpeg",
 			Description:   "Стойкая подводка с тонким аппликатором",
+			Volume:        "3 мл",
+			Ingredients:   "Aqua, Styrene/Acrylates Copolymer, Butylene Glycol, Phenoxyethanol, CI 77266",
 			IsOnSale:      false,
 		},
 		{
@@ -421,6 +522,8 @@ func SeedTestData() error {
 			Price:         4800.00,
 			ImagePath:     "images/dior/forever-glow.jpg",
 			Description:   "Тональное средство с эффектом сияния",
+			Volume:        "30 мл",
+			Ingredients:   "Aqua, Cyclopentasiloxane, Isododecane, Glycerin, Titanium Dioxide, Iron Oxides (CI 77491, CI 77492, CI 77499)",
 			IsOnSale:      false,
 		},
 		{
@@ -431,6 +534,8 @@ func SeedTestData() error {
 			Price:         3800.00,
 			ImagePath:     "images/dior/glow-highlighter.jpg",
 			Description:   "Хайлайтер для сияющего эффекта",
+			Volume:        "6 г",
+			Ingredients:   "Mica, Synthetic Fluorphlogopite, Dimethicone, Tin Oxide, Titanium Dioxide (CI 77891)",
 			IsOnSale:      true,
 			SalePrice:     3400.00,
 		},
@@ -442,6 +547,8 @@ func SeedTestData() error {
 			Price:         3800.00,
 			ImagePath:     "images/dior/capture-totale.jpg",
 			Description:   "Деликатное очищение с розовой водой",
+			Volume:        "250 мл",
+			Ingredients:   "Aqua, PEG-6 Caprylic/Capric Glycerides, Fructooligosaccharides, Cucumis Sativus Fruit Extract, Propylene Glycol, Cetrimonium Bromide",
 			IsOnSale:      false,
 		},
 		{
@@ -452,6 +559,8 @@ func SeedTestData() error {
 			Price:         5200.00,
 			ImagePath:     "images/dior/capture-youth.jpg",
 			Description:   "Антивозрастной уход для области вокруг глаз",
+			Volume:        "15 мл",
+			Ingredients:   "Aqua, Glycerin, Dimethicone, Caffeine, Sodium Hyaluronate, Tocopheryl Acetate",
 			IsOnSale:      true,
 			SalePrice:     4700.00,
 		},
@@ -465,6 +574,8 @@ func SeedTestData() error {
 			Price:         2800.00,
 			ImagePath:     "images/drjart/bb-cream.jpg",
 			Description:   "Многофункциональный BB-крем с SPF40 и уходом",
+			Volume:        "40 мл",
+			Ingredients:   "Aqua, Cyclopentasiloxane, Titanium Dioxide, Zinc Oxide, Glycerin, Iron Oxides (CI 77491, CI 77492, CI 77499)",
 			IsOnSale:      false,
 		},
 		{
@@ -475,6 +586,8 @@ func SeedTestData() error {
 			Price:         3200.00,
 			ImagePath:     "images/drjart/cc-cream.jpg",
 			Description:   "Корректирующий крем с противовоспалительным эффектом",
+			Volume:        "40 мл",
+			Ingredients:   "Aqua, Cyclopentasiloxane, Titanium Dioxide, Zinc Oxide, Glycerin, Iron Oxides (CI 77491, CI 77492, CI 77499)",
 			IsOnSale:      true,
 			SalePrice:     2900.00,
 		},
@@ -486,6 +599,8 @@ func SeedTestData() error {
 			Price:         3500.00,
 			ImagePath:     "images/drjart/ceramidin-cushion.jpg",
 			Description:   "Тональное средство с церамидами для сухой кожи",
+			Volume:        "15 г",
+			Ingredients:   "Aqua, Cyclopentasiloxane, Titanium Dioxide, Zinc Oxide, Glycerin, Iron Oxides (CI 77491, CI 77492, CI 77499)",
 			IsOnSale:      false,
 		},
 		{
@@ -496,6 +611,8 @@ func SeedTestData() error {
 			Price:         4200.00,
 			ImagePath:     "images/drjart/ceramidin-cream.jpg",
 			Description:   "Интенсивное увлажнение с керамидным комплексом",
+			Volume:        "50 мл",
+			Ingredients:   "Aqua, Glycerin, Dimethicone, Butylene Glycol, Sodium Hyaluronate, Tocopheryl Acetate",
 			IsOnSale:      false,
 		},
 		{
@@ -506,6 +623,8 @@ func SeedTestData() error {
 			Price:         3800.00,
 			ImagePath:     "images/drjart/cicapair-serum.jpg",
 			Description:   "Успокаивающая сыворотка для чувствительной кожи",
+			Volume:        "30 мл",
+			Ingredients:   "Aqua, Glycerin, Niacinamide, Centella Asiatica Extract, Sodium Hyaluronate, Panthenol",
 			IsOnSale:      true,
 			SalePrice:     3400.00,
 		},
@@ -513,10 +632,12 @@ func SeedTestData() error {
 			Name:          "Маска Rubber Lover Vital Hydra Solution",
 			Slug:          "drjart-hydra-mask",
 			BrandID:       5,
-			SubcategoryID: careHydration.ID,
+			SubcategoryID: careHydrationMasks.ID,
 			Price:         1200.00,
 			ImagePath:     "images/drjart/hydra-mask.jpg",
 			Description:   "Гидрофильная маска для интенсивного увлажнения",
+			Volume:        "50 мл",
+			Ingredients:   "Aqua, Glycerin, Butylene Glycol, Kaolin, Sodium Hyaluronate, Allantoin",
 			IsOnSale:      false,
 		},
 		{
@@ -527,6 +648,8 @@ func SeedTestData() error {
 			Price:         1800.00,
 			ImagePath:     "images/drjart/micro-foam.jpg",
 			Description:   "Мягкая пенка с микрочастицами для глубокого очищения",
+			Volume:        "200 мл",
+			Ingredients:   "Aqua, Sodium Laureth Sulfate, Cocamidopropyl Betaine, Glycerin, Sodium Chloride, Citric Acid",
 			IsOnSale:      false,
 		},
 		{
@@ -537,6 +660,8 @@ func SeedTestData() error {
 			Price:         2200.00,
 			ImagePath:     "images/drjart/microwater.jpg",
 			Description:   "Деликатное очищение без необходимости смывания",
+			Volume:        "250 мл",
+			Ingredients:   "Aqua, PEG-6 Caprylic/Capric Glycerides, Fructooligosaccharides, Cucumis Sativus Fruit Extract, Propylene Glycol, Cetrimonium Bromide",
 			IsOnSale:      true,
 			SalePrice:     1900.00,
 		},
@@ -548,6 +673,8 @@ func SeedTestData() error {
 			Price:         2500.00,
 			ImagePath:     "images/drjart/eye-patch.jpg",
 			Description:   "Гидрогелевые патчи для мгновенного свежего взгляда",
+			Volume:        "60 шт.",
+			Ingredients:   "Aqua, Glycerin, Carrageenan, Ceratonia Siliqua Gum, Sodium Hyaluronate, Caffeine",
 			IsOnSale:      false,
 		},
 		{
@@ -558,6 +685,8 @@ func SeedTestData() error {
 			Price:         3600.00,
 			ImagePath:     "images/drjart/tiger-grass.jpg",
 			Description:   "Восстанавливающий крем с тигровой травой",
+			Volume:        "50 мл",
+			Ingredients:   "Aqua, Glycerin, Dimethicone, Butylene Glycol, Sodium Hyaluronate, Tocopheryl Acetate",
 			IsOnSale:      true,
 			SalePrice:     3200.00,
 		},
@@ -570,6 +699,8 @@ func SeedTestData() error {
 			Price:         4900.00,
 			ImagePath:     "images/clarins/cushion-foundation.jpg",
 			Description:   "Кушон с эффектом естественного сияния",
+			Volume:        "15 г",
+			Ingredients:   "Aqua, Cyclopentasiloxane, Titanium Dioxide, Zinc Oxide, Glycerin, Iron Oxides (CI 77491, CI 77492, CI 77499)",
 			IsOnSale:      false,
 		},
 		{
@@ -580,6 +711,8 @@ func SeedTestData() error {
 			Price:         3200.00,
 			ImagePath:     "images/clarins/blush-prodige.jpg",
 			Description:   "Румяна с светоотражающими частицами",
+			Volume:        "5 г",
+			Ingredients:   "Talc, Mica, Dimethicone, Ethylhexyl Palmitate, Iron Oxides (CI 77491), CI 15850",
 			IsOnSale:      true,
 			SalePrice:     2900.00,
 		},
@@ -593,6 +726,8 @@ func SeedTestData() error {
 			Price:         2200.00,
 			ImagePath:     "/photos/photo_2025-06-03 11.52.40.jpeg",
 			Description:   "Матовые тени для век стойкой формулы",
+			Volume:        "2,5 г",
+			Ingredients:   "Talc, Mica, Magnesium Stearate, Dimethicone, Caprylyl Glycol, Titanium Dioxide (CI 77891)",
 			IsOnSale:      false,
 		},
 		{
@@ -603,6 +738,8 @@ func SeedTestData() error {
 			Price:         2500.00,
 			ImagePath:     "/photos/photo_2025-06-03 11.52.42.jpeg",
 			Description:   "Стойкая подводка с тонким аппликатором",
+			Volume:        "3 мл",
+			Ingredients:   "Aqua, Styrene/Acrylates Copolymer, Butylene Glycol, Phenoxyethanol, CI 77266",
 			IsOnSale:      false,
 		},
 
@@ -615,6 +752,8 @@ func SeedTestData() error {
 			Price:         2700.00,
 			ImagePath:     "images/clarins/joli-rouge.jpg",
 			Description:   "Блестящая помада с увлажняющим эффектом",
+			Volume:        "3,5 г",
+			Ingredients:   "Ricinus Communis Seed Oil, Ozokerite, Octyldodecanol, Cera Alba, Tocopheryl Acetate, CI 15850",
 			IsOnSale:      false,
 		},
 
@@ -627,6 +766,8 @@ func SeedTestData() error {
 			Price:         2800.00,
 			ImagePath:     "images/clarins/foaming-cleanser.jpg",
 			Description:   "Мягкая очищающая пенка",
+			Volume:        "200 мл",
+			Ingredients:   "Aqua, Sodium Laureth Sulfate, Cocamidopropyl Betaine, Glycerin, Sodium Chloride, Citric Acid",
 			IsOnSale:      true,
 			SalePrice:     2500.00,
 		},
@@ -640,6 +781,8 @@ func SeedTestData() error {
 			Price:         4900.00,
 			ImagePath:     "images/clarins/hydra-essentiel.jpg",
 			Description:   "Увлажняющий крем с шелковой текстурой",
+			Volume:        "50 мл",
+			Ingredients:   "Aqua, Glycerin, Dimethicone, Butylene Glycol, Sodium Hyaluronate, Tocopheryl Acetate",
 			IsOnSale:      false,
 		},
 
@@ -652,6 +795,8 @@ func SeedTestData() error {
 			Price:         3100.00,
 			ImagePath:     "images/clarins/toning-lotion.jpg",
 			Description:   "Тонизирующий лосьон с экстрактом ромашки",
+			Volume:        "200 мл",
+			Ingredients:   "Aqua, Butylene Glycol, Glycerin, Niacinamide, Sodium Hyaluronate, Phenoxyethanol",
 			IsOnSale:      false,
 		},
 
@@ -664,6 +809,8 @@ func SeedTestData() error {
 			Price:         3900.00,
 			ImagePath:     "images/clarins/flash-balm.jpg",
 			Description:   "Бальзам для мгновенного сияния кожи",
+			Volume:        "50 мл",
+			Ingredients:   "Aqua, Glycerin, Dimethicone, Butylene Glycol, Sodium Hyaluronate, Tocopheryl Acetate",
 			IsOnSale:      true,
 			SalePrice:     3500.00,
 		},
@@ -675,6 +822,8 @@ func SeedTestData() error {
 			Price:         2200.00,
 			ImagePath:     "images/clarins/lip-oil.jpg",
 			Description:   "Питательное масло для губ",
+			Volume:        "6 мл",
+			Ingredients:   "Polybutene, Hydrogenated Polyisobutene, Simmondsia Chinensis Seed Oil, Tocopherol, Parfum",
 			IsOnSale:      false,
 		},
 		// Catrice
@@ -686,6 +835,8 @@ func SeedTestData() error {
 			Price:         899.00,
 			ImagePath:     "images/catrice/hd-foundation.jpg",
 			Description:   "Тональная основа с полным покрытием",
+			Volume:        "30 мл",
+			Ingredients:   "Aqua, Cyclopentasiloxane, Isododecane, Glycerin, Titanium Dioxide, Iron Oxides (CI 77491, CI 77492, CI 77499)",
 			IsOnSale:      true,
 			SalePrice:     699.00,
 		},
@@ -697,6 +848,8 @@ func SeedTestData() error {
 			Price:         599.00,
 			ImagePath:     "images/catrice/mattifying-powder.jpg",
 			Description:   "Матирующая пудра для лица",
+			Volume:        "10 г",
+			Ingredients:   "Talc, Mica, Zinc Stearate, Dimethicone, Silica, Iron Oxides (CI 77491, CI 77492, CI 77499)",
 			IsOnSale:      false,
 		},
 
@@ -709,6 +862,8 @@ func SeedTestData() error {
 			Price:         649.00,
 			ImagePath:     "images/catrice/glam-doll.jpg",
 			Description:   "Тушь для объема ресниц",
+			Volume:        "9 мл",
+			Ingredients:   "Aqua, Cera Alba, Copernicia Cerifera Cera, Stearic Acid, Acacia Senegal Gum, Iron Oxides (CI 77499)",
 			IsOnSale:      false,
 		},
 		{
@@ -719,6 +874,8 @@ func SeedTestData() error {
 			Price:         399.00,
 			ImagePath:     "images/catrice/eyebrow-pencil.jpg",
 			Description:   "Карандаш для бровей с щеточкой",
+			Volume:        "1,2 г",
+			Ingredients:   "Hydrogenated Vegetable Oil, Synthetic Wax, Mica, Tocopheryl Acetate, Iron Oxides (CI 77491, CI 77499)",
 			IsOnSale:      true,
 			SalePrice:     349.00,
 		},
@@ -732,6 +889,8 @@ func SeedTestData() error {
 			Price:         349.00,
 			ImagePath:     "images/catrice/lip-glow.jpg",
 			Description:   "Бальзам для губ с эффектом сияния",
+			Volume:        "3,5 г",
+			Ingredients:   "Ricinus Communis Seed Oil, Butyrospermum Parkii Butter, Cera Alba, Tocopherol, Parfum",
 			IsOnSale:      false,
 		},
 
@@ -744,6 +903,8 @@ func SeedTestData() error {
 			Price:         599.00,
 			ImagePath:     "images/catrice/cleansing-balm.jpg",
 			Description:   "Бальзам для демакияжа",
+			Volume:        "125 мл",
+			Ingredients:   "Carthamus Tinctorius Seed Oil, Caprylic/Capric Triglyceride, PEG-20 Glyceryl Triisostearate, Polyethylene, Tocopherol",
 			IsOnSale:      false,
 		},
 
@@ -756,6 +917,8 @@ func SeedTestData() error {
 			Price:         749.00,
 			ImagePath:     "images/catrice/hydro-moisturizer.jpg",
 			Description:   "Легкий увлажняющий крем",
+			Volume:        "50 мл",
+			Ingredients:   "Aqua, Glycerin, Dimethicone, Butylene Glycol, Sodium Hyaluronate, Tocopheryl Acetate",
 			IsOnSale:      true,
 			SalePrice:     649.00,
 		},
@@ -769,6 +932,8 @@ func SeedTestData() error {
 			Price:         499.00,
 			ImagePath:     "images/catrice/refresh-toner.jpg",
 			Description:   "Освежающий тоник для лица",
+			Volume:        "200 мл",
+			Ingredients:   "Aqua, Butylene Glycol, Glycerin, Niacinamide, Sodium Hyaluronate, Phenoxyethanol",
 			IsOnSale:      false,
 		},
 
@@ -781,6 +946,8 @@ func SeedTestData() error {
 			Price:         449.00,
 			ImagePath:     "images/catrice/liquid-camouflage.jpg",
 			Description:   "Высокопокрывающий консилер",
+			Volume:        "7 мл",
+			Ingredients:   "Aqua, Dimethicone, Isododecane, Glycerin, Titanium Dioxide, Iron Oxides (CI 77491, CI 77492, CI 77499)",
 			IsOnSale:      false,
 		},
 		{
@@ -791,6 +958,8 @@ func SeedTestData() error {
 			Price:         299.00,
 			ImagePath:     "images/catrice/lip-liner.jpg",
 			Description:   "Карандаш для контура губ",
+			Volume:        "1,2 г",
+			Ingredients:   "Hydrogenated Vegetable Oil, Synthetic Wax, Mica, Tocopheryl Acetate, Iron Oxides (CI 77491, CI 77499)",
 			IsOnSale:      true,
 			SalePrice:     249.00,
 		},
@@ -803,6 +972,8 @@ func SeedTestData() error {
 			Price:         4200.00,
 			ImagePath:     "images/clinique/even-better.jpg",
 			Description:   "Тональный крем с эффектом выравнивания тона",
+			Volume:        "30 мл",
+			Ingredients:   "Aqua, Cyclopentasiloxane, Isododecane, Glycerin, Titanium Dioxide, Iron Oxides (CI 77491, CI 77492, CI 77499)",
 			IsOnSale:      false,
 		},
 		{
@@ -813,6 +984,8 @@ func SeedTestData() error {
 			Price:         3800.00,
 			ImagePath:     "images/clinique/beyond-perfecting.jpg",
 			Description:   "Тональное средство 2-в-1: крем + консилер",
+			Volume:        "30 мл",
+			Ingredients:   "Aqua, Cyclopentasiloxane, Isododecane, Glycerin, Titanium Dioxide, Iron Oxides (CI 77491, CI 77492, CI 77499)",
 			IsOnSale:      true,
 			SalePrice:     3400.00,
 		},
@@ -826,6 +999,8 @@ func SeedTestData() error {
 			Price:         2900.00,
 			ImagePath:     "images/clinique/high-impact.jpg",
 			Description:   "Тушь для объема и длины ресниц",
+			Volume:        "9 мл",
+			Ingredients:   "Aqua, Cera Alba, Copernicia Cerifera Cera, Stearic Acid, Acacia Senegal Gum, Iron Oxides (CI 77499)",
 			IsOnSale:      false,
 		},
 		{
@@ -836,6 +1011,8 @@ func SeedTestData() error {
 			Price:         3500.00,
 			ImagePath:     "images/clinique/shadow-palette.jpg",
 			Description:   "Палетка нейтральных теней для век",
+			Volume:        "10 г",
+			Ingredients:   "Talc, Mica, Magnesium Stearate, Dimethicone, Caprylyl Glycol, Titanium Dioxide (CI 77891)",
 			IsOnSale:      false,
 		},
 
@@ -848,6 +1025,8 @@ func SeedTestData() error {
 			Price:         2200.00,
 			ImagePath:     "images/clinique/black-honey.jpg",
 			Description:   "Культовая оттеночная помада",
+			Volume:        "3,5 г",
+			Ingredients:   "Ricinus Communis Seed Oil, Ozokerite, Octyldodecanol, Cera Alba, Tocopheryl Acetate, CI 15850",
 			IsOnSale:      false,
 		},
 
@@ -860,6 +1039,8 @@ func SeedTestData() error {
 			Price:         3200.00,
 			ImagePath:     "images/clinique/cleansing-balm.jpg",
 			Description:   "Бальзам для демакияжа",
+			Volume:        "125 мл",
+			Ingredients:   "Carthamus Tinctorius Seed Oil, Caprylic/Capric Triglyceride, PEG-20 Glyceryl Triisostearate, Polyethylene, Tocopherol",
 			IsOnSale:      true,
 			SalePrice:     2900.00,
 		},
@@ -873,6 +1054,8 @@ func SeedTestData() error {
 			Price:         4200.00,
 			ImagePath:     "images/clinique/moisture-surge.jpg",
 			Description:   "Гидратирующий крем с 72-часовым действием",
+			Volume:        "50 мл",
+			Ingredients:   "Aqua, Glycerin, Dimethicone, Butylene Glycol, Sodium Hyaluronate, Tocopheryl Acetate",
 			IsOnSale:      false,
 		},
 
@@ -885,6 +1068,8 @@ func SeedTestData() error {
 			Price:         3100.00,
 			ImagePath:     "images/clinique/clarifying-lotion.jpg",
 			Description:   "Тонизирующий лосьон для нормальной кожи",
+			Volume:        "200 мл",
+			Ingredients:   "Aqua, Butylene Glycol, Glycerin, Niacinamide, Sodium Hyaluronate, Phenoxyethanol",
 			IsOnSale:      false,
 		},
 
@@ -897,6 +1082,8 @@ func SeedTestData() error {
 			Price:         2500.00,
 			ImagePath:     "images/clinique/chubby-cheek.jpg",
 			Description:   "Кремовые румяна в стике",
+			Volume:        "5 г",
+			Ingredients:   "Talc, Mica, Dimethicone, Ethylhexyl Palmitate, Iron Oxides (CI 77491), CI 15850",
 			IsOnSale:      true,
 			SalePrice:     2200.00,
 		},
@@ -908,6 +1095,8 @@ func SeedTestData() error {
 			Price:         1800.00,
 			ImagePath:     "images/clinique/pop-lip.jpg",
 			Description:   "Помада с праймером в составе",
+			Volume:        "3,5 г",
+			Ingredients:   "Ricinus Communis Seed Oil, Ozokerite, Octyldodecanol, Cera Alba, Tocopheryl Acetate, CI 15850",
 			IsOnSale:      false,
 		},
 		// Shiseido
@@ -919,6 +1108,8 @@ func SeedTestData() error {
 			Price:         5200.00,
 			ImagePath:     "images/shiseido/synchro-foundation.jpg",
 			Description:   "Тональная основа с технологией самообновления",
+			Volume:        "30 мл",
+			Ingredients:   "Aqua, Cyclopentasiloxane, Isododecane, Glycerin, Titanium Dioxide, Iron Oxides (CI 77491, CI 77492, CI 77499)",
 			IsOnSale:      false,
 		},
 		{
@@ -929,6 +1120,8 @@ func SeedTestData() error {
 			Price:         3500.00,
 			ImagePath:     "images/shiseido/whipped-blush.jpg",
 			Description:   "Воздушные румяна с эффектом вуали",
+			Volume:        "5 г",
+			Ingredients:   "Talc, Mica, Dimethicone, Ethylhexyl Palmitate, Iron Oxides (CI 77491), CI 15850",
 			IsOnSale:      true,
 			SalePrice:     3100.00,
 		},
@@ -942,6 +1135,8 @@ func SeedTestData() error {
 			Price:         2800.00,
 			ImagePath:     "images/shiseido/microliner.jpg",
 			Description:   "Тонкая подводка с кисточкой-иглой",
+			Volume:        "3 мл",
+			Ingredients:   "Aqua, Styrene/Acrylates Copolymer, Butylene Glycol, Phenoxyethanol, CI 77266",
 			IsOnSale:      false,
 		},
 		{
@@ -952,6 +1147,8 @@ func SeedTestData() error {
 			Price:         3900.00,
 			ImagePath:     "images/shiseido/imperial-lash.jpg",
 			Description:   "Тушь для создания императорских ресниц",
+			Volume:        "9 мл",
+			Ingredients:   "Aqua, Cera Alba, Copernicia Cerifera Cera, Stearic Acid, Acacia Senegal Gum, Iron Oxides (CI 77499)",
 			IsOnSale:      false,
 		},
 
@@ -964,6 +1161,8 @@ func SeedTestData() error {
 			Price:         3200.00,
 			ImagePath:     "images/shiseido/visionairy-lipstick.jpg",
 			Description:   "Невесомая гелевая помада",
+			Volume:        "3,5 г",
+			Ingredients:   "Ricinus Communis Seed Oil, Ozokerite, Octyldodecanol, Cera Alba, Tocopheryl Acetate, CI 15850",
 			IsOnSale:      true,
 			SalePrice:     2900.00,
 		},
@@ -977,6 +1176,8 @@ func SeedTestData() error {
 			Price:         4100.00,
 			ImagePath:     "images/shiseido/cleansing-oil.jpg",
 			Description:   "Масло для демакияжа с экстрактом сакуры",
+			Volume:        "150 мл",
+			Ingredients:   "Caprylic/Capric Triglyceride, Simmondsia Chinensis Seed Oil, PEG-20 Glyceryl Triisostearate, Tocopherol, Parfum",
 			IsOnSale:      false,
 		},
 
@@ -989,6 +1190,8 @@ func SeedTestData() error {
 			Price:         2900.00,
 			ImagePath:     "images/shiseido/waso-cream.jpg",
 			Description:   "Увлажняющий крем с экстрактом лотоса",
+			Volume:        "50 мл",
+			Ingredients:   "Aqua, Glycerin, Dimethicone, Butylene Glycol, Sodium Hyaluronate, Tocopheryl Acetate",
 			IsOnSale:      false,
 		},
 
@@ -1001,6 +1204,8 @@ func SeedTestData() error {
 			Price:         5500.00,
 			ImagePath:     "images/shiseido/softener.jpg",
 			Description:   "Обогащенный тоник для подготовки кожи",
+			Volume:        "200 мл",
+			Ingredients:   "Aqua, Butylene Glycol, Glycerin, Niacinamide, Sodium Hyaluronate, Phenoxyethanol",
 			IsOnSale:      true,
 			SalePrice:     4900.00,
 		},
@@ -1014,6 +1219,8 @@ func SeedTestData() error {
 			Price:         3800.00,
 			ImagePath:     "images/shiseido/blurring-primer.jpg",
 			Description:   "Праймер с эффектом мягкого блюра",
+			Volume:        "30 мл",
+			Ingredients:   "Dimethicone, Dimethicone Crosspolymer, Isododecane, Silica, Tocopherol",
 			IsOnSale:      false,
 		},
 		{
@@ -1024,6 +1231,8 @@ func SeedTestData() error {
 			Price:         3000.00,
 			ImagePath:     "images/shiseido/matte-lipstick.jpg",
 			Description:   "Матовая помада с пудровой текстурой",
+			Volume:        "3,5 г",
+			Ingredients:   "Ricinus Communis Seed Oil, Ozokerite, Octyldodecanol, Cera Alba, Tocopheryl Acetate, CI 15850",
 			IsOnSale:      false,
 		},
 		// Kiko Milano
@@ -1035,6 +1244,8 @@ func SeedTestData() error {
 			Price:         1299.00,
 			ImagePath:     "images/kiko/smart-fusion.jpg",
 			Description:   "Тональная основа с умной адаптацией",
+			Volume:        "30 мл",
+			Ingredients:   "Aqua, Cyclopentasiloxane, Isododecane, Glycerin, Titanium Dioxide, Iron Oxides (CI 77491, CI 77492, CI 77499)",
 			IsOnSale:      true,
 			SalePrice:     1099.00,
 		},
@@ -1046,6 +1257,8 @@ func SeedTestData() error {
 			Price:         899.00,
 			ImagePath:     "images/kiko/blending-blush.jpg",
 			Description:   "Румяна с волнообразным дизайном",
+			Volume:        "5 г",
+			Ingredients:   "Talc, Mica, Dimethicone, Ethylhexyl Palmitate, Iron Oxides (CI 77491), CI 15850",
 			IsOnSale:      false,
 		},
 
@@ -1058,6 +1271,8 @@ func SeedTestData() error {
 			Price:         599.00,
 			ImagePath:     "images/kiko/water-eyeshadow.jpg",
 			Description:   "Тени с эффектом мокрого сияния",
+			Volume:        "2,5 г",
+			Ingredients:   "Talc, Mica, Magnesium Stearate, Dimethicone, Caprylyl Glycol, Titanium Dioxide (CI 77891)",
 			IsOnSale:      false,
 		},
 		{
@@ -1068,6 +1283,8 @@ func SeedTestData() error {
 			Price:         499.00,
 			ImagePath:     "images/kiko/smart-pencil.jpg",
 			Description:   "Универсальный карандаш для глаз",
+			Volume:        "1,2 г",
+			Ingredients:   "Hydrogenated Vegetable Oil, Synthetic Wax, Mica, Tocopheryl Acetate, Iron Oxides (CI 77491, CI 77499)",
 			IsOnSale:      true,
 			SalePrice:     399.00,
 		},
@@ -1081,6 +1298,8 @@ func SeedTestData() error {
 			Price:         799.00,
 			ImagePath:     "images/kiko/velvet-passion.jpg",
 			Description:   "Матовая помада с бархатной текстурой",
+			Volume:        "3,5 г",
+			Ingredients:   "Ricinus Communis Seed Oil, Ozokerite, Octyldodecanol, Cera Alba, Tocopheryl Acetate, CI 15850",
 			IsOnSale:      false,
 		},
 
@@ -1093,6 +1312,8 @@ func SeedTestData() error {
 			Price:         999.00,
 			ImagePath:     "images/kiko/cleansing-oil.jpg",
 			Description:   "Очищающее масло с маслом жожоба",
+			Volume:        "150 мл",
+			Ingredients:   "Caprylic/Capric Triglyceride, Simmondsia Chinensis Seed Oil, PEG-20 Glyceryl Triisostearate, Tocopherol, Parfum",
 			IsOnSale:      false,
 		},
 
@@ -1105,6 +1326,8 @@ func SeedTestData() error {
 			Price:         1499.00,
 			ImagePath:     "images/kiko/hydrating-serum.jpg",
 			Description:   "Увлажняющая сыворотка с гиалуроновой кислотой",
+			Volume:        "30 мл",
+			Ingredients:   "Aqua, Glycerin, Niacinamide, Centella Asiatica Extract, Sodium Hyaluronate, Panthenol",
 			IsOnSale:      true,
 			SalePrice:     1299.00,
 		},
@@ -1118,6 +1341,8 @@ func SeedTestData() error {
 			Price:         799.00,
 			ImagePath:     "images/kiko/rose-toner.jpg",
 			Description:   "Тоник с экстрактом розы",
+			Volume:        "200 мл",
+			Ingredients:   "Aqua, Butylene Glycol, Glycerin, Niacinamide, Sodium Hyaluronate, Phenoxyethanol",
 			IsOnSale:      false,
 		},
 
@@ -1130,6 +1355,8 @@ func SeedTestData() error {
 			Price:         699.00,
 			ImagePath:     "images/kiko/skin-evolution.jpg",
 			Description:   "Консилер с уходовыми свойствами",
+			Volume:        "7 мл",
+			Ingredients:   "Aqua, Dimethicone, Isododecane, Glycerin, Titanium Dioxide, Iron Oxides (CI 77491, CI 77492, CI 77499)",
 			IsOnSale:      false,
 		},
 		{
@@ -1140,6 +1367,8 @@ func SeedTestData() error {
 			Price:         599.00,
 			ImagePath:     "images/kiko/jelly-stylo.jpg",
 			Description:   "Помада-стик с желеобразной текстурой",
+			Volume:        "3,5 г",
+			Ingredients:   "Ricinus Communis Seed Oil, Ozokerite, Octyldodecanol, Cera Alba, Tocopheryl Acetate, CI 15850",
 			IsOnSale:      true,
 			SalePrice:     499.00,
 		},
@@ -1152,6 +1381,8 @@ func SeedTestData() error {
 			Price:         3900.00,
 			ImagePath:     "images/erborian/bb-ginseng.jpg",
 			Description:   "BB-крем с женьшенем для сияния кожи",
+			Volume:        "40 мл",
+			Ingredients:   "Aqua, Cyclopentasiloxane, Titanium Dioxide, Zinc Oxide, Glycerin, Iron Oxides (CI 77491, CI 77492, CI 77499)",
 			IsOnSale:      false,
 		},
 		{
@@ -1162,6 +1393,8 @@ func SeedTestData() error {
 			Price:         4200.00,
 			ImagePath:     "images/erborian/cc-red-correct.jpg",
 			Description:   "CC-крем с коррекцией красноты",
+			Volume:        "40 мл",
+			Ingredients:   "Aqua, Cyclopentasiloxane, Titanium Dioxide, Zinc Oxide, Glycerin, Iron Oxides (CI 77491, CI 77492, CI 77499)",
 			IsOnSale:      true,
 			SalePrice:     3800.00,
 		},
@@ -1175,6 +1408,8 @@ func SeedTestData() error {
 			Price:         2900.00,
 			ImagePath:     "images/erborian/super-brow.jpg",
 			Description:   "Помада для бровей с фиксирующим эффектом",
+			Volume:        "4 г",
+			Ingredients:   "Isododecane, Cera Microcristallina, Dimethicone, Silica, Iron Oxides (CI 77491, CI 77499)",
 			IsOnSale:      false,
 		},
 
@@ -1187,6 +1422,8 @@ func SeedTestData() error {
 			Price:         3200.00,
 			ImagePath:     "images/erborian/liquid-lip.jpg",
 			Description:   "Жидкая помада с комфортной текстурой",
+			Volume:        "7 мл",
+			Ingredients:   "Isododecane, Dimethicone, Trimethylsiloxysilicate, Silica, Tocopheryl Acetate, CI 15850",
 			IsOnSale:      false,
 		},
 		{
@@ -1197,6 +1434,8 @@ func SeedTestData() error {
 			Price:         2500.00,
 			ImagePath:     "images/erborian/lip-tint.jpg",
 			Description:   "Тинт для губ с эффектом сияющих губ",
+			Volume:        "6 мл",
+			Ingredients:   "Polybutene, Hydrogenated Polyisobutene, Simmondsia Chinensis Seed Oil, Tocopherol, Parfum",
 			IsOnSale:      true,
 			SalePrice:     2200.00,
 		},
@@ -1210,6 +1449,8 @@ func SeedTestData() error {
 			Price:         2800.00,
 			ImagePath:     "images/erborian/solid-cleansing.jpg",
 			Description:   "Твердое очищающее масло-трансформер",
+			Volume:        "80 г",
+			Ingredients:   "Caprylic/Capric Triglyceride, Simmondsia Chinensis Seed Oil, PEG-20 Glyceryl Triisostearate, Tocopherol, Parfum",
 			IsOnSale:      false,
 		},
 
@@ -1222,16 +1463,20 @@ func SeedTestData() error {
 			Price:         3500.00,
 			ImagePath:     "images/erborian/yuza-lotion.jpg",
 			Description:   "Двухфазный увлажняющий лосьон с юдзу",
+			Volume:        "200 мл",
+			Ingredients:   "Aqua, Butylene Glycol, Glycerin, Niacinamide, Sodium Hyaluronate, Phenoxyethanol",
 			IsOnSale:      false,
 		},
 		{
 			Name:          "Bamboo Waterlock Mask",
 			Slug:          "erborian-bamboo-mask",
 			BrandID:       11,
-			SubcategoryID: careHydration.ID,
+			SubcategoryID: careHydrationMasks.ID,
 			Price:         2900.00,
 			ImagePath:     "images/erborian/bamboo-mask.jpg",
 			Description:   "Увлажняющая маска с экстрактом бамбука",
+			Volume:        "50 мл",
+			Ingredients:   "Aqua, Glycerin, Butylene Glycol, Kaolin, Sodium Hyaluronate, Allantoin",
 			IsOnSale:      true,
 			SalePrice:     2600.00,
 		},
@@ -1245,6 +1490,8 @@ func SeedTestData() error {
 			Price:         3100.00,
 			ImagePath:     "images/erborian/ginseng-toner.jpg",
 			Description:   "Молочный тоник с экстрактом женьшеня",
+			Volume:        "200 мл",
+			Ingredients:   "Aqua, Butylene Glycol, Glycerin, Niacinamide, Sodium Hyaluronate, Phenoxyethanol",
 			IsOnSale:      false,
 		},
 		{
@@ -1255,11 +1502,33 @@ func SeedTestData() error {
 			Price:         3400.00,
 			ImagePath:     "images/erborian/perfect-glow.jpg",
 			Description:   "Иллюминатор для сияния кожи",
+			Volume:        "6 г",
+			Ingredients:   "Mica, Synthetic Fluorphlogopite, Dimethicone, Tin Oxide, Titanium Dioxide (CI 77891)",
 			IsOnSale:      false,
 		},
 	}
-	for _, product := range products {
-		if err := DB.Create(&product).Error; err != nil {
+	for i := range products {
+		if err := catalog.Products.Create(&products[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SeedPromoCodes добавляет тестовые промокоды
+func SeedPromoCodes(db *gorm.DB) error {
+	promoCodes := []models.PromoCode{
+		{
+			Code:               "WELCOME10",
+			DiscountType:       models.DiscountPercent,
+			DiscountValue:      10,
+			MaxUsesPerCustomer: 1,
+			IsActive:           true,
+		},
+	}
+	for _, promoCode := range promoCodes {
+		if err := db.Create(&promoCode).Error; err != nil {
 			return err
 		}
 	}
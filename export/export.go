@@ -0,0 +1,159 @@
+// Package export выгружает каталог в CSV и JSON Lines для партнеров и аналитики.
+// Продукты читаются из базы пачками и сразу записываются в выходной поток,
+// поэтому размер каталога не влияет на расход памяти
+package export
+
+import (
+	"cosmetics_catalog/models"
+	"cosmetics_catalog/repositories"
+	"cosmetics_catalog/seo"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Форматы выгрузки
+const (
+	FormatCSV   = "csv"
+	FormatJSONL = "jsonl"
+)
+
+// batchSize число продуктов, которые читаются из базы за один запрос
+const batchSize = 500
+
+// Record продукт в выгрузке. Названия колонок CSV совпадают с тегами json,
+// а колонки sku, slug, name, brand, subcategory, price, sale_price и is_on_sale
+// понимает импорт ассортимента, поэтому выгрузку можно загрузить обратно
+type Record struct {
+	ID              uint    `json:"id"`
+	SKU             string  `json:"sku"`
+	Slug            string  `json:"slug"`
+	Name            string  `json:"name"`
+	Brand           string  `json:"brand"`
+	Category        string  `json:"category"`
+	Subcategory     string  `json:"subcategory"`
+	SubcategoryName string  `json:"subcategory_name"`
+	Price           float64 `json:"price"`
+	SalePrice       float64 `json:"sale_price"`
+	IsOnSale        bool    `json:"is_on_sale"`
+	URL             string  `json:"url"`
+	ImageURL        string  `json:"image_url"`
+	Volume          string  `json:"volume"`
+	UpdatedAt       string  `json:"updated_at"`
+}
+
+// columns заголовок CSV в порядке полей Record
+var columns = []string{
+	"id", "sku", "slug", "name", "brand", "category", "subcategory", "subcategory_name",
+	"price", "sale_price", "is_on_sale", "url", "image_url", "volume", "updated_at",
+}
+
+func (r Record) csvRow() []string {
+	return []string{
+		strconv.FormatUint(uint64(r.ID), 10),
+		r.SKU,
+		r.Slug,
+		r.Name,
+		r.Brand,
+		r.Category,
+		r.Subcategory,
+		r.SubcategoryName,
+		formatPrice(r.Price),
+		formatPrice(r.SalePrice),
+		strconv.FormatBool(r.IsOnSale),
+		r.URL,
+		r.ImageURL,
+		r.Volume,
+		r.UpdatedAt,
+	}
+}
+
+// Write выгружает все продукты каталога в формате FormatCSV или FormatJSONL.
+// baseURL нужен для абсолютных адресов страниц и изображений, без завершающего "/"
+func Write(w io.Writer, products repositories.Products, subcategories repositories.Subcategories, format, baseURL string) error {
+	var write func(Record) error
+	var flush func() error
+
+	switch format {
+	case FormatCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(columns); err != nil {
+			return err
+		}
+		write = func(record Record) error { return writer.Write(record.csvRow()) }
+		flush = func() error {
+			writer.Flush()
+			return writer.Error()
+		}
+	case FormatJSONL:
+		encoder := json.NewEncoder(w)
+		encoder.SetEscapeHTML(false)
+		write = func(record Record) error { return encoder.Encode(record) }
+		flush = func() error { return nil }
+	default:
+		return fmt.Errorf("неизвестный формат выгрузки %q, ожидается %s или %s", format, FormatCSV, FormatJSONL)
+	}
+
+	// Подкатегорий немного, их загружаем целиком для путей с предками
+	all, err := subcategories.GetAll()
+	if err != nil {
+		return err
+	}
+	byID := make(map[uint]models.Subcategory, len(all))
+	for _, subcategory := range all {
+		byID[subcategory.ID] = subcategory
+	}
+
+	err = products.Batches(batchSize, func(batch []models.Product) error {
+		for _, product := range batch {
+			if err := write(newRecord(product, byID, baseURL)); err != nil {
+				return err
+			}
+		}
+		// Отдаем пачку клиенту, не дожидаясь конца выгрузки
+		return flush()
+	})
+	if err != nil {
+		return err
+	}
+	return flush()
+}
+
+func newRecord(product models.Product, subcategories map[uint]models.Subcategory, baseURL string) Record {
+	// Название подкатегории с предками, например "Увлажнение > Маски"
+	var names []string
+	for id := &product.SubcategoryID; id != nil; {
+		subcategory, ok := subcategories[*id]
+		if !ok {
+			break
+		}
+		names = append([]string{subcategory.Name}, names...)
+		id = subcategory.ParentID
+	}
+
+	return Record{
+		ID:              product.ID,
+		SKU:             product.SKU,
+		Slug:            product.Slug,
+		Name:            product.Name,
+		Brand:           product.Brand.Name,
+		Category:        product.Subcategory.Category.Name,
+		Subcategory:     product.Subcategory.Category.Slug + "/" + product.Subcategory.Path,
+		SubcategoryName: strings.Join(names, " > "),
+		Price:           product.Price,
+		SalePrice:       product.SalePrice,
+		IsOnSale:        product.IsOnSale,
+		URL:             baseURL + product.URL(),
+		ImageURL:        seo.AbsoluteURL(baseURL, product.ImagePath),
+		Volume:          product.Volume,
+		UpdatedAt:       product.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+func formatPrice(price float64) string {
+	return strconv.FormatFloat(price, 'f', -1, 64)
+}
@@ -0,0 +1,61 @@
+package export
+
+import (
+	"bytes"
+	"cosmetics_catalog/models"
+	"cosmetics_catalog/repositories/memory"
+	"cosmetics_catalog/repositories/repotest"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	catalog := memory.NewCatalog().Repositories()
+	f := repotest.Seed(t, catalog)
+	f.Cream.ImagePath = "/static/krem.jpg"
+	if err := catalog.Products.Update(&f.Cream); err != nil {
+		t.Fatal(err)
+	}
+	// Продуктов больше, чем помещается в одну пачку
+	for i := 0; i < batchSize; i++ {
+		product := models.Product{Name: "Крем", BrandID: f.Alpha.ID, SubcategoryID: f.Creams.ID, Price: 1000.5}
+		if err := catalog.Products.Create(&product); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var out bytes.Buffer
+	if err := Write(&out, catalog.Products, catalog.Subcategories, FormatCSV, "https://example.com"); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != batchSize+4 || strings.Join(rows[0], ",") != strings.Join(columns, ",") {
+		t.Fatalf("строк %d, заголовок %v", len(rows), rows[0])
+	}
+	want := []string{"1", "", f.Cream.Slug, "Крем для лица", "Альфа", "Уход", "uxod/liczo/kremy", "Лицо > Кремы", "1000", "0", "false",
+		"https://example.com/catalog/uxod/liczo/kremy/" + f.Cream.Slug, "https://example.com/static/krem.jpg", ""}
+	if got := rows[1][:len(want)]; strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("строка CSV = %q, want %q", got, want)
+	}
+
+	out.Reset()
+	if err := Write(&out, catalog.Products, catalog.Subcategories, FormatJSONL, "https://example.com"); err != nil {
+		t.Fatal(err)
+	}
+	var record Record
+	if err := json.NewDecoder(&out).Decode(&record); err != nil {
+		t.Fatal(err)
+	}
+	if record.Slug != f.Cream.Slug || record.SubcategoryName != "Лицо > Кремы" {
+		t.Errorf("запись JSON = %+v", record)
+	}
+
+	if err := Write(&out, catalog.Products, catalog.Subcategories, "xml", ""); err == nil {
+		t.Error("Write с неизвестным форматом должен вернуть ошибку")
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bytes"
+	"cosmetics_catalog/feeds"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Фид для Яндекс Маркета
+func (app *App) handleYandexFeed(w http.ResponseWriter, r *http.Request) {
+	app.serveFeed(w, r, "yandex", func(out io.Writer, catalog feeds.Catalog) error {
+		return feeds.WriteYML(out, catalog, time.Now())
+	})
+}
+
+// Фид для Google Merchant Center
+func (app *App) handleGoogleFeed(w http.ResponseWriter, r *http.Request) {
+	app.serveFeed(w, r, "google", feeds.WriteGoogle)
+}
+
+// Лента Atom с продуктами, недавно попавшими в акцию
+func (app *App) handleSalesFeed(w http.ResponseWriter, r *http.Request) {
+	app.serveAtom(w, r, "atom sales", func() (feeds.AtomFeed, error) {
+		products, err := app.products.GetRecentSales(0, feeds.AtomEntries)
+		if err != nil {
+			return feeds.AtomFeed{}, err
+		}
+
+		feed := feeds.AtomFeed{
+			Title:    "Акции — " + feeds.ShopName,
+			Path:     "/catalog/sales/feed",
+			PagePath: "/catalog/sales",
+			Updated:  time.Now(),
+		}
+		for _, product := range products {
+			feed.Entries = append(feed.Entries, feeds.SaleEntry(product))
+		}
+		return feed, nil
+	})
+}
+
+// Лента Atom бренда: новые акции и новинки
+func (app *App) handleBrandFeed(w http.ResponseWriter, r *http.Request) {
+	brandSlug := r.PathValue("brand")
+
+	brand, err := app.brands.GetBySlug(strings.ToLower(brandSlug))
+	if err != nil {
+		// Бренд мог быть переименован
+		if moved, err := app.slugHistory.FindBrand(brandSlug); err == nil {
+			redirectPermanent(w, r, moved.URL()+"/feed")
+			return
+		}
+		http.NotFound(w, r)
+		return
+	}
+	if brandSlug != brand.Slug {
+		redirectPermanent(w, r, brand.URL()+"/feed")
+		return
+	}
+
+	app.serveAtom(w, r, "atom brand "+brand.Slug, func() (feeds.AtomFeed, error) {
+		sales, err := app.products.GetRecentSales(brand.ID, feeds.AtomEntries)
+		if err != nil {
+			return feeds.AtomFeed{}, err
+		}
+		arrivals, err := app.products.GetNewArrivals(brand.ID, feeds.AtomEntries)
+		if err != nil {
+			return feeds.AtomFeed{}, err
+		}
+
+		feed := feeds.AtomFeed{
+			Title:    brand.Name + ": акции и новинки — " + feeds.ShopName,
+			Path:     brand.URL() + "/feed",
+			PagePath: brand.URL(),
+			Updated:  brand.UpdatedAt,
+		}
+		for _, product := range sales {
+			feed.Entries = append(feed.Entries, feeds.SaleEntry(product))
+		}
+		for _, product := range arrivals {
+			feed.Entries = append(feed.Entries, feeds.NewArrivalEntry(product))
+		}
+		return feed, nil
+	})
+}
+
+// serveFeed отдает фид из кэша. Кэш сбрасывается при изменении каталога.
+// Ссылки строятся по адресу сайта из настроек, а не по заголовку Host:
+// иначе каждый новый Host добавлял бы запись в кэш
+func (app *App) serveFeed(w http.ResponseWriter, r *http.Request, name string, write func(io.Writer, feeds.Catalog) error) {
+	baseURL := app.config.SiteURL()
+	data, err := app.feedCache.Get(name, func(out io.Writer) error {
+		catalog, err := app.loadFeedCatalog(baseURL)
+		if err != nil {
+			return err
+		}
+		return write(out, catalog)
+	})
+	if err != nil {
+		http.Error(w, "Ошибка построения фида", http.StatusInternalServerError)
+		return
+	}
+
+	writeCached(w, r, "application/xml; charset=utf-8", data)
+}
+
+// atomFeedURL возвращает адрес ленты Atom для ссылки в заголовке страницы.
+// Если ленты выключены в настройках, ссылка не выводится
+func (app *App) atomFeedURL(path string) string {
+	if !app.config.Features.AtomFeeds {
+		return ""
+	}
+	return app.absoluteURL(path)
+}
+
+// serveAtom отдает ленту Atom из кэша фидов. Ссылки, как и в serveFeed,
+// строятся по адресу сайта из настроек
+func (app *App) serveAtom(w http.ResponseWriter, r *http.Request, name string, load func() (feeds.AtomFeed, error)) {
+	baseURL := app.config.SiteURL()
+	data, err := app.feedCache.Get(name, func(out io.Writer) error {
+		feed, err := load()
+		if err != nil {
+			return err
+		}
+		feed.BaseURL = baseURL
+		return feeds.WriteAtom(out, feed)
+	})
+	if err != nil {
+		http.Error(w, "Ошибка построения ленты", http.StatusInternalServerError)
+		return
+	}
+
+	writeCached(w, r, "application/atom+xml; charset=utf-8", data)
+}
+
+// writeCached отдает содержимое с ETag. Если у клиента та же версия,
+// http.ServeContent ответит 304 без тела
+func writeCached(w http.ResponseWriter, r *http.Request, contentType string, data []byte) {
+	sum := sha256.Sum256(data)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+}
+
+// loadFeedCatalog загружает категории, подкатегории и продукты для фидов
+func (app *App) loadFeedCatalog(baseURL string) (feeds.Catalog, error) {
+	catalog := feeds.Catalog{BaseURL: baseURL}
+	var err error
+	if catalog.Categories, err = app.categories.GetAll(); err != nil {
+		return catalog, err
+	}
+	if catalog.Subcategories, err = app.subcategories.GetAll(); err != nil {
+		return catalog, err
+	}
+	catalog.Products, err = app.products.GetAll()
+	return catalog, err
+}
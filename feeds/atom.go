@@ -0,0 +1,178 @@
+package feeds
+
+import (
+	"cosmetics_catalog/models"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"time"
+)
+
+// AtomEntries наибольшее число записей в ленте Atom
+const AtomEntries = 50
+
+// atomNamespace пространство имен формата Atom
+const atomNamespace = "http://www.w3.org/2005/Atom"
+
+// Виды событий, по которым продукт попадает в ленту
+const (
+	EntrySale = iota
+	EntryNewArrival
+)
+
+// AtomFeed данные ленты Atom
+type AtomFeed struct {
+	// BaseURL адрес сайта без завершающего "/"
+	BaseURL string
+	// Title заголовок ленты
+	Title string
+	// Path путь к ленте, например "/catalog/sales/feed"
+	Path string
+	// PagePath путь к странице сайта, которую описывает лента
+	PagePath string
+	// Updated время обновления пустой ленты. Если записи есть,
+	// лента считается обновленной вместе с самой новой из них
+	Updated time.Time
+	// Entries записи ленты в любом порядке
+	Entries []AtomEntry
+}
+
+// AtomEntry запись ленты: продукт и событие, по которому он в нее попал
+type AtomEntry struct {
+	Kind    int
+	Product models.Product
+	Updated time.Time
+}
+
+// SaleEntry создает запись о продукте, попавшем в акцию. Для продуктов
+// без даты начала акции используется дата создания
+func SaleEntry(product models.Product) AtomEntry {
+	updated := product.CreatedAt
+	if product.SaleStartedAt != nil {
+		updated = *product.SaleStartedAt
+	}
+	return AtomEntry{Kind: EntrySale, Product: product, Updated: updated}
+}
+
+// NewArrivalEntry создает запись о новом продукте
+func NewArrivalEntry(product models.Product) AtomEntry {
+	return AtomEntry{Kind: EntryNewArrival, Product: product, Updated: product.CreatedAt}
+}
+
+type atomXML struct {
+	XMLName xml.Name       `xml:"feed"`
+	Xmlns   string         `xml:"xmlns,attr"`
+	ID      string         `xml:"id"`
+	Title   string         `xml:"title"`
+	Updated string         `xml:"updated"`
+	Author  atomAuthor     `xml:"author"`
+	Links   []atomLink     `xml:"link"`
+	Entries []atomEntryXML `xml:"entry"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomEntryXML struct {
+	ID        string     `xml:"id"`
+	Title     string     `xml:"title"`
+	Updated   string     `xml:"updated"`
+	Published string     `xml:"published"`
+	Links     []atomLink `xml:"link"`
+	Category  *atomTerm  `xml:"category,omitempty"`
+	Summary   string     `xml:"summary,omitempty"`
+}
+
+type atomTerm struct {
+	Term string `xml:"term,attr"`
+}
+
+// WriteAtom записывает ленту в формате Atom. Записи выводятся от новых
+// к старым, в ленту попадают не больше AtomEntries записей
+func WriteAtom(w io.Writer, feed AtomFeed) error {
+	entries := append([]AtomEntry(nil), feed.Entries...)
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Updated.After(entries[j].Updated)
+	})
+	if len(entries) > AtomEntries {
+		entries = entries[:AtomEntries]
+	}
+
+	updated := feed.Updated
+	if len(entries) > 0 {
+		updated = entries[0].Updated
+	}
+
+	self := feed.BaseURL + feed.Path
+	doc := atomXML{
+		Xmlns:   atomNamespace,
+		ID:      self,
+		Title:   feed.Title,
+		Updated: atomTime(updated),
+		Author:  atomAuthor{Name: ShopName},
+		Links: []atomLink{
+			{Rel: "self", Type: "application/atom+xml", Href: self},
+			{Rel: "alternate", Type: "text/html", Href: feed.BaseURL + feed.PagePath},
+		},
+	}
+
+	host := feedHost(feed.BaseURL)
+	for _, entry := range entries {
+		product := entry.Product
+		item := atomEntryXML{
+			Updated:   atomTime(entry.Updated),
+			Published: atomTime(entry.Updated),
+			Links:     []atomLink{{Rel: "alternate", Type: "text/html", Href: feed.BaseURL + product.URL()}},
+			Summary:   product.Description,
+		}
+		if product.Subcategory.Category.Name != "" {
+			item.Category = &atomTerm{Term: product.Subcategory.Category.Name}
+		}
+
+		// Идентификатор записи привязан к событию, поэтому повторное
+		// попадание продукта в акцию дает новую запись
+		date := entry.Updated.UTC().Format("2006-01-02")
+		switch entry.Kind {
+		case EntrySale:
+			price, oldPrice := productPrices(product)
+			item.ID = fmt.Sprintf("tag:%s,%s:products/%d/sale/%d", host, date, product.ID, entry.Updated.Unix())
+			item.Title = fmt.Sprintf("Скидка: %s — %s ₽", product.Name, formatPrice(price))
+			if oldPrice > 0 {
+				item.Title += fmt.Sprintf(" вместо %s ₽", formatPrice(oldPrice))
+			}
+		default:
+			item.ID = fmt.Sprintf("tag:%s,%s:products/%d", host, date, product.ID)
+			item.Title = "Новинка: " + product.Name
+		}
+		if product.Brand.Name != "" {
+			item.Title += " (" + product.Brand.Name + ")"
+		}
+
+		doc.Entries = append(doc.Entries, item)
+	}
+
+	return writeXML(w, doc)
+}
+
+// atomTime выводит время в формате RFC 3339, как того требует Atom
+func atomTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// feedHost возвращает имя хоста для идентификаторов записей вида tag:
+func feedHost(baseURL string) string {
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Hostname() == "" {
+		return "localhost"
+	}
+	return u.Hostname()
+}
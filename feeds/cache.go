@@ -0,0 +1,79 @@
+package feeds
+
+import (
+	"bytes"
+	"cosmetics_catalog/database/aftercommit"
+	"fmt"
+	"io"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// Cache хранит сгенерированные фиды, пока каталог не изменится
+type Cache struct {
+	mu      sync.Mutex
+	entries map[string][]byte
+}
+
+// NewCache создает пустой кэш фидов
+func NewCache() *Cache {
+	return &Cache{entries: map[string][]byte{}}
+}
+
+// Get возвращает фид по ключу. Если фида нет в кэше, он строится функцией build
+func (c *Cache) Get(key string, build func(w io.Writer) error) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if data, ok := c.entries[key]; ok {
+		return data, nil
+	}
+
+	var buf bytes.Buffer
+	if err := build(&buf); err != nil {
+		return nil, err
+	}
+	c.entries[key] = buf.Bytes()
+	return c.entries[key], nil
+}
+
+// Invalidate сбрасывает все фиды
+func (c *Cache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = map[string][]byte{}
+}
+
+// Watch регистрирует колбэки GORM, которые сбрасывают кэш после создания,
+// изменения и удаления записей в таблицах переданных моделей.
+// Кэш сбрасывается после фиксации транзакции (см. пакет aftercommit):
+// иначе запрос фида, пришедший до фиксации явной транзакции, построил бы
+// фид по старым данным и закэшировал его до следующего изменения.
+// Имя колбэков уникально для кэша, поэтому несколько кэшей
+// на одном подключении не заменяют колбэки друг друга
+func (c *Cache) Watch(db *gorm.DB, models ...any) error {
+	tables := map[string]bool{}
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return err
+		}
+		tables[stmt.Schema.Table] = true
+	}
+
+	invalidate := func(tx *gorm.DB) {
+		if tx.Error == nil && tables[tx.Statement.Table] {
+			aftercommit.Do(tx, c.Invalidate)
+		}
+	}
+
+	name := fmt.Sprintf("feeds:invalidate:%p", c)
+	if err := db.Callback().Create().Before("gorm:commit_or_rollback_transaction").Register(name, invalidate); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().Before("gorm:commit_or_rollback_transaction").Register(name, invalidate); err != nil {
+		return err
+	}
+	return db.Callback().Delete().Before("gorm:commit_or_rollback_transaction").Register(name, invalidate)
+}
@@ -0,0 +1,58 @@
+package feeds
+
+import (
+	"cosmetics_catalog/database"
+	"cosmetics_catalog/database/dbtest"
+	"cosmetics_catalog/models"
+	"io"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestCacheInvalidatedAfterCommit(t *testing.T) {
+	db := dbtest.OpenMigrated(t, database.DriverSQLite)
+	cache := NewCache()
+	if err := cache.Watch(db, &models.Brand{}); err != nil {
+		t.Fatal(err)
+	}
+
+	builds := 0
+	get := func() {
+		t.Helper()
+		_, err := cache.Get("brands", func(io.Writer) error {
+			builds++
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	get()
+	if err := db.Create(&models.Brand{Name: "Альфа"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	get()
+	if builds != 2 {
+		t.Fatalf("после создания бренда фид построен %d раз, want 2", builds)
+	}
+
+	// Фид, запрошенный до фиксации транзакции, не остается в кэше после нее
+	var beforeCommit int
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&models.Brand{Name: "Бета"}).Error; err != nil {
+			return err
+		}
+		get()
+		beforeCommit = builds
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	get()
+	if builds != beforeCommit+1 {
+		t.Errorf("после фиксации транзакции фид не перестроен: построений %d, до фиксации %d", builds, beforeCommit)
+	}
+}
@@ -0,0 +1,78 @@
+// Package feeds формирует товарные фиды для маркетплейсов и рекламных площадок:
+// YML для Яндекс Маркета и XML для Google Merchant Center, а также ленты Atom
+// с новыми акциями и новинками для подписчиков
+package feeds
+
+import (
+	"cosmetics_catalog/models"
+	"cosmetics_catalog/seo"
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+// ShopName название магазина в фидах
+const ShopName = seo.SiteName
+
+// subcategoryIDOffset сдвиг идентификаторов подкатегорий в дереве категорий фида,
+// чтобы они не пересекались с идентификаторами категорий
+const subcategoryIDOffset = 100000
+
+// Catalog данные каталога для построения фида
+type Catalog struct {
+	// BaseURL адрес сайта без завершающего "/", например "https://example.com"
+	BaseURL string
+	// Categories категории каталога
+	Categories []models.Category
+	// Subcategories подкатегории всех уровней
+	Subcategories []models.Subcategory
+	// Products продукты с загруженными брендом, подкатегорией и ее категорией
+	Products []models.Product
+}
+
+// productPrices возвращает цену продажи и прежнюю цену продукта.
+// Прежняя цена нулевая, если продукт продается без скидки
+func productPrices(product models.Product) (price, oldPrice float64) {
+	if product.IsOnSale && product.SalePrice > 0 && product.SalePrice < product.Price {
+		return product.SalePrice, product.Price
+	}
+	return product.Price, 0
+}
+
+// categoryPath возвращает названия категории и подкатегорий от корня
+// до подкатегории продукта, например "Уход > Увлажнение > Маски"
+func categoryPath(subcategories map[uint]models.Subcategory, product models.Product) string {
+	var names []string
+	for id := &product.SubcategoryID; id != nil; {
+		subcategory, ok := subcategories[*id]
+		if !ok {
+			break
+		}
+		names = append([]string{subcategory.Name}, names...)
+		id = subcategory.ParentID
+	}
+	names = append([]string{product.Subcategory.Category.Name}, names...)
+	return strings.Join(names, " > ")
+}
+
+// subcategoriesByID индексирует подкатегории по ID
+func subcategoriesByID(subcategories []models.Subcategory) map[uint]models.Subcategory {
+	byID := make(map[uint]models.Subcategory, len(subcategories))
+	for _, subcategory := range subcategories {
+		byID[subcategory.ID] = subcategory
+	}
+	return byID
+}
+
+func writeXML(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,79 @@
+package feeds
+
+import (
+	"cosmetics_catalog/seo"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// googleNamespace пространство имен атрибутов Google Merchant Center
+const googleNamespace = "http://base.google.com/ns/1.0"
+
+type googleRSS struct {
+	XMLName xml.Name      `xml:"rss"`
+	Version string        `xml:"version,attr"`
+	XmlnsG  string        `xml:"xmlns:g,attr"`
+	Channel googleChannel `xml:"channel"`
+}
+
+type googleChannel struct {
+	Title       string       `xml:"title"`
+	Link        string       `xml:"link"`
+	Description string       `xml:"description"`
+	Items       []googleItem `xml:"item"`
+}
+
+type googleItem struct {
+	ID           string `xml:"g:id"`
+	Title        string `xml:"g:title"`
+	Description  string `xml:"g:description,omitempty"`
+	Link         string `xml:"g:link"`
+	ImageLink    string `xml:"g:image_link,omitempty"`
+	Availability string `xml:"g:availability"`
+	Price        string `xml:"g:price"`
+	SalePrice    string `xml:"g:sale_price,omitempty"`
+	Brand        string `xml:"g:brand,omitempty"`
+	Condition    string `xml:"g:condition"`
+	ProductType  string `xml:"g:product_type"`
+}
+
+// WriteGoogle записывает фид в формате RSS 2.0 для Google Merchant Center
+func WriteGoogle(w io.Writer, catalog Catalog) error {
+	subcategories := subcategoriesByID(catalog.Subcategories)
+
+	channel := googleChannel{
+		Title:       ShopName,
+		Link:        catalog.BaseURL + "/catalog/",
+		Description: "Товары каталога косметики",
+	}
+	for _, product := range catalog.Products {
+		price, oldPrice := productPrices(product)
+		item := googleItem{
+			ID:           strconv.FormatUint(uint64(product.ID), 10),
+			Title:        product.Name,
+			Description:  product.Description,
+			Link:         catalog.BaseURL + product.URL(),
+			ImageLink:    seo.AbsoluteURL(catalog.BaseURL, product.ImagePath),
+			Availability: "in_stock",
+			Price:        googlePrice(price),
+			Brand:        product.Brand.Name,
+			Condition:    "new",
+			ProductType:  categoryPath(subcategories, product),
+		}
+		// Google ожидает обычную цену в price, а цену со скидкой в sale_price
+		if oldPrice > 0 {
+			item.Price = googlePrice(oldPrice)
+			item.SalePrice = googlePrice(price)
+		}
+		channel.Items = append(channel.Items, item)
+	}
+
+	return writeXML(w, googleRSS{Version: "2.0", XmlnsG: googleNamespace, Channel: channel})
+}
+
+// googlePrice выводит цену с валютой, например "1290.00 RUB"
+func googlePrice(price float64) string {
+	return fmt.Sprintf("%.2f %s", price, seo.Currency)
+}
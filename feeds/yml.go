@@ -0,0 +1,115 @@
+package feeds
+
+import (
+	"cosmetics_catalog/seo"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"time"
+)
+
+// ymlCurrency код рубля в формате YML
+const ymlCurrency = "RUR"
+
+type ymlCatalog struct {
+	XMLName xml.Name `xml:"yml_catalog"`
+	Date    string   `xml:"date,attr"`
+	Shop    ymlShop  `xml:"shop"`
+}
+
+type ymlShop struct {
+	Name       string            `xml:"name"`
+	Company    string            `xml:"company"`
+	URL        string            `xml:"url"`
+	Currencies []ymlCurrencyRate `xml:"currencies>currency"`
+	Categories []ymlCategory     `xml:"categories>category"`
+	Offers     []ymlOffer        `xml:"offers>offer"`
+}
+
+type ymlCurrencyRate struct {
+	ID   string `xml:"id,attr"`
+	Rate string `xml:"rate,attr"`
+}
+
+type ymlCategory struct {
+	ID       string `xml:"id,attr"`
+	ParentID string `xml:"parentId,attr,omitempty"`
+	Name     string `xml:",chardata"`
+}
+
+type ymlOffer struct {
+	ID          string `xml:"id,attr"`
+	Available   bool   `xml:"available,attr"`
+	URL         string `xml:"url"`
+	Price       string `xml:"price"`
+	OldPrice    string `xml:"oldprice,omitempty"`
+	CurrencyID  string `xml:"currencyId"`
+	CategoryID  string `xml:"categoryId"`
+	Picture     string `xml:"picture,omitempty"`
+	Vendor      string `xml:"vendor,omitempty"`
+	Name        string `xml:"name"`
+	Description string `xml:"description,omitempty"`
+}
+
+// WriteYML записывает фид в формате YML для Яндекс Маркета.
+// Категории и подкатегории выводятся одним деревом: идентификаторы
+// подкатегорий сдвинуты на subcategoryIDOffset
+func WriteYML(w io.Writer, catalog Catalog, now time.Time) error {
+	shop := ymlShop{
+		Name:       ShopName,
+		Company:    ShopName,
+		URL:        catalog.BaseURL + "/catalog/",
+		Currencies: []ymlCurrencyRate{{ID: ymlCurrency, Rate: "1"}},
+	}
+
+	for _, category := range catalog.Categories {
+		shop.Categories = append(shop.Categories, ymlCategory{
+			ID:   strconv.FormatUint(uint64(category.ID), 10),
+			Name: category.Name,
+		})
+	}
+	for _, subcategory := range catalog.Subcategories {
+		parentID := strconv.FormatUint(uint64(subcategory.CategoryID), 10)
+		if subcategory.ParentID != nil {
+			parentID = subcategoryCategoryID(*subcategory.ParentID)
+		}
+		shop.Categories = append(shop.Categories, ymlCategory{
+			ID:       subcategoryCategoryID(subcategory.ID),
+			ParentID: parentID,
+			Name:     subcategory.Name,
+		})
+	}
+
+	for _, product := range catalog.Products {
+		price, oldPrice := productPrices(product)
+		offer := ymlOffer{
+			ID: strconv.FormatUint(uint64(product.ID), 10),
+			// Остатки в каталоге не ведутся, поэтому товар всегда в наличии
+			Available:   true,
+			URL:         catalog.BaseURL + product.URL(),
+			Price:       formatPrice(price),
+			CurrencyID:  ymlCurrency,
+			CategoryID:  subcategoryCategoryID(product.SubcategoryID),
+			Picture:     seo.AbsoluteURL(catalog.BaseURL, product.ImagePath),
+			Vendor:      product.Brand.Name,
+			Name:        product.Name,
+			Description: product.Description,
+		}
+		if oldPrice > 0 {
+			offer.OldPrice = formatPrice(oldPrice)
+		}
+		shop.Offers = append(shop.Offers, offer)
+	}
+
+	return writeXML(w, ymlCatalog{Date: now.Format(time.RFC3339), Shop: shop})
+}
+
+// subcategoryCategoryID возвращает идентификатор подкатегории в дереве категорий фида
+func subcategoryCategoryID(id uint) string {
+	return strconv.FormatUint(uint64(id)+subcategoryIDOffset, 10)
+}
+
+// formatPrice выводит цену с точкой и без лишних нулей
+func formatPrice(price float64) string {
+	return strconv.FormatFloat(price, 'f', -1, 64)
+}
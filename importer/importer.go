@@ -0,0 +1,461 @@
+// Package importer загружает ассортимент из таблиц CSV и XLSX.
+//
+// Каждая строка таблицы описывает продукт и сопоставляется с каталогом
+// по артикулу, а если его нет — по слагу. Найденный продукт обновляется,
+// иначе создается новый. Пустые ячейки не меняют значения полей,
+// поэтому в таблице достаточно оставить колонки, которые нужно обновить
+package importer
+
+import (
+	"cosmetics_catalog/models"
+	"cosmetics_catalog/slug"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInvalidRows возвращается, если в таблице есть строки с ошибками.
+// В этом случае изменения не сохраняются
+var ErrInvalidRows = errors.New("в таблице есть строки с ошибками, изменения не сохранены")
+
+// Result итоги импорта
+type Result struct {
+	Created   int
+	Updated   int
+	Unchanged int
+	Invalid   int
+}
+
+// Importer сопоставляет строки таблицы с каталогом и сохраняет изменения
+type Importer struct {
+	db            *gorm.DB
+	brands        []models.Brand
+	subcategories []models.Subcategory
+}
+
+// change изменение продукта по одной строке таблицы
+type change struct {
+	row     Row
+	product models.Product
+	create  bool
+	diff    []string
+	errs    []string
+}
+
+// New создает импортер для базы данных. Дату начала акции продуктов
+// запоминает хук модели, он же вызывает обработчик из models.WithSaleHook,
+// если тот передан в контексте db
+func New(db *gorm.DB) *Importer {
+	return &Importer{db: db}
+}
+
+// Import проверяет строки, выводит в out изменения и ошибки по каждой строке
+// и сохраняет изменения одной транзакцией. В режиме dryRun база не меняется
+func (im *Importer) Import(rows []Row, dryRun bool, out io.Writer) (Result, error) {
+	var result Result
+	if err := im.db.Order("id").Find(&im.brands).Error; err != nil {
+		return result, err
+	}
+	if err := im.db.Preload("Category").Order("category_id, path").Find(&im.subcategories).Error; err != nil {
+		return result, err
+	}
+
+	changes := make([]*change, 0, len(rows))
+	keys := map[string]int{}
+	for _, row := range rows {
+		c, err := im.plan(row)
+		if err != nil {
+			return result, err
+		}
+
+		// Одна и та же позиция не должна встречаться в таблице дважды
+		for _, key := range []string{"sku:" + c.product.SKU, "slug:" + c.product.Slug} {
+			if strings.HasSuffix(key, ":") {
+				continue
+			}
+			if line, ok := keys[key]; ok {
+				c.errs = append(c.errs, fmt.Sprintf("позиция уже встречалась в строке %d", line))
+				break
+			}
+			keys[key] = row.Line
+		}
+
+		changes = append(changes, c)
+		report(out, c)
+
+		switch {
+		case len(c.errs) > 0:
+			result.Invalid++
+		case c.create:
+			result.Created++
+		case len(c.diff) > 0:
+			result.Updated++
+		default:
+			result.Unchanged++
+		}
+	}
+
+	if result.Invalid > 0 {
+		return result, ErrInvalidRows
+	}
+	if dryRun {
+		return result, nil
+	}
+
+	err := im.db.Transaction(func(tx *gorm.DB) error {
+		for _, c := range changes {
+			var err error
+			switch {
+			case c.create:
+				err = tx.Omit(clause.Associations).Create(&c.product).Error
+			case len(c.diff) > 0:
+				err = tx.Omit(clause.Associations).Save(&c.product).Error
+			}
+			if err != nil {
+				return fmt.Errorf("строка %d: %w", c.row.Line, err)
+			}
+		}
+		return nil
+	})
+	return result, err
+}
+
+// plan находит продукт для строки и применяет к нему значения из таблицы.
+// Ошибки данных накапливаются в change, ошибка возвращается только при сбое базы
+func (im *Importer) plan(row Row) (*change, error) {
+	c := &change{row: row}
+	values := row.Values
+
+	sku := values[ColumnSKU]
+	productSlug := strings.ToLower(values[ColumnSlug])
+	if sku == "" && productSlug == "" {
+		c.errs = append(c.errs, "не указаны артикул и слаг")
+		return c, nil
+	}
+	if productSlug != "" && slug.Make(productSlug) != productSlug {
+		c.errs = append(c.errs, fmt.Sprintf("некорректный слаг «%s», например подойдет «%s»", values[ColumnSlug], slug.Make(productSlug)))
+	}
+
+	current, err := im.find(c, sku, productSlug)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		c.create = true
+	} else {
+		c.product = *current
+	}
+	old := c.product
+	product := &c.product
+
+	if sku != "" {
+		product.SKU = sku
+	}
+	if productSlug != "" {
+		product.Slug = productSlug
+	}
+	if row.Has(ColumnName) {
+		product.Name = values[ColumnName]
+	}
+	if row.Has(ColumnBrand) {
+		if brand, ok := im.brand(values[ColumnBrand]); ok {
+			product.BrandID = brand.ID
+		} else {
+			c.errs = append(c.errs, fmt.Sprintf("неизвестный бренд «%s»", values[ColumnBrand]))
+		}
+	}
+	if row.Has(ColumnSubcategory) {
+		subcategory, errText := im.subcategory(values[ColumnSubcategory])
+		if errText != "" {
+			c.errs = append(c.errs, errText)
+		} else {
+			product.SubcategoryID = subcategory.ID
+		}
+	}
+	if row.Has(ColumnPrice) {
+		if price, ok := parsePrice(values[ColumnPrice]); ok {
+			product.Price = price
+		} else {
+			c.errs = append(c.errs, fmt.Sprintf("некорректная цена «%s»", values[ColumnPrice]))
+		}
+	}
+	if row.Has(ColumnSalePrice) {
+		if price, ok := parsePrice(values[ColumnSalePrice]); ok {
+			product.SalePrice = price
+		} else {
+			c.errs = append(c.errs, fmt.Sprintf("некорректная цена со скидкой «%s»", values[ColumnSalePrice]))
+		}
+	}
+	if row.Has(ColumnIsOnSale) {
+		if onSale, ok := parseBool(values[ColumnIsOnSale]); ok {
+			product.IsOnSale = onSale
+		} else {
+			c.errs = append(c.errs, fmt.Sprintf("в колонке акции ожидается «да» или «нет», указано «%s»", values[ColumnIsOnSale]))
+		}
+	}
+	if row.Has(ColumnImage) {
+		product.ImagePath = values[ColumnImage]
+	}
+	if row.Has(ColumnDescription) {
+		product.Description = values[ColumnDescription]
+	}
+	if row.Has(ColumnVolume) {
+		product.Volume = values[ColumnVolume]
+	}
+	if row.Has(ColumnIngredients) {
+		product.Ingredients = values[ColumnIngredients]
+	}
+
+	// Для нового продукта обязательные колонки должны быть заполнены,
+	// остальные проверки имеют смысл, только если значения в ячейках корректны
+	if c.create {
+		for _, required := range []struct{ column, title string }{
+			{ColumnName, "название"},
+			{ColumnBrand, "бренд"},
+			{ColumnSubcategory, "подкатегория"},
+			{ColumnPrice, "цена"},
+		} {
+			if !row.Has(required.column) {
+				c.errs = append(c.errs, fmt.Sprintf("для нового продукта нужна колонка «%s»", required.title))
+			}
+		}
+	}
+	if len(c.errs) == 0 {
+		c.errs = validate(*product)
+	}
+
+	c.diff = im.diff(old, *product, c.create)
+	return c, nil
+}
+
+// find ищет продукт по артикулу, а если его нет — по слагу.
+// Возвращает nil, если продукт нужно создать
+func (im *Importer) find(c *change, sku, productSlug string) (*models.Product, error) {
+	var product models.Product
+	if sku != "" {
+		found := im.db.Where("sku = ?", sku).Limit(1).Find(&product)
+		if found.Error != nil {
+			return nil, found.Error
+		}
+		if found.RowsAffected > 0 {
+			if productSlug != "" && productSlug != product.Slug {
+				if taken, err := im.slugTaken(productSlug, product.ID); err != nil {
+					return nil, err
+				} else if taken {
+					c.errs = append(c.errs, fmt.Sprintf("слаг «%s» занят другим продуктом", productSlug))
+				}
+			}
+			return &product, nil
+		}
+	}
+	if productSlug == "" {
+		return nil, nil
+	}
+
+	// Ищем и среди удаленных продуктов: их слаги тоже заняты
+	found := im.db.Unscoped().Where("slug = ?", productSlug).Limit(1).Find(&product)
+	if found.Error != nil || found.RowsAffected == 0 {
+		return nil, found.Error
+	}
+	if product.DeletedAt.Valid {
+		c.errs = append(c.errs, fmt.Sprintf("слаг «%s» занят удаленным продуктом", productSlug))
+		return nil, nil
+	}
+	// Продукт с этим слагом уже привязан к другому артикулу
+	if sku != "" && product.SKU != "" && product.SKU != sku {
+		c.errs = append(c.errs, fmt.Sprintf("слаг «%s» занят продуктом с артикулом %s", productSlug, product.SKU))
+	}
+	return &product, nil
+}
+
+// slugTaken сообщает, принадлежит ли слаг другому продукту, в том числе удаленному
+func (im *Importer) slugTaken(productSlug string, id uint) (bool, error) {
+	var count int64
+	err := im.db.Unscoped().Model(&models.Product{}).Where("slug = ? AND id <> ?", productSlug, id).Count(&count).Error
+	return count > 0, err
+}
+
+// brand находит бренд по слагу или названию без учета регистра
+func (im *Importer) brand(value string) (models.Brand, bool) {
+	for _, brand := range im.brands {
+		if strings.EqualFold(brand.Slug, value) || strings.EqualFold(brand.Name, value) {
+			return brand, true
+		}
+	}
+	return models.Brand{}, false
+}
+
+// subcategory находит подкатегорию по полному пути с категорией
+// ("uxod/uvlazhnenie/maski"), по пути внутри категории ("uvlazhnenie/maski")
+// или по названию ("Маски"). Если под значение подходят несколько
+// подкатегорий, возвращается текст ошибки с вариантами
+func (im *Importer) subcategory(value string) (models.Subcategory, string) {
+	key := strings.Trim(strings.ToLower(value), "/")
+	matchers := []func(models.Subcategory) bool{
+		func(s models.Subcategory) bool { return s.Category.Slug+"/"+s.Path == key },
+		func(s models.Subcategory) bool { return s.Path == key },
+		func(s models.Subcategory) bool { return strings.EqualFold(s.Name, value) },
+	}
+
+	for _, matches := range matchers {
+		var found []models.Subcategory
+		for _, subcategory := range im.subcategories {
+			if matches(subcategory) {
+				found = append(found, subcategory)
+			}
+		}
+		switch {
+		case len(found) == 1:
+			return found[0], ""
+		case len(found) > 1:
+			var paths []string
+			for _, subcategory := range found {
+				paths = append(paths, subcategory.Category.Slug+"/"+subcategory.Path)
+			}
+			return models.Subcategory{}, fmt.Sprintf("подкатегория «%s» неоднозначна, укажите путь: %s", value, strings.Join(paths, ", "))
+		}
+	}
+	return models.Subcategory{}, fmt.Sprintf("неизвестная подкатегория «%s»", value)
+}
+
+// validate проверяет продукт после применения значений из таблицы
+func validate(product models.Product) []string {
+	var errs []string
+	if utf8.RuneCountInString(product.Name) > 255 {
+		errs = append(errs, "название длиннее 255 символов")
+	}
+	if product.Price <= 0 {
+		errs = append(errs, "цена должна быть больше нуля")
+	}
+	if product.IsOnSale && (product.SalePrice <= 0 || product.SalePrice >= product.Price) {
+		errs = append(errs, "цена со скидкой должна быть больше нуля и меньше обычной цены")
+	}
+	return errs
+}
+
+// diff описывает изменения полей продукта для отчета
+func (im *Importer) diff(old, updated models.Product, create bool) []string {
+	if create {
+		old = models.Product{}
+	}
+
+	var lines []string
+	field := func(name, before, after string) {
+		if before != after {
+			lines = append(lines, fmt.Sprintf("%s: %s → %s", name, quote(before), quote(after)))
+		}
+	}
+
+	field("артикул", old.SKU, updated.SKU)
+	field("слаг", old.Slug, updated.Slug)
+	field("название", old.Name, updated.Name)
+	field("бренд", im.brandName(old.BrandID), im.brandName(updated.BrandID))
+	field("подкатегория", im.subcategoryPath(old.SubcategoryID), im.subcategoryPath(updated.SubcategoryID))
+	field("цена", formatPrice(old.Price), formatPrice(updated.Price))
+	field("цена со скидкой", formatPrice(old.SalePrice), formatPrice(updated.SalePrice))
+	field("акция", formatBool(old.IsOnSale), formatBool(updated.IsOnSale))
+	field("изображение", old.ImagePath, updated.ImagePath)
+	field("описание", old.Description, updated.Description)
+	field("объем", old.Volume, updated.Volume)
+	field("состав", old.Ingredients, updated.Ingredients)
+	return lines
+}
+
+func (im *Importer) brandName(id uint) string {
+	for _, brand := range im.brands {
+		if brand.ID == id {
+			return brand.Name
+		}
+	}
+	return ""
+}
+
+func (im *Importer) subcategoryPath(id uint) string {
+	for _, subcategory := range im.subcategories {
+		if subcategory.ID == id {
+			return subcategory.Category.Slug + "/" + subcategory.Path
+		}
+	}
+	return ""
+}
+
+// report выводит результат разбора строки
+func report(out io.Writer, c *change) {
+	name := c.product.SKU
+	if name == "" {
+		name = c.product.Slug
+	}
+
+	switch {
+	case len(c.errs) > 0:
+		fmt.Fprintf(out, "строка %d: %s: ошибка\n", c.row.Line, name)
+		for _, err := range c.errs {
+			fmt.Fprintf(out, "    %s\n", err)
+		}
+		return
+	case c.create:
+		fmt.Fprintf(out, "строка %d: %s: новый продукт\n", c.row.Line, name)
+	case len(c.diff) > 0:
+		fmt.Fprintf(out, "строка %d: %s: изменения\n", c.row.Line, name)
+	default:
+		fmt.Fprintf(out, "строка %d: %s: без изменений\n", c.row.Line, name)
+	}
+	for _, line := range c.diff {
+		fmt.Fprintf(out, "    %s\n", line)
+	}
+}
+
+// parsePrice разбирает цену. Допускаются пробелы между разрядами,
+// десятичная запятая и знак рубля
+func parsePrice(value string) (float64, bool) {
+	value = strings.TrimSuffix(strings.TrimSpace(value), "₽")
+	value = strings.NewReplacer(" ", "", "\u00a0", "", "\u202f", "", ",", ".").Replace(value)
+	price, err := strconv.ParseFloat(value, 64)
+	if err != nil || price < 0 {
+		return 0, false
+	}
+	return price, true
+}
+
+// parseBool разбирает признак акции
+func parseBool(value string) (bool, bool) {
+	switch strings.ToLower(value) {
+	case "да", "yes", "true", "1", "+":
+		return true, true
+	case "нет", "no", "false", "0", "-":
+		return false, true
+	}
+	return false, false
+}
+
+func formatBool(value bool) string {
+	if value {
+		return "да"
+	}
+	return "нет"
+}
+
+// formatPrice выводит цену для отчета, нулевая цена считается незаданной
+func formatPrice(price float64) string {
+	if price == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(price, 'f', -1, 64)
+}
+
+// quote выводит значение для отчета. Длинные тексты сокращаются
+func quote(value string) string {
+	if value == "" {
+		return "—"
+	}
+	if runes := []rune(value); len(runes) > 60 {
+		value = string(runes[:59]) + "…"
+	}
+	return "«" + value + "»"
+}
@@ -0,0 +1,184 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Колонки таблицы ассортимента
+const (
+	ColumnSKU         = "sku"
+	ColumnSlug        = "slug"
+	ColumnName        = "name"
+	ColumnBrand       = "brand"
+	ColumnSubcategory = "subcategory"
+	ColumnPrice       = "price"
+	ColumnSalePrice   = "sale_price"
+	ColumnIsOnSale    = "is_on_sale"
+	ColumnImage       = "image"
+	ColumnDescription = "description"
+	ColumnVolume      = "volume"
+	ColumnIngredients = "ingredients"
+)
+
+// columnAliases сопоставляет заголовки таблицы с колонками.
+// Заголовки сравниваются без учета регистра и пробелов по краям
+var columnAliases = map[string]string{
+	"sku":             ColumnSKU,
+	"артикул":         ColumnSKU,
+	"slug":            ColumnSlug,
+	"слаг":            ColumnSlug,
+	"name":            ColumnName,
+	"название":        ColumnName,
+	"brand":           ColumnBrand,
+	"бренд":           ColumnBrand,
+	"subcategory":     ColumnSubcategory,
+	"подкатегория":    ColumnSubcategory,
+	"price":           ColumnPrice,
+	"цена":            ColumnPrice,
+	"sale_price":      ColumnSalePrice,
+	"цена со скидкой": ColumnSalePrice,
+	"is_on_sale":      ColumnIsOnSale,
+	"акция":           ColumnIsOnSale,
+	"image":           ColumnImage,
+	"изображение":     ColumnImage,
+	"description":     ColumnDescription,
+	"описание":        ColumnDescription,
+	"volume":          ColumnVolume,
+	"объем":           ColumnVolume,
+	"объём":           ColumnVolume,
+	"ingredients":     ColumnIngredients,
+	"состав":          ColumnIngredients,
+}
+
+// ignoredColumns колонки выгрузки каталога, которые вычисляются
+// из других полей и при импорте пропускаются
+var ignoredColumns = map[string]bool{
+	"id":               true,
+	"category":         true,
+	"subcategory_name": true,
+	"url":              true,
+	"image_url":        true,
+	"updated_at":       true,
+}
+
+// Row строка таблицы. Values содержит значения по колонкам,
+// колонки, которых нет в таблице, в Values отсутствуют
+type Row struct {
+	Line   int
+	Values map[string]string
+}
+
+// Has сообщает, заполнена ли колонка в строке
+func (r Row) Has(column string) bool {
+	return r.Values[column] != ""
+}
+
+// ReadFile читает таблицу ассортимента. Формат определяется по расширению:
+// .csv или .xlsx. Из книги XLSX читается первый лист
+func ReadFile(path string) ([]Row, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		return ReadCSV(file)
+	case ".xlsx":
+		return ReadXLSX(path)
+	default:
+		return nil, fmt.Errorf("неподдерживаемый формат файла %s, ожидается .csv или .xlsx", path)
+	}
+}
+
+// ReadCSV читает таблицу в формате CSV. Разделителем может быть запятая
+// или точка с запятой, как при выгрузке из Excel с русскими настройками
+func ReadCSV(r io.Reader) ([]Row, error) {
+	reader := bufio.NewReader(r)
+
+	// Пропускаем метку порядка байтов, которую добавляет Excel
+	if bom, _ := reader.Peek(3); bytes.Equal(bom, []byte("\uFEFF")) {
+		reader.Discard(3)
+	}
+
+	// Разделитель определяем по строке заголовка
+	header, err := reader.Peek(4096)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, err
+	}
+	if i := bytes.IndexByte(header, '\n'); i >= 0 {
+		header = header[:i]
+	}
+
+	csvReader := csv.NewReader(reader)
+	if bytes.Count(header, []byte(";")) > bytes.Count(header, []byte(",")) {
+		csvReader.Comma = ';'
+	}
+	csvReader.FieldsPerRecord = -1
+
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	return newRows(records)
+}
+
+// newRows разбирает заголовок таблицы и превращает записи в строки.
+// Номера строк считаются с 1, заголовок — первая строка
+func newRows(records [][]string) ([]Row, error) {
+	if len(records) == 0 {
+		return nil, fmt.Errorf("таблица пуста")
+	}
+
+	columns := make([]string, len(records[0]))
+	seen := map[string]bool{}
+	for i, title := range records[0] {
+		title = strings.ToLower(strings.TrimSpace(title))
+		if title == "" {
+			continue
+		}
+		column, ok := columnAliases[title]
+		if !ok && ignoredColumns[title] {
+			continue
+		}
+		if !ok {
+			return nil, fmt.Errorf("неизвестная колонка «%s»", records[0][i])
+		}
+		if seen[column] {
+			return nil, fmt.Errorf("колонка «%s» указана дважды", records[0][i])
+		}
+		seen[column] = true
+		columns[i] = column
+	}
+	if !seen[ColumnSKU] && !seen[ColumnSlug] {
+		return nil, fmt.Errorf("в таблице нет колонки с артикулом или слагом")
+	}
+
+	var rows []Row
+	for i, record := range records[1:] {
+		row := Row{Line: i + 2, Values: map[string]string{}}
+		empty := true
+		for j, value := range record {
+			if j >= len(columns) || columns[j] == "" {
+				continue
+			}
+			value = strings.TrimSpace(value)
+			row.Values[columns[j]] = value
+			if value != "" {
+				empty = false
+			}
+		}
+		// Пустые строки в конце таблицы не считаются ошибкой
+		if !empty {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
@@ -0,0 +1,205 @@
+package importer
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		RelID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxText `xml:"si"`
+}
+
+// xlsxText текст ячейки: простой или из нескольких фрагментов с форматированием
+type xlsxText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	if len(t.Runs) == 0 {
+		return t.T
+	}
+	var b strings.Builder
+	for _, run := range t.Runs {
+		b.WriteString(run.T)
+	}
+	return b.String()
+}
+
+type xlsxSheet struct {
+	Rows []struct {
+		Index int `xml:"r,attr"`
+		Cells []struct {
+			Ref    string   `xml:"r,attr"`
+			Type   string   `xml:"t,attr"`
+			Value  string   `xml:"v"`
+			Inline xlsxText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// ReadXLSX читает первый лист книги Excel. Поддерживаются текстовые,
+// числовые и логические ячейки, формулы читаются по сохраненному значению
+func ReadXLSX(filename string) ([]Row, error) {
+	archive, err := zip.OpenReader(filename)
+	if err != nil {
+		return nil, fmt.Errorf("файл %s не похож на книгу XLSX: %w", filename, err)
+	}
+	defer archive.Close()
+
+	files := map[string]*zip.File{}
+	for _, file := range archive.File {
+		files[file.Name] = file
+	}
+
+	sheetPath, err := firstSheetPath(files)
+	if err != nil {
+		return nil, err
+	}
+
+	var shared xlsxSharedStrings
+	if file, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decodeZipXML(file, &shared); err != nil {
+			return nil, err
+		}
+	}
+
+	file, ok := files[sheetPath]
+	if !ok {
+		return nil, fmt.Errorf("в книге нет листа %s", sheetPath)
+	}
+	var sheet xlsxSheet
+	if err := decodeZipXML(file, &sheet); err != nil {
+		return nil, err
+	}
+
+	var records [][]string
+	for i, row := range sheet.Rows {
+		// Пустые строки в файле пропускаются, поэтому номер берется из атрибута r
+		index := row.Index
+		if index == 0 {
+			index = i + 1
+		}
+		for len(records) < index {
+			records = append(records, nil)
+		}
+
+		var record []string
+		for j, cell := range row.Cells {
+			column := j
+			if cell.Ref != "" {
+				column = columnIndex(cell.Ref)
+			}
+			for len(record) <= column {
+				record = append(record, "")
+			}
+
+			switch cell.Type {
+			case "s":
+				n, err := strconv.Atoi(cell.Value)
+				if err != nil || n < 0 || n >= len(shared.Items) {
+					return nil, fmt.Errorf("ячейка %s ссылается на несуществующую строку", cell.Ref)
+				}
+				record[column] = shared.Items[n].String()
+			case "inlineStr":
+				record[column] = cell.Inline.String()
+			case "b":
+				record[column] = map[string]string{"1": "true", "0": "false"}[cell.Value]
+			case "", "n":
+				record[column] = formatNumber(cell.Value)
+			default:
+				record[column] = cell.Value
+			}
+		}
+		records[index-1] = record
+	}
+
+	// Таблица должна начинаться с заголовка в первой строке листа
+	return newRows(records)
+}
+
+// firstSheetPath находит в книге файл первого листа
+func firstSheetPath(files map[string]*zip.File) (string, error) {
+	var workbook xlsxWorkbook
+	file, ok := files["xl/workbook.xml"]
+	if !ok {
+		return "", fmt.Errorf("в книге нет xl/workbook.xml")
+	}
+	if err := decodeZipXML(file, &workbook); err != nil {
+		return "", err
+	}
+	if len(workbook.Sheets) == 0 {
+		return "", fmt.Errorf("в книге нет листов")
+	}
+
+	var rels xlsxRelationships
+	if file, ok := files["xl/_rels/workbook.xml.rels"]; ok {
+		if err := decodeZipXML(file, &rels); err != nil {
+			return "", err
+		}
+	}
+	for _, rel := range rels.Relationships {
+		if rel.ID != workbook.Sheets[0].RelID {
+			continue
+		}
+		// Путь бывает абсолютным от корня архива или относительным от xl/
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/"), nil
+		}
+		return path.Join("xl", rel.Target), nil
+	}
+	return "xl/worksheets/sheet1.xml", nil
+}
+
+func decodeZipXML(file *zip.File, v any) error {
+	reader, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	if err := xml.NewDecoder(reader).Decode(v); err != nil {
+		return fmt.Errorf("ошибка чтения %s: %w", file.Name, err)
+	}
+	return nil
+}
+
+// columnIndex возвращает номер колонки с нуля по адресу ячейки, например 27 для "AB5"
+func columnIndex(ref string) int {
+	index := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		index = index*26 + int(r-'A') + 1
+	}
+	return index - 1
+}
+
+// formatNumber убирает из чисел артефакты двоичного представления,
+// которые Excel сохраняет в файле: 2599.9899999999998 превращается в 2599.99
+func formatNumber(value string) string {
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return value
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
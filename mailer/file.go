@@ -0,0 +1,28 @@
+package mailer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileMailer сохраняет письма в каталог в виде .eml файлов.
+// Используется на тестовых стендах вместо настоящей отправки
+type FileMailer struct {
+	dir string
+}
+
+// NewFileMailer создает почтовый сервис, сохраняющий письма в dir
+func NewFileMailer(dir string) (*FileMailer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileMailer{dir: dir}, nil
+}
+
+// Send сохраняет письмо в отдельный файл
+func (m *FileMailer) Send(msg Message) error {
+	name := fmt.Sprintf("%s.eml", time.Now().Format("20060102-150405.000000000"))
+	return os.WriteFile(filepath.Join(m.dir, name), format("", msg), 0o644)
+}
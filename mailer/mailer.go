@@ -0,0 +1,58 @@
+package mailer
+
+import (
+	"fmt"
+	"log"
+	"mime"
+	"strings"
+	"time"
+)
+
+// Message письмо для отправки
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer отправляет письма покупателям
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// StdoutMailer выводит письма в лог вместо отправки.
+// Используется при локальной разработке
+type StdoutMailer struct{}
+
+// NewStdoutMailer создает почтовый сервис, печатающий письма в лог
+func NewStdoutMailer() *StdoutMailer {
+	return &StdoutMailer{}
+}
+
+// Send печатает письмо в лог
+func (m *StdoutMailer) Send(msg Message) error {
+	log.Printf("Письмо для %s\nТема: %s\n\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// format собирает письмо в формате RFC 5322
+func format(from string, msg Message) []byte {
+	var b strings.Builder
+	if from != "" {
+		fmt.Fprintf(&b, "From: %s\r\n", from)
+	}
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", encodeHeader(msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+// encodeHeader кодирует заголовок письма для кириллицы
+func encodeHeader(value string) string {
+	return mime.QEncoding.Encode("utf-8", value)
+}
@@ -1,118 +1,314 @@
 package main
 
 import (
-	"cosmetics_catalog/database"
+	"cosmetics_catalog/config"
+	"cosmetics_catalog/mailer"
 	"cosmetics_catalog/models"
 	"cosmetics_catalog/repositories"
+	"cosmetics_catalog/router"
+	"cosmetics_catalog/seo"
+	"errors"
+	"flag"
+	"fmt"
 	"html/template"
 	"log"
 	"net/http"
+	"os"
+	"slices"
 	"strconv"
 	"strings"
 
 	"gorm.io/gorm"
 )
 
-var productRepo *repositories.ProductRepository
-
 func main() {
-	// Подключение к базе данных
-	if err := database.Connect(); err != nil {
-		log.Fatalf("Ошибка подключения к базе данных: %v", err)
+	// Без подкоманды приложение запускает веб-сервер, как и раньше
+	name, args := "serve", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
 	}
 
-	// Посев тестовых данных
-	if err := database.SeedTestData(); err != nil {
-		log.Fatalf("Ошибка при посеве данных: %v", err)
+	cmd, ok := findCommand(name)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Неизвестная команда %q\n\n", name)
+		printUsage()
+		os.Exit(2)
+	}
+	if err := cmd.run(args); err != nil {
+		log.Fatal(err)
 	}
+}
 
-	// Инициализация репозитория продуктов
-	productRepo = repositories.NewProductRepository(database.DB)
+// runServe запускает веб-сервер
+func runServe(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	settings := config.BindFlags(flags, "db", "addr", "base-url", "templates", "photos", "log-level")
+	demo := flags.Bool("demo", false, "демо-режим: тестовый каталог в памяти без сохранения в базе")
+	flags.Parse(args)
+
+	// Настройки и подключение к базе данных
+	var (
+		cfg     config.Config
+		db      *gorm.DB
+		catalog repositories.Catalog
+		err     error
+	)
+	if *demo {
+		cfg, db, catalog, err = setupDemo(settings)
+	} else {
+		cfg, db, err = setup(settings)
+		catalog = repositories.NewCatalog(db)
+	}
+	if err != nil {
+		return err
+	}
+	if err := cfg.ValidateDirs(); err != nil {
+		return fmt.Errorf("ошибка в настройках: %w", err)
+	}
+	for _, warning := range cfg.Warnings() {
+		log.Printf("Внимание: %s", warning)
+	}
 
-	// Настройка маршрутов
-	http.HandleFunc("/", handleCatalogRoutes)
+	// Инициализация почтового сервиса
+	mail, err := newMailer(cfg.Mail)
+	if err != nil {
+		return fmt.Errorf("ошибка настройки почты: %w", err)
+	}
+
+	app, err := NewApp(cfg, db, catalog, mail)
+	if err != nil {
+		return err
+	}
 
-	// Раздача статических файлов из папки photos
-	http.Handle("/photos/", http.StripPrefix("/photos/", http.FileServer(http.Dir("./photos"))))
+	log.Printf("Сервер запущен на %s", cfg.Addr)
+	return http.ListenAndServe(cfg.Addr, app)
+}
 
-	log.Println("Сервер запущен на http://localhost:8080")
-	http.ListenAndServe(":8080", nil)
+// newRouter регистрирует маршруты приложения. Имена маршрутов
+// используются в шаблонах для построения адресов через функцию url
+func (app *App) newRouter() *router.Router {
+	rt := router.New()
+
+	// Каталог. Фиксированные слова sales и brands важнее слага категории
+	rt.Get("/", app.handleHome).Name("home")
+	rt.Get("/catalog", app.handleHome)
+	rt.Get("/catalog/", app.handleMainPage).Name("catalog")
+	rt.Get("/catalog/sales", app.handleSaleProducts).Name("sales")
+	if app.config.Features.AtomFeeds {
+		rt.Get("/catalog/sales/feed", app.handleSalesFeed).Name("sales.feed")
+	}
+	rt.Get("/catalog/sales/{product}", app.handleSaleProduct).Name("sales.product")
+	rt.Get("/catalog/brands", app.handleBrands).Name("brands")
+	rt.Get("/catalog/brands/{brand}", app.handleBrandProducts).Name("brand")
+	if app.config.Features.AtomFeeds {
+		rt.Get("/catalog/brands/{brand}/feed", app.handleBrandFeed).Name("brand.feed")
+	}
+	rt.Get("/catalog/brands/{brand}/{product}", app.handleBrandProduct).Name("brand.product")
+	rt.Get("/catalog/{category}", app.handleCatalogSubcategory).Name("category")
+	rt.Get("/catalog/{category}/{path...}", app.handleCatalogPath).Name("catalog.path")
+
+	// Избранное, сравнение и корзина
+	rt.Get("/wishlist", app.handleWishlist).Name("wishlist")
+	rt.Post("/wishlist/toggle", app.handleWishlistToggle).Name("wishlist.toggle")
+	rt.Get("/compare", app.handleCompare).Name("compare")
+	rt.Get("/compare.json", app.handleCompareJSON).Name("compare.json")
+	rt.Post("/compare/toggle", app.handleCompareToggle).Name("compare.toggle")
+	rt.Get("/cart", app.handleCart).Name("cart")
+	rt.Post("/cart/add", app.handleCartAdd).Name("cart.add")
+	rt.Post("/cart/update", app.handleCartUpdate).Name("cart.update")
+	rt.Post("/cart/promo", app.handleCartPromo).Name("cart.promo")
+	rt.Post("/cart/checkout", app.handleCheckout).Name("cart.checkout")
+
+	// Личный кабинет
+	rt.Get("/account", app.handleAccount).Name("account")
+	rt.Get("/account/register", app.handleRegister).Name("account.register")
+	rt.Post("/account/register", app.handleRegister)
+	rt.Get("/account/verify", app.handleVerifyEmail).Name("account.verify")
+	rt.Get("/account/login", app.handleLogin).Name("account.login")
+	rt.Post("/account/login", app.handleLogin)
+	rt.Post("/account/logout", app.handleLogout).Name("account.logout")
+	rt.Get("/account/password/forgot", app.handleForgotPassword).Name("account.forgot")
+	rt.Post("/account/password/forgot", app.handleForgotPassword)
+	rt.Get("/account/password/reset", app.handleResetPassword).Name("account.reset")
+	rt.Post("/account/password/reset", app.handleResetPassword)
+	rt.Post("/account/profile", app.handleProfile).Name("account.profile")
+	rt.Post("/account/addresses", app.handleAddresses).Name("account.addresses")
+
+	// Карта сайта и правила для поисковых роботов
+	rt.Get("/sitemap.xml", app.handleSitemap).Name("sitemap")
+	rt.Get("/sitemaps/{page}", app.handleSitemapPage).Name("sitemap.page")
+	rt.Get("/robots.txt", app.handleRobots).Name("robots")
+
+	// Товарные фиды для маркетплейсов
+	if app.config.Features.Feeds {
+		rt.Get("/feeds/yandex.yml", app.handleYandexFeed).Name("feeds.yandex")
+		rt.Get("/feeds/google.xml", app.handleGoogleFeed).Name("feeds.google")
+	}
+
+	// Администрирование
+	if app.config.Features.Admin {
+		rt.Get("/admin/export", app.requireAdmin(app.handleAdminExport)).Name("admin.export")
+		rt.Get("/admin/prices", app.requireAdmin(app.handleAdminPrices)).Name("admin.prices")
+		rt.Post("/admin/prices", app.requireAdmin(app.handleAdminPrices))
+		rt.Get("/admin/slugs", app.requireAdmin(app.handleAdminSlugs)).Name("admin.slugs")
+		rt.Get("/admin/slugs/{entity}/{id}", app.requireAdmin(app.handleAdminSlug)).Name("admin.slug")
+		rt.Post("/admin/slugs/{entity}/{id}", app.requireAdmin(app.handleAdminSlug))
+	}
+
+	// Раздача статических файлов из каталога фотографий
+	photos := http.StripPrefix("/photos/", http.FileServer(http.Dir(app.config.PhotoDir)))
+	rt.Handle(http.MethodGet, "/photos/{file...}", photos).Name("photo")
+
+	return rt
 }
 
-func handleCatalogRoutes(w http.ResponseWriter, r *http.Request) {
-	path := strings.TrimPrefix(r.URL.Path, "/catalog/")
-	parts := strings.Split(path, "/")
+// newBreadcrumbs начинает навигационную цепочку текущего запроса
+func (app *App) newBreadcrumbs() seo.Breadcrumbs {
+	return seo.NewBreadcrumbs(app.absoluteURL(""))
+}
 
-	if len(parts) > 0 && (parts[0] == "favicon.ico" || parts[0] == "images" || parts[0] == "assets") {
-		http.NotFound(w, r)
-		return
+// pageMeta дополняет метаданные адресом страницы. Если у страницы
+// нет своего изображения, для OpenGraph берется фото первого товара
+func (app *App) pageMeta(r *http.Request, meta seo.Meta, products []models.Product) seo.Meta {
+	if len(products) > 0 {
+		meta = meta.WithImage(products[0].ImagePath)
 	}
+	return meta.Absolute(app.absoluteURL(""), r.URL.Path)
+}
 
-	switch {
-	case len(parts) == 0 || parts[0] == "": // /catalog/
-		handleMainPage(w, r)
+// Корневой адрес и /catalog ведут на главную страницу каталога
+func (app *App) handleHome(w http.ResponseWriter, r *http.Request) {
+	http.Redirect(w, r, "/catalog/", http.StatusMovedPermanently)
+}
 
-	case len(parts) == 1 && parts[0] == "sales": // /catalog/sales
-		handleSaleProducts(w, r)
+// newMailer выбирает почтовый сервис по настройкам почты:
+// адрес SMTP включает отправку через SMTP, каталог сохраняет письма в файлы,
+// без настроек письма выводятся в лог
+func newMailer(settings config.Mail) (mailer.Mailer, error) {
+	switch {
+	case settings.SMTPAddr != "":
+		return mailer.NewSMTPMailer(
+			settings.SMTPAddr,
+			settings.From,
+			settings.SMTPUsername,
+			settings.SMTPPassword,
+		)
+	case settings.Dir != "":
+		return mailer.NewFileMailer(settings.Dir)
+	default:
+		return mailer.NewStdoutMailer(), nil
+	}
+}
 
-	case len(parts) == 1 && parts[0] == "brands": // /catalog/brands
-		handleBrands(w, r)
+// handleCatalogPath разбирает путь внутри категории произвольной глубины.
+// Если весь путь ведет к подкатегории, показывается список ее продуктов,
+// иначе последний сегмент считается слагом продукта
+func (app *App) handleCatalogPath(w http.ResponseWriter, r *http.Request) {
+	categorySlug := r.PathValue("category")
+	slugs := strings.Split(r.PathValue("path"), "/")
 
-	case len(parts) == 1: // /catalog/{category}
-		handleCatalogSubcategory(w, r, parts[0])
+	category, err := app.categories.GetBySlug(strings.ToLower(categorySlug))
+	if err != nil {
+		// Категория могла быть переименована
+		if moved, err := app.slugHistory.FindCategory(categorySlug); err == nil {
+			redirectPermanent(w, r, "/catalog/"+moved.Slug+"/"+strings.Join(slugs, "/"))
+			return
+		}
+		http.NotFound(w, r)
+		return
+	}
 
-	case len(parts) == 2 && parts[0] == "sales": // /catalog/sales/{product}
-		handleProduct(w, r, parts[0], "", parts[1])
+	path := strings.Join(slugs, "/")
+	subcategory, err := app.subcategories.GetByPath(category.ID, path)
+	if err == nil {
+		app.handleCategoryProducts(w, r, subcategory)
+		return
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		http.Error(w, "Ошибка получения подкатегории", http.StatusInternalServerError)
+		return
+	}
 
-	case len(parts) == 2 && parts[0] == "brands": // /catalog/brands/{brand}
-		handleBrandProducts(w, r, parts[1])
+	var subcategoryPath, productSlug string
+	if len(slugs) > 1 {
+		subcategoryPath = strings.Join(slugs[:len(slugs)-1], "/")
+		productSlug = slugs[len(slugs)-1]
+		if _, err := app.subcategories.GetByPath(category.ID, subcategoryPath); err == nil {
+			app.handleProduct(w, r, categorySlug, subcategoryPath, productSlug)
+			return
+		}
+	}
 
-	case len(parts) == 2: // /catalog/{category}/{subcategory}
-		handleCategoryProducts(w, r, parts[0], parts[1])
+	// Путь мог принадлежать переименованной или перемещенной подкатегории
+	if moved, err := app.slugHistory.FindSubcategory(category.ID, path); err == nil {
+		redirectPermanent(w, r, moved.URL())
+		return
+	}
+	if productSlug != "" {
+		if moved, err := app.slugHistory.FindSubcategory(category.ID, subcategoryPath); err == nil {
+			redirectPermanent(w, r, moved.URL()+"/"+productSlug)
+			return
+		}
+	}
 
-	case len(parts) == 3 && parts[0] != "sales": // /catalog/{category}/{subcategory}/{product}
-		handleProduct(w, r, parts[0], parts[1], parts[2])
+	http.NotFound(w, r)
+}
 
-	default:
-		http.NotFound(w, r)
+// redirectPermanent перенаправляет на новый адрес страницы с сохранением параметров запроса
+func redirectPermanent(w http.ResponseWriter, r *http.Request, path string) {
+	if r.URL.RawQuery != "" {
+		path += "?" + r.URL.RawQuery
 	}
+	http.Redirect(w, r, path, http.StatusMovedPermanently)
 }
 
 // Главная страница с категориями
-func handleMainPage(w http.ResponseWriter, r *http.Request) {
-	tmpl, err := template.ParseFiles("templates/catalog.html")
+func (app *App) handleMainPage(w http.ResponseWriter, r *http.Request) {
+	tmpl, err := app.parseTemplate("catalog.html")
 	if err != nil {
 		http.Error(w, "Ошибка загрузки шаблона", http.StatusInternalServerError)
 		return
 	}
 
-	var categories []models.Category
-	if err := database.DB.Find(&categories).Error; err != nil {
+	categories, err := app.categories.List("")
+	if err != nil {
 		http.Error(w, "Ошибка получения категорий", http.StatusInternalServerError)
 		return
 	}
 
-	err = tmpl.Execute(w, categories)
+	data := struct {
+		Categories  []repositories.CategoryItem
+		Breadcrumbs seo.Breadcrumbs
+		Meta        seo.Meta
+	}{
+		Categories:  categories,
+		Breadcrumbs: app.newBreadcrumbs(),
+		Meta:        app.pageMeta(r, seo.NewMeta("Все категории", "Каталог косметики: уход, макияж, бренды и товары по акции."), nil),
+	}
+
+	err = tmpl.Execute(w, data)
 	if err != nil {
 		http.Error(w, "Ошибка рендеринга", http.StatusInternalServerError)
 	}
 }
 
 // Страница подкатегории макияж уход
-func handleCatalogSubcategory(w http.ResponseWriter, r *http.Request, slug string) {
-	var current models.Category
-
-	// Находим категорию и подгружаем подкатегории
-	if err := database.DB.
-		Preload("Subcategories").
-		Where("slug = ?", slug).
-		First(&current).Error; err != nil {
+func (app *App) handleCatalogSubcategory(w http.ResponseWriter, r *http.Request) {
+	slug := r.PathValue("category")
 
+	// Находим категорию и подкатегории верхнего уровня
+	current, err := app.categories.GetBySlug(slug)
+	if err != nil {
+		// Категория могла быть переименована
+		if moved, err := app.slugHistory.FindCategory(slug); err == nil {
+			redirectPermanent(w, r, "/catalog/"+moved.Slug)
+			return
+		}
 		http.NotFound(w, r)
 		return
 	}
 
-	tmpl, err := template.ParseFiles("templates/subcategory.html")
+	tmpl, err := app.parseTemplate("subcategory.html")
 	if err != nil {
 		http.Error(w, "Ошибка загрузки шаблона", http.StatusInternalServerError)
 		return
@@ -123,10 +319,14 @@ func handleCatalogSubcategory(w http.ResponseWriter, r *http.Request, slug strin
 		Name          string
 		Slug          string
 		Subcategories []models.Subcategory
+		Breadcrumbs   seo.Breadcrumbs
+		Meta          seo.Meta
 	}{
 		Name:          current.Name,
 		Slug:          current.Slug,
 		Subcategories: current.Subcategories,
+		Breadcrumbs:   app.newBreadcrumbs().Category(*current),
+		Meta:          app.pageMeta(r, seo.CategoryMeta(*current), nil),
 	}
 
 	if err := tmpl.Execute(w, data); err != nil {
@@ -135,27 +335,54 @@ func handleCatalogSubcategory(w http.ResponseWriter, r *http.Request, slug strin
 }
 
 // Страница отображения брендов
-func handleBrands(w http.ResponseWriter, r *http.Request) {
-	tmpl, err := template.ParseFiles("templates/brands.html")
+func (app *App) handleBrands(w http.ResponseWriter, r *http.Request) {
+	tmpl, err := app.parseTemplate("brands.html")
 	if err != nil {
 		http.Error(w, "Ошибка загрузки шаблона", http.StatusInternalServerError)
 		return
 	}
 
-	var brands []models.Brand
-	if err := database.DB.Find(&brands).Error; err != nil {
-		http.Error(w, "Ошибка получения категорий", http.StatusInternalServerError)
+	brands, err := app.brands.List(repositories.ListByName)
+	if err != nil {
+		http.Error(w, "Ошибка получения брендов", http.StatusInternalServerError)
 		return
 	}
 
-	err = tmpl.Execute(w, brands)
+	// Поиск оставляет в списке только бренды, найденные по названию
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query != "" {
+		found, err := app.brands.Search(query, 0)
+		if err != nil {
+			http.Error(w, "Ошибка поиска брендов", http.StatusInternalServerError)
+			return
+		}
+		ids := make(map[uint]bool, len(found))
+		for _, brand := range found {
+			ids[brand.ID] = true
+		}
+		brands = slices.DeleteFunc(brands, func(item repositories.BrandItem) bool { return !ids[item.ID] })
+	}
+
+	data := struct {
+		Brands      []repositories.BrandItem
+		Query       string
+		Breadcrumbs seo.Breadcrumbs
+		Meta        seo.Meta
+	}{
+		Brands:      brands,
+		Query:       query,
+		Breadcrumbs: app.newBreadcrumbs().Add("Бренды", "/catalog/brands"),
+		Meta:        app.pageMeta(r, seo.NewMeta("Бренды", "Все бренды каталога косметики."), nil),
+	}
+
+	err = tmpl.Execute(w, data)
 	if err != nil {
 		http.Error(w, "Ошибка рендеринга", http.StatusInternalServerError)
 	}
 }
 
-// Страница всех продуктов подкатегории
-func handleCategoryProducts(w http.ResponseWriter, r *http.Request, categorySlug, subcategorySlug string) {
+// Страница всех продуктов подкатегории вместе с вложенными подкатегориями
+func (app *App) handleCategoryProducts(w http.ResponseWriter, r *http.Request, subcategory *models.Subcategory) {
 
 	// Получаем параметры фильтрации
 	query := r.URL.Query()
@@ -171,76 +398,37 @@ func handleCategoryProducts(w http.ResponseWriter, r *http.Request, categorySlug
 		maxPrice, _ = strconv.ParseFloat(query.Get("max_price"), 64)
 	}
 
-	// Отображение продуктов без фильтра
-	var subcat models.Subcategory
-	if filter == "no" {
-		if err := database.DB.
-			Preload("Products").
-			Joins("JOIN categories ON categories.id = subcategories.category_id").
-			Where("subcategories.slug = ? AND categories.slug = ?",
-				strings.ToLower(subcategorySlug),
-				strings.ToLower(categorySlug)).
-			First(&subcat).Error; err != nil {
-
-			http.NotFound(w, r)
-			return
-		}
+	// Сортировка продуктов по цене
+	var sort string
+	switch filter {
+	case "high":
+		sort = "asc"
+	case "low":
+		sort = "desc"
 	}
 
-	// Сортировка продуктов по возрастанию цены
-	if filter == "high" {
-		if err := database.DB.
-			Preload("Products", func(db *gorm.DB) *gorm.DB {
-				return db.Order("products.price ASC")
-			}).
-			Joins("JOIN categories ON categories.id = subcategories.category_id").
-			Where("subcategories.slug = ? AND categories.slug = ?",
-				strings.ToLower(subcategorySlug),
-				strings.ToLower(categorySlug)).
-			First(&subcat).Error; err != nil {
-
-			http.NotFound(w, r)
-			return
-		}
+	// Список включает продукты всех потомков подкатегории
+	ids, err := app.subcategories.GetDescendantIDs(subcategory)
+	if err != nil {
+		http.Error(w, "Ошибка получения подкатегорий", http.StatusInternalServerError)
+		return
 	}
 
-	// Сортировка продуктов по убыванию цены
-	if filter == "low" {
-		if err := database.DB.
-			Preload("Products", func(db *gorm.DB) *gorm.DB {
-				return db.Order("products.price DESC")
-			}).
-			Joins("JOIN categories ON categories.id = subcategories.category_id").
-			Where("subcategories.slug = ? AND categories.slug = ?",
-				strings.ToLower(subcategorySlug),
-				strings.ToLower(categorySlug)).
-			First(&subcat).Error; err != nil {
-
-			http.NotFound(w, r)
-			return
-		}
+	products, err := app.products.GetBySubcategories(ids, sort, minPrice, maxPrice)
+	if err != nil {
+		http.Error(w, "Ошибка получения продуктов", http.StatusInternalServerError)
+		return
 	}
 
-	// Отображение продуктов в промежутке от minPrice до maxPrice
-	if filter == "range" {
-		if err := database.DB.
-			Preload("Products", func(db *gorm.DB) *gorm.DB {
-				return db.
-					Where("products.price BETWEEN ? AND ?", minPrice, maxPrice)
-			}).
-			Joins("JOIN categories ON categories.id = subcategories.category_id").
-			Where("subcategories.slug = ? AND categories.slug = ?",
-				strings.ToLower(subcategorySlug),
-				strings.ToLower(categorySlug)).
-			First(&subcat).Error; err != nil {
-
-			http.NotFound(w, r)
-			return
-		}
+	// Хлебные крошки строятся по дереву от категории до текущей подкатегории
+	ancestors, err := app.subcategories.GetAncestors(subcategory)
+	if err != nil {
+		http.Error(w, "Ошибка получения подкатегорий", http.StatusInternalServerError)
+		return
 	}
 
 	// Загружаем шаблон
-	tmpl, err := template.ParseFiles("templates/products.html")
+	tmpl, err := app.parseTemplate("products.html")
 	if err != nil {
 		http.Error(w, "Ошибка загрузки шаблона", http.StatusInternalServerError)
 		return
@@ -248,19 +436,29 @@ func handleCategoryProducts(w http.ResponseWriter, r *http.Request, categorySlug
 
 	// Подготавливаем данные для шаблона
 	data := struct {
-		Filter          string
-		CategorySlug    string
-		SubcategorySlug string
-		MinPrice        float64
-		MaxPrice        float64
-		Products        []models.Product
+		Title         string
+		Path          string
+		Breadcrumbs   seo.Breadcrumbs
+		Meta          seo.Meta
+		Subcategories []models.Subcategory
+		Filter        string
+		MinPrice      float64
+		MaxPrice      float64
+		Products      []models.Product
+		Wishlist      map[uint]bool
+		Compare       map[uint]bool
 	}{
-		Filter:          filter,
-		CategorySlug:    categorySlug,
-		SubcategorySlug: subcategorySlug,
-		MinPrice:        minPrice,
-		MaxPrice:        maxPrice,
-		Products:        subcat.Products,
+		Title:         subcategory.Name,
+		Path:          subcategory.URL(),
+		Breadcrumbs:   app.newBreadcrumbs().Subcategory(ancestors, *subcategory),
+		Meta:          app.pageMeta(r, seo.SubcategoryMeta(*subcategory), products),
+		Subcategories: subcategory.Children,
+		Filter:        filter,
+		MinPrice:      minPrice,
+		MaxPrice:      maxPrice,
+		Products:      products,
+		Wishlist:      app.wishlistProductIDs(r),
+		Compare:       app.compareSet(r),
 	}
 
 	// Рендерим шаблон
@@ -269,17 +467,73 @@ func handleCategoryProducts(w http.ResponseWriter, r *http.Request, categorySlug
 	}
 }
 
-// Страница конкретного продукта
-func handleProduct(w http.ResponseWriter, r *http.Request, category, subcategory, productSlug string) {
+// Продукт из раздела акций
+func (app *App) handleSaleProduct(w http.ResponseWriter, r *http.Request) {
+	app.handleProduct(w, r, "sales", "", r.PathValue("product"))
+}
+
+// Продукт со страницы бренда
+func (app *App) handleBrandProduct(w http.ResponseWriter, r *http.Request) {
+	app.handleProduct(w, r, "brands", r.PathValue("brand"), r.PathValue("product"))
+}
+
+// Страница конкретного продукта.
+// Продукт открывается по каноническому адресу /catalog/{category}/{subcategory...}/{product},
+// адреса из раздела акций и страницы бренда перенаправляют на него.
+// Прежние слаги продукта и бренда тоже перенаправляют на канонический адрес.
+// Если продукт не принадлежит указанной категории, подкатегории или бренду, возвращается 404
+func (app *App) handleProduct(w http.ResponseWriter, r *http.Request, category, subcategory, productSlug string) {
 
-	var product models.Product
-	if err := database.DB.Where("slug = ?", productSlug).First(&product).Error; err != nil {
+	product, err := app.products.GetBySlug(strings.ToLower(productSlug))
+	if err != nil {
+		moved, historyErr := app.slugHistory.FindProduct(productSlug)
+		if historyErr != nil {
+			http.NotFound(w, r)
+			return
+		}
+		product = moved
+	}
+
+	var belongs bool
+	switch category {
+	case "sales": // /catalog/sales/{product}
+		belongs = product.IsOnSale
+	case "brands": // /catalog/brands/{brand}/{product}
+		belongs = product.Brand.Slug == strings.ToLower(subcategory)
+		if !belongs {
+			moved, err := app.slugHistory.FindBrand(subcategory)
+			belongs = err == nil && moved.ID == product.BrandID
+		}
+	default:
+		belongs = product.Subcategory.Category.Slug == strings.ToLower(category) &&
+			product.Subcategory.Path == strings.ToLower(subcategory)
+	}
+	if !belongs {
 		http.NotFound(w, r)
 		return
 	}
 
+	canonical := product.URL()
+	if r.URL.Path != canonical {
+		redirectPermanent(w, r, canonical)
+		return
+	}
+
+	ancestors, err := app.subcategories.GetAncestors(&product.Subcategory)
+	if err != nil {
+		http.Error(w, "Ошибка получения подкатегорий", http.StatusInternalServerError)
+		return
+	}
+
+	ratings, err := app.reviews.GetRatings([]uint{product.ID})
+	if err != nil {
+		http.Error(w, "Ошибка получения отзывов", http.StatusInternalServerError)
+		return
+	}
+
 	// Создаем структуру данных для шаблона
 	data := struct {
+		ID          uint
 		Name        string
 		Slug        string
 		Price       float64
@@ -287,7 +541,13 @@ func handleProduct(w http.ResponseWriter, r *http.Request, category, subcategory
 		Description string
 		IsOnSale    bool
 		SalePrice   float64
+		InCompare   bool
+		Canonical   string
+		Breadcrumbs seo.Breadcrumbs
+		Meta        seo.Meta
+		JSONLD      template.JS
 	}{
+		ID:          product.ID,
 		Name:        product.Name,
 		Slug:        productSlug,
 		Price:       product.Price,
@@ -295,9 +555,14 @@ func handleProduct(w http.ResponseWriter, r *http.Request, category, subcategory
 		Description: product.Description,
 		IsOnSale:    product.IsOnSale,
 		SalePrice:   product.SalePrice,
+		InCompare:   app.compareSet(r)[product.ID],
+		Canonical:   app.absoluteURL(canonical),
+		Breadcrumbs: app.newBreadcrumbs().Subcategory(ancestors, product.Subcategory).Add(product.Name, canonical),
+		Meta:        app.pageMeta(r, seo.ProductMeta(*product), nil),
+		JSONLD:      seo.ProductJSONLD(*product, ratings[product.ID], app.absoluteURL("")),
 	}
 
-	tmpl, err := template.ParseFiles("templates/product.html")
+	tmpl, err := app.parseTemplate("product.html")
 	if err != nil {
 		http.Error(w, "Ошибка загрузки шаблона", http.StatusInternalServerError)
 		return
@@ -311,7 +576,9 @@ func handleProduct(w http.ResponseWriter, r *http.Request, category, subcategory
 }
 
 // Страница всех продуктов бренда
-func handleBrandProducts(w http.ResponseWriter, r *http.Request, brandSlug string) {
+func (app *App) handleBrandProducts(w http.ResponseWriter, r *http.Request) {
+	brandSlug := r.PathValue("brand")
+
 	// Получаем параметры фильтрации
 	query := r.URL.Query()
 	filter := query.Get("filter")
@@ -326,54 +593,42 @@ func handleBrandProducts(w http.ResponseWriter, r *http.Request, brandSlug strin
 		maxPrice, _ = strconv.ParseFloat(query.Get("max_price"), 64)
 	}
 
-	var brand models.Brand
-	var products []models.Product
-	var err error
-
-	// Базовый запрос для получения бренда
-	baseQuery := database.DB.Where("slug = ?", strings.ToLower(brandSlug))
-
-	// В зависимости от фильтра применяем разные условия
+	// Сортировка продуктов по цене
+	var sort string
 	switch filter {
-	case "no":
-		// Без фильтрации (все продукты бренда)
-		err = baseQuery.Preload("Products").First(&brand).Error
-		products = brand.Products
-
+	case "no", "range":
 	case "high":
-		// Сортировка по возрастанию цены
-		err = baseQuery.Preload("Products", func(db *gorm.DB) *gorm.DB {
-			return db.Order("products.price ASC")
-		}).First(&brand).Error
-		products = brand.Products
-
+		sort = "asc"
 	case "low":
-		// Сортировка по убыванию цены
-		err = baseQuery.Preload("Products", func(db *gorm.DB) *gorm.DB {
-			return db.Order("products.price DESC")
-		}).First(&brand).Error
-		products = brand.Products
-
-	case "range":
-		// Фильтрация по ценовому диапазону
-		err = baseQuery.Preload("Products", func(db *gorm.DB) *gorm.DB {
-			return db.
-				Where("products.price BETWEEN ? AND ?", minPrice, maxPrice)
-		}).First(&brand).Error
-		products = brand.Products
-
+		sort = "desc"
 	default:
 		http.NotFound(w, r)
 		return
 	}
 
+	brand, err := app.brands.GetBySlug(strings.ToLower(brandSlug))
 	if err != nil {
+		// Бренд мог быть переименован
+		if moved, err := app.slugHistory.FindBrand(brandSlug); err == nil {
+			redirectPermanent(w, r, "/catalog/brands/"+moved.Slug)
+			return
+		}
 		http.NotFound(w, r)
 		return
 	}
 
+	// Продукты бренда уже загружены, запрос нужен только для сортировки и фильтра
+	products := brand.Products
+	if sort != "" || minPrice > 0 || maxPrice > 0 {
+		products, err = app.products.GetByBrand(brand.ID, sort, minPrice, maxPrice)
+		if err != nil {
+			http.Error(w, "Ошибка получения продуктов", http.StatusInternalServerError)
+			return
+		}
+	}
+
 	// Загружаем шаблон
-	tmpl, err := template.ParseFiles("templates/products.html")
+	tmpl, err := app.parseTemplate("products.html")
 	if err != nil {
 		http.Error(w, "Ошибка загрузки шаблона", http.StatusInternalServerError)
 		return
@@ -381,19 +636,28 @@ func handleBrandProducts(w http.ResponseWriter, r *http.Request, brandSlug strin
 
 	// Подготавливаем данные для шаблона
 	data := struct {
-		Filter          string
-		CategorySlug    string
-		SubcategorySlug string
-		MinPrice        float64
-		MaxPrice        float64
-		Products        []models.Product
+		Title         string
+		Path          string
+		Breadcrumbs   seo.Breadcrumbs
+		Meta          seo.Meta
+		Subcategories []models.Subcategory
+		Filter        string
+		MinPrice      float64
+		MaxPrice      float64
+		Products      []models.Product
+		Wishlist      map[uint]bool
+		Compare       map[uint]bool
 	}{
-		Filter:          filter,
-		CategorySlug:    "brands",
-		SubcategorySlug: brandSlug,
-		MinPrice:        minPrice,
-		MaxPrice:        maxPrice,
-		Products:        products,
+		Title:       brand.Name,
+		Path:        "/catalog/brands/" + brand.Slug,
+		Breadcrumbs: app.newBreadcrumbs().Brand(*brand),
+		Meta:        app.pageMeta(r, seo.BrandMeta(*brand), products).WithFeed(app.atomFeedURL(brand.URL() + "/feed")),
+		Filter:      filter,
+		MinPrice:    minPrice,
+		MaxPrice:    maxPrice,
+		Products:    products,
+		Wishlist:    app.wishlistProductIDs(r),
+		Compare:     app.compareSet(r),
 	}
 
 	// Рендерим шаблон
@@ -403,7 +667,7 @@ func handleBrandProducts(w http.ResponseWriter, r *http.Request, brandSlug strin
 }
 
 // Страница товаров со скидкой
-func handleSaleProducts(w http.ResponseWriter, r *http.Request) {
+func (app *App) handleSaleProducts(w http.ResponseWriter, r *http.Request) {
 
 	// Получаем параметры фильтрации
 	query := r.URL.Query()
@@ -419,37 +683,23 @@ func handleSaleProducts(w http.ResponseWriter, r *http.Request) {
 		maxPrice, _ = strconv.ParseFloat(query.Get("max_price"), 64)
 	}
 
-	var products []models.Product
-	var err error
-
-	// Создаем базовый запрос
-	dbQuery := database.DB.Model(&models.Product{}).Where("is_on_sale = true")
-
-	// Применяем фильтры
+	// Сортировка продуктов по цене
+	var sort string
 	switch filter {
-	case "no":
-		// Без дополнительной фильтрации
-		err = dbQuery.Find(&products).Error
 	case "high":
-		// Сортировка по возрастанию цены
-		err = dbQuery.Order("price ASC").Find(&products).Error
+		sort = "asc"
 	case "low":
-		// Сортировка по убыванию цены
-		err = dbQuery.Order("price DESC").Find(&products).Error
-	case "range":
-		// Фильтрация по ценовому диапазону
-		err = dbQuery.
-			Where("price BETWEEN ? AND ?", minPrice, maxPrice).
-			Find(&products).Error
+		sort = "desc"
 	}
 
+	products, err := app.products.GetOnSale(sort, minPrice, maxPrice)
 	if err != nil {
 		http.Error(w, "Ошибка базы данных: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	// Загружаем шаблон
-	tmpl, err := template.ParseFiles("templates/products_sales.html")
+	tmpl, err := app.parseTemplate("products_sales.html")
 	if err != nil {
 		http.Error(w, "Ошибка загрузки шаблона", http.StatusInternalServerError)
 		return
@@ -457,15 +707,24 @@ func handleSaleProducts(w http.ResponseWriter, r *http.Request) {
 
 	// Подготавливаем данные для шаблона
 	data := struct {
-		Filter   string
-		MinPrice float64
-		MaxPrice float64
-		Products []models.Product
+		Filter      string
+		MinPrice    float64
+		MaxPrice    float64
+		Products    []models.Product
+		Wishlist    map[uint]bool
+		Compare     map[uint]bool
+		Breadcrumbs seo.Breadcrumbs
+		Meta        seo.Meta
 	}{
-		Filter:   filter,
-		MinPrice: minPrice,
-		MaxPrice: maxPrice,
-		Products: products,
+		Filter:      filter,
+		MinPrice:    minPrice,
+		MaxPrice:    maxPrice,
+		Products:    products,
+		Wishlist:    app.wishlistProductIDs(r),
+		Compare:     app.compareSet(r),
+		Breadcrumbs: app.newBreadcrumbs().Add("Акции", "/catalog/sales"),
+		Meta: app.pageMeta(r, seo.NewMeta("Товары со скидкой", "Косметика по акции: товары со скидкой из всех разделов каталога."), products).
+			WithFeed(app.atomFeedURL("/catalog/sales/feed")),
 	}
 
 	// Рендерим шаблон
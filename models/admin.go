@@ -0,0 +1,10 @@
+package models
+
+import "gorm.io/gorm"
+
+// AdminUser сотрудник с доступом к разделу администрирования
+type AdminUser struct {
+	gorm.Model
+	Login        string `gorm:"unique;not null;size:100"`
+	PasswordHash string `gorm:"not null;size:255"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Customer учетная запись покупателя
+type Customer struct {
+	gorm.Model
+	Email           string `gorm:"unique;not null;size:255"`
+	PasswordHash    string `gorm:"not null;size:255"`
+	Name            string `gorm:"size:100"`
+	Phone           string `gorm:"size:30"`
+	EmailVerifiedAt *time.Time
+	Addresses       []Address `gorm:"foreignKey:CustomerID"`
+}
+
+// IsVerified сообщает, подтвердил ли покупатель email
+func (c *Customer) IsVerified() bool {
+	return c.EmailVerifiedAt != nil
+}
+
+// Назначение одноразовых токенов покупателя
+const (
+	TokenPurposeVerifyEmail   = "verify_email"
+	TokenPurposeResetPassword = "reset_password"
+)
+
+// CustomerToken одноразовый токен для подтверждения email или сброса пароля.
+// В базе хранится только хеш токена
+type CustomerToken struct {
+	gorm.Model
+	CustomerID uint      `gorm:"not null;index"`
+	Purpose    string    `gorm:"not null;size:30"`
+	TokenHash  string    `gorm:"unique;not null;size:64"`
+	ExpiresAt  time.Time `gorm:"not null"`
+	UsedAt     *time.Time
+}
+
+// Address адрес доставки из адресной книги покупателя
+type Address struct {
+	gorm.Model
+	CustomerID uint   `gorm:"not null;index"`
+	Recipient  string `gorm:"not null;size:100"`
+	Phone      string `gorm:"size:30"`
+	City       string `gorm:"not null;size:100"`
+	Street     string `gorm:"not null;size:255"`
+	PostalCode string `gorm:"size:20"`
+	IsDefault  bool   `gorm:"default:false"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import "gorm.io/gorm"
+
+// Статусы заказа
+const (
+	OrderStatusNew       = "new"
+	OrderStatusPaid      = "paid"
+	OrderStatusShipped   = "shipped"
+	OrderStatusCompleted = "completed"
+	OrderStatusCanceled  = "canceled"
+)
+
+// Order заказ покупателя
+type Order struct {
+	gorm.Model
+	CustomerID *uint       `gorm:"index"`
+	Status     string      `gorm:"not null;size:20;default:new"`
+	Total      float64     `gorm:"not null"`
+	Discount   float64     `gorm:"not null;default:0"`
+	PromoCode  string      `gorm:"size:50"`
+	Address    string      `gorm:"size:500"`
+	Items      []OrderItem `gorm:"foreignKey:OrderID"`
+}
+
+// OrderItem позиция заказа. Название и цена фиксируются на момент покупки
+type OrderItem struct {
+	gorm.Model
+	OrderID   uint    `gorm:"not null;index"`
+	ProductID uint    `gorm:"not null"`
+	Name      string  `gorm:"not null;size:255"`
+	Price     float64 `gorm:"not null"`
+	Quantity  int     `gorm:"not null"`
+
+	Product Product
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// PriceHistory запись об изменении цены продукта
+type PriceHistory struct {
+	ID           uint `gorm:"primarykey"`
+	ProductID    uint `gorm:"not null;index"`
+	OldPrice     float64
+	NewPrice     float64
+	OldSalePrice float64
+	NewSalePrice float64
+	Reason       string `gorm:"size:255"`
+	CreatedAt    time.Time
+}
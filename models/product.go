@@ -1,12 +1,26 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SEO метаданные страницы, заданные вручную.
+// Пустые поля заменяются значениями, сгенерированными пакетом seo
+type SEO struct {
+	MetaTitle       string `gorm:"size:255"`
+	MetaDescription string `gorm:"size:500"`
+	OGImage         string `gorm:"size:255"`
+}
 
 type Brand struct {
 	gorm.Model
 	Name     string    `gorm:"unique;not null;size:100"`
 	Slug     string    `gorm:"unique;not null;size:110"`
 	Products []Product `gorm:"foreignKey:BrandID"`
+
+	SEO
 }
 
 type Category struct {
@@ -14,25 +28,66 @@ type Category struct {
 	Name          string        `gorm:"unique;not null;size:100"`
 	Slug          string        `gorm:"unique;not null;size:110"`
 	Subcategories []Subcategory `gorm:"foreignKey:CategoryID"`
+
+	SEO
 }
 
+// Subcategory узел дерева категорий. Подкатегории верхнего уровня
+// не имеют родителя, вложенные ссылаются на него через ParentID.
+// Path хранит слаги от корня до узла через "/" и вычисляется автоматически
 type Subcategory struct {
 	gorm.Model
-	Name       string    `gorm:"not null;size:100"`
-	Slug       string    `gorm:"not null;size:110"`
-	CategoryID uint      `gorm:"not null"`
-	Products   []Product `gorm:"foreignKey:SubcategoryID"`
+	Name       string        `gorm:"not null;size:100"`
+	Slug       string        `gorm:"not null;size:110"`
+	Path       string        `gorm:"not null;size:500;uniqueIndex:idx_subcategories_category_path,priority:2"`
+	CategoryID uint          `gorm:"not null;uniqueIndex:idx_subcategories_category_path,priority:1"`
+	ParentID   *uint         `gorm:"index"`
+	Children   []Subcategory `gorm:"foreignKey:ParentID"`
+	Products   []Product     `gorm:"foreignKey:SubcategoryID"`
+
+	SEO
+	Category Category
+
+	// Значения до изменения, нужны для каскадного обновления потомков
+	oldPath       string
+	oldCategoryID uint
 }
 
 type Product struct {
 	gorm.Model
 	Name          string  `gorm:"not null;size:255"`
-	Slug          string  `gorm:"not null;size:265"`
+	Slug          string  `gorm:"unique;not null;size:265"`
+	SKU           string  `gorm:"size:64;index"` // Артикул, по нему сверяется импорт ассортимента
 	BrandID       uint    `gorm:"not null"`
 	SubcategoryID uint    `gorm:"not null"`
 	Price         float64 `gorm:"not null"`
 	ImagePath     string  `gorm:"not null;size:255"`
 	Description   string  `gorm:"type:text"`
+	Volume        string  `gorm:"size:50"`
+	Ingredients   string  `gorm:"type:text"`
 	IsOnSale      bool    `gorm:"default:false"`
 	SalePrice     float64
+	SaleStartedAt *time.Time
+
+	SEO
+	Brand       Brand
+	Subcategory Subcategory
+
+	saleStarted bool // Продукт только что попал в акцию, см. trackSale
+}
+
+// URL возвращает адрес страницы бренда
+func (b Brand) URL() string {
+	return "/catalog/brands/" + b.Slug
+}
+
+// URL возвращает адрес страницы категории
+func (c Category) URL() string {
+	return "/catalog/" + c.Slug
+}
+
+// URL возвращает адрес страницы продукта.
+// Подкатегория продукта должна быть загружена вместе с категорией
+func (p Product) URL() string {
+	return p.Subcategory.URL() + "/" + p.Slug
 }
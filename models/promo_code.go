@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Типы скидки промокода
+const (
+	DiscountPercent = "percent"
+	DiscountFixed   = "fixed"
+)
+
+// PromoCode промокод на скидку в корзине.
+// Нулевые лимиты означают отсутствие ограничения,
+// пустые списки брендов и категорий — действие на весь каталог
+type PromoCode struct {
+	gorm.Model
+	Code               string  `gorm:"unique;not null;size:50"`
+	DiscountType       string  `gorm:"not null;size:10"`
+	DiscountValue      float64 `gorm:"not null"`
+	MinCartTotal       float64 `gorm:"not null;default:0"`
+	MaxUses            int     `gorm:"not null;default:0"`
+	MaxUsesPerCustomer int     `gorm:"not null;default:0"`
+	ValidFrom          *time.Time
+	ValidUntil         *time.Time
+	IsActive           bool       `gorm:"default:true"`
+	Brands             []Brand    `gorm:"many2many:promo_code_brands"`
+	Categories         []Category `gorm:"many2many:promo_code_categories"`
+}
+
+// PromoRedemption факт использования промокода в заказе
+type PromoRedemption struct {
+	gorm.Model
+	PromoCodeID uint    `gorm:"not null;index"`
+	CustomerID  *uint   `gorm:"index"`
+	OrderID     uint    `gorm:"not null"`
+	Discount    float64 `gorm:"not null"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import "gorm.io/gorm"
+
+// Review отзыв покупателя о продукте
+type Review struct {
+	gorm.Model
+	ProductID  uint   `gorm:"not null;index"`
+	CustomerID *uint  `gorm:"index"`
+	AuthorName string `gorm:"not null;size:100"`
+	Rating     int    `gorm:"not null"`
+	Text       string `gorm:"type:text"`
+
+	Product Product
+}
+
+// Rating сводная оценка продукта по отзывам
+type Rating struct {
+	Average float64
+	Count   int64
+}
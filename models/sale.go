@@ -0,0 +1,84 @@
+package models
+
+import (
+	"context"
+	"cosmetics_catalog/database/aftercommit"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SaleHook функция, вызываемая после фиксации сохранения продукта, который только что попал в акцию
+type SaleHook func(product *Product)
+
+type saleHookKey struct{}
+
+// WithSaleHook возвращает контекст, в котором сохранение продукта, попавшего
+// в акцию, вызывает hook. Обработчики, добавленные в ctx раньше, тоже вызываются.
+// Контекст передается в подключение через db.WithContext, поэтому обработчик
+// срабатывает только для запросов этого подключения
+func WithSaleHook(ctx context.Context, hook SaleHook) context.Context {
+	if previous, ok := ctx.Value(saleHookKey{}).(SaleHook); ok {
+		next := hook
+		hook = func(product *Product) {
+			previous(product)
+			next(product)
+		}
+	}
+	return context.WithValue(ctx, saleHookKey{}, hook)
+}
+
+// trackSale сравнивает признак акции с сохраненным в базе. Запоминает дату
+// начала акции для ленты скидок и отмечает продукт, только что попавший в акцию.
+// Вызывается из BeforeSave, поэтому срабатывает при любом способе сохранения:
+// в репозитории, импорте и изменении цен
+func (p *Product) trackSale(tx *gorm.DB) error {
+	p.saleStarted = false
+	if !p.IsOnSale {
+		p.SaleStartedAt = nil
+		return nil
+	}
+
+	var current []Product
+	if p.ID != 0 {
+		err := tx.Session(&gorm.Session{NewDB: true}).
+			Model(&Product{}).
+			Select("is_on_sale", "sale_started_at").
+			Where("id = ?", p.ID).
+			Find(&current).
+			Error
+		if err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case len(current) == 0:
+		if p.SaleStartedAt == nil {
+			now := time.Now()
+			p.SaleStartedAt = &now
+		}
+	case !current[0].IsOnSale:
+		now := time.Now()
+		p.SaleStartedAt = &now
+		p.saleStarted = true
+	case p.SaleStartedAt == nil:
+		p.SaleStartedAt = current[0].SaleStartedAt
+	}
+	return nil
+}
+
+// AfterSave откладывает вызов обработчиков из контекста запроса до фиксации
+// транзакции, если продукт только что попал в акцию. Обработчики отправляют
+// письма, поэтому не вызываются для откаченных изменений и не держат транзакцию
+func (p *Product) AfterSave(tx *gorm.DB) error {
+	if !p.saleStarted {
+		return nil
+	}
+	p.saleStarted = false
+	if hook, ok := tx.Statement.Context.Value(saleHookKey{}).(SaleHook); ok {
+		product := *p
+		aftercommit.Do(tx, func() { hook(&product) })
+	}
+	return nil
+}
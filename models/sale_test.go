@@ -0,0 +1,82 @@
+package models
+
+import (
+	"context"
+	"cosmetics_catalog/database/aftercommit"
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestSaleHookAfterCommit(t *testing.T) {
+	db := newTestDB(t)
+	aftercommit.Install(db)
+
+	products := []Product{{Name: "Крем", Price: 1000}, {Name: "Маска", Price: 500}}
+	if err := db.Create(&products).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	var notified []string
+	hook := func(product *Product) {
+		// Подключение одно: до фиксации транзакции запрос ждал бы его до таймаута
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		var saved Product
+		if err := db.WithContext(ctx).First(&saved, product.ID).Error; err != nil {
+			t.Errorf("обработчик вызван до фиксации транзакции: %v", err)
+		} else if !saved.IsOnSale {
+			t.Errorf("обработчик не видит акцию продукта %s", product.Name)
+		}
+		notified = append(notified, product.Name)
+	}
+	tx := db.WithContext(WithSaleHook(context.Background(), hook))
+
+	// Транзакция откатывается после сохранения первого продукта
+	failed := errors.New("ошибка второй строки")
+	err := tx.Transaction(func(tx *gorm.DB) error {
+		products[0].IsOnSale, products[0].SalePrice = true, 800
+		if err := tx.Save(&products[0]).Error; err != nil {
+			return err
+		}
+		return failed
+	})
+	if !errors.Is(err, failed) {
+		t.Fatalf("Transaction error = %v", err)
+	}
+	if len(notified) != 0 {
+		t.Fatalf("уведомления об откаченной акции: %v", notified)
+	}
+
+	products[0].IsOnSale, products[0].SalePrice = true, 800
+	products[1].IsOnSale, products[1].SalePrice = true, 400
+	err = tx.Transaction(func(tx *gorm.DB) error {
+		for i := range products {
+			if err := tx.Save(&products[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notified) != 2 {
+		t.Fatalf("уведомления после фиксации: %v", notified)
+	}
+
+	// Отдельное сохранение без явной транзакции уведомляет после своей фиксации
+	products[0].IsOnSale = false
+	if err := tx.Save(&products[0]).Error; err != nil {
+		t.Fatal(err)
+	}
+	products[0].IsOnSale = true
+	if err := tx.Save(&products[0]).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(notified) != 3 || notified[2] != "Крем" {
+		t.Errorf("уведомления = %v", notified)
+	}
+}
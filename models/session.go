@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// Session данные сессии посетителя, хранящиеся на сервере
+type Session struct {
+	ID        string `gorm:"primaryKey;size:64"`
+	Data      string `gorm:"type:text"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
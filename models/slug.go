@@ -0,0 +1,78 @@
+package models
+
+import (
+	"context"
+	"cosmetics_catalog/slug"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// ErrReservedSlug возвращается, если слаг категории занят служебным разделом каталога
+var ErrReservedSlug = errors.New("слаг зарезервирован служебным разделом каталога")
+
+type reservedSlugsKey struct{}
+
+// WithReservedCategorySlugs возвращает контекст, в котором слова slugs нельзя
+// использовать как слаги категорий, например "sales" и "brands" из адресов
+// /catalog/sales и /catalog/brands. Слова, зарезервированные в ctx раньше,
+// сохраняются. Контекст передается в подключение через db.WithContext
+func WithReservedCategorySlugs(ctx context.Context, slugs ...string) context.Context {
+	reserved := map[string]bool{}
+	for s := range reservedCategorySlugs(ctx) {
+		reserved[s] = true
+	}
+	for _, s := range slugs {
+		reserved[s] = true
+	}
+	return context.WithValue(ctx, reservedSlugsKey{}, reserved)
+}
+
+// IsReservedCategorySlug сообщает, зарезервировано ли слово в контексте
+func IsReservedCategorySlug(ctx context.Context, slug string) bool {
+	return reservedCategorySlugs(ctx)[slug]
+}
+
+func reservedCategorySlugs(ctx context.Context) map[string]bool {
+	reserved, _ := ctx.Value(reservedSlugsKey{}).(map[string]bool)
+	return reserved
+}
+
+// BeforeCreate генерирует слаг продукта из названия, если он не задан
+func (p *Product) BeforeCreate(tx *gorm.DB) (err error) {
+	if p.Slug == "" {
+		p.Slug, err = uniqueSlug(tx.Session(&gorm.Session{NewDB: true}).Model(&Product{}), p.Name, nil)
+	}
+	return err
+}
+
+// BeforeCreate генерирует слаг бренда из названия, если он не задан
+func (b *Brand) BeforeCreate(tx *gorm.DB) (err error) {
+	if b.Slug == "" {
+		b.Slug, err = uniqueSlug(tx.Session(&gorm.Session{NewDB: true}).Model(&Brand{}), b.Name, nil)
+	}
+	return err
+}
+
+// BeforeCreate генерирует слаг категории из названия, если он не задан
+func (c *Category) BeforeCreate(tx *gorm.DB) (err error) {
+	if c.Slug == "" {
+		c.Slug, err = uniqueSlug(tx.Session(&gorm.Session{NewDB: true}).Model(&Category{}), c.Name, reservedCategorySlugs(tx.Statement.Context))
+	}
+	return err
+}
+
+// uniqueSlug транслитерирует название и подбирает слаг, не занятый
+// другими записями запроса scope и не входящий в reserved. Удаленные записи
+// тоже учитываются, так как уникальный индекс распространяется и на них
+func uniqueSlug(scope *gorm.DB, name string, reserved map[string]bool) (string, error) {
+	return slug.Unique(slug.Make(name), func(candidate string) (bool, error) {
+		if reserved[candidate] {
+			return true, nil
+		}
+
+		var count int64
+		err := scope.Session(&gorm.Session{}).Unscoped().Where("slug = ?", candidate).Count(&count).Error
+		return count > 0, err
+	})
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Типы сущностей в истории слагов
+const (
+	SlugEntityProduct     = "product"
+	SlugEntityBrand       = "brand"
+	SlugEntityCategory    = "category"
+	SlugEntitySubcategory = "subcategory"
+)
+
+// SlugHistory прежний слаг сущности, по которому старые ссылки
+// перенаправляются на актуальный адрес. Для подкатегорий хранится
+// прежний путь и категория, в которой он находился
+type SlugHistory struct {
+	ID         uint   `gorm:"primaryKey"`
+	EntityType string `gorm:"not null;size:20;index:idx_slug_history_lookup"`
+	EntityID   uint   `gorm:"not null"`
+	Slug       string `gorm:"not null;size:500;index:idx_slug_history_lookup"`
+	CategoryID uint   `gorm:"not null;default:0"`
+	CreatedAt  time.Time
+}
+
+// BeforeSave записывает прежний слаг продукта в историю
+// и отмечает начало акции
+func (p *Product) BeforeSave(tx *gorm.DB) error {
+	if err := recordSlugChange(tx, &Product{}, SlugEntityProduct, p.ID, p.Slug); err != nil {
+		return err
+	}
+	return p.trackSale(tx)
+}
+
+// BeforeSave записывает прежний слаг бренда в историю
+func (b *Brand) BeforeSave(tx *gorm.DB) error {
+	return recordSlugChange(tx, &Brand{}, SlugEntityBrand, b.ID, b.Slug)
+}
+
+// BeforeSave проверяет, что слаг категории не зарезервирован,
+// и записывает прежний слаг в историю
+func (c *Category) BeforeSave(tx *gorm.DB) error {
+	if IsReservedCategorySlug(tx.Statement.Context, c.Slug) {
+		return ErrReservedSlug
+	}
+	return recordSlugChange(tx, &Category{}, SlugEntityCategory, c.ID, c.Slug)
+}
+
+// recordSlugChange сравнивает новый слаг с сохраненным в базе
+// и при изменении добавляет прежний слаг в историю
+func recordSlugChange(tx *gorm.DB, model interface{}, entityType string, id uint, slug string) error {
+	if id == 0 || slug == "" {
+		return nil
+	}
+
+	db := tx.Session(&gorm.Session{NewDB: true})
+
+	var old []string
+	if err := db.Model(model).Where("id = ?", id).Pluck("slug", &old).Error; err != nil {
+		return err
+	}
+	if len(old) == 0 || old[0] == slug {
+		return nil
+	}
+
+	return db.Create(&SlugHistory{EntityType: entityType, EntityID: id, Slug: old[0]}).Error
+}
@@ -0,0 +1,141 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestDB открывает пустую базу SQLite в памяти с таблицами каталога
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// У каждого подключения к :memory: своя база, поэтому оно должно быть одно
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := db.AutoMigrate(&Brand{}, &Category{}, &Subcategory{}, &Product{}, &SlugHistory{}); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestUniqueSlug(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing []string
+		deleted  []string
+		reserved map[string]bool
+		want     string
+	}{
+		{name: "Крем", want: "krem"},
+		{name: "Крем", existing: []string{"krem"}, want: "krem-2"},
+		{name: "Крем", existing: []string{"krem", "krem-2"}, want: "krem-3"},
+		{name: "Крем", existing: []string{"krem-2"}, want: "krem"},
+		{name: "Крем", deleted: []string{"krem"}, want: "krem-2"},
+		{name: "Sales", reserved: map[string]bool{"sales": true}, want: "sales-2"},
+		{name: "Sales", existing: []string{"sales-2"}, reserved: map[string]bool{"sales": true}, want: "sales-3"},
+		{name: "!!!", want: "item"},
+		{name: "!!!", existing: []string{"item"}, want: "item-2"},
+	}
+	for _, tt := range tests {
+		db := newTestDB(t)
+		for i, s := range append(tt.existing, tt.deleted...) {
+			brand := Brand{Name: "Бренд " + string(rune('A'+i)), Slug: s}
+			if err := db.Create(&brand).Error; err != nil {
+				t.Fatal(err)
+			}
+			if i >= len(tt.existing) {
+				if err := db.Delete(&brand).Error; err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+
+		got, err := uniqueSlug(db.Model(&Brand{}), tt.name, tt.reserved)
+		if err != nil {
+			t.Fatalf("uniqueSlug(%q): %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Errorf("uniqueSlug(%q) при занятых %v и удаленных %v = %q, ожидалось %q",
+				tt.name, tt.existing, tt.deleted, got, tt.want)
+		}
+	}
+}
+
+func TestCreateGeneratesUniqueSlugs(t *testing.T) {
+	db := newTestDB(t)
+	category := Category{Name: "Уход", Subcategories: []Subcategory{{Name: "Очищение"}}}
+	if err := db.Create(&category).Error; err != nil {
+		t.Fatal(err)
+	}
+	brand := Brand{Name: "Bioderma"}
+	if err := db.Create(&brand).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"gel-dlya-umyvaniya", "gel-dlya-umyvaniya-2", "gel-dlya-umyvaniya-3"}
+	for _, slug := range want {
+		product := Product{
+			Name:          "Гель для умывания",
+			BrandID:       brand.ID,
+			SubcategoryID: category.Subcategories[0].ID,
+			Price:         100,
+		}
+		if err := db.Create(&product).Error; err != nil {
+			t.Fatal(err)
+		}
+		if product.Slug != slug {
+			t.Errorf("слаг продукта %q, ожидался %q", product.Slug, slug)
+		}
+	}
+
+	if category.Slug != "uxod" {
+		t.Errorf("слаг категории %q, ожидался uxod", category.Slug)
+	}
+	if subcategory := category.Subcategories[0]; subcategory.Path != "ochishhenie" {
+		t.Errorf("путь подкатегории %q, ожидался ochishhenie", subcategory.Path)
+	}
+}
+
+func TestReservedCategorySlugs(t *testing.T) {
+	db := newTestDB(t)
+	ctx := WithReservedCategorySlugs(context.Background(), "sales")
+	ctx = WithReservedCategorySlugs(ctx, "brands")
+	reserved := db.WithContext(ctx)
+
+	category := Category{Name: "Sales"}
+	if err := reserved.Create(&category).Error; err != nil {
+		t.Fatal(err)
+	}
+	if category.Slug != "sales-2" {
+		t.Errorf("слаг категории %q, ожидался sales-2", category.Slug)
+	}
+
+	for _, slug := range []string{"sales", "brands"} {
+		category.Slug = slug
+		if err := reserved.Save(&category).Error; !errors.Is(err, ErrReservedSlug) {
+			t.Errorf("сохранение категории со слагом %q: %v, ожидалась ErrReservedSlug", slug, err)
+		}
+	}
+
+	// Без резерва в контексте подключения слово свободно
+	brands := Category{Name: "Brands"}
+	if err := db.Create(&brands).Error; err != nil {
+		t.Fatal(err)
+	}
+	if brands.Slug != "brands" {
+		t.Errorf("слаг категории %q, ожидался brands", brands.Slug)
+	}
+}
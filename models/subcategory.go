@@ -0,0 +1,108 @@
+package models
+
+import (
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ErrSubcategoryCycle возвращается при попытке сделать подкатегорию потомком самой себя
+var ErrSubcategoryCycle = errors.New("подкатегорию нельзя вложить в нее саму или в ее потомка")
+
+// URL возвращает адрес страницы подкатегории.
+// Категория подкатегории должна быть загружена
+func (s Subcategory) URL() string {
+	return "/catalog/" + s.Category.Slug + "/" + s.Path
+}
+
+// AncestorPaths возвращает пути всех предков подкатегории от корня
+func (s Subcategory) AncestorPaths() []string {
+	slugs := strings.Split(s.Path, "/")
+	paths := make([]string, 0, len(slugs)-1)
+	for i := 1; i < len(slugs); i++ {
+		paths = append(paths, strings.Join(slugs[:i], "/"))
+	}
+	return paths
+}
+
+// BeforeSave генерирует слаг, вычисляет путь подкатегории и наследует категорию от родителя
+func (s *Subcategory) BeforeSave(tx *gorm.DB) error {
+	db := tx.Session(&gorm.Session{NewDB: true})
+
+	// Слаг генерируется из названия и должен быть уникален среди соседних узлов
+	if s.Slug == "" {
+		siblings := db.Model(&Subcategory{}).Where("id <> ?", s.ID)
+		if s.ParentID != nil {
+			siblings = siblings.Where("parent_id = ?", *s.ParentID)
+		} else {
+			siblings = siblings.Where("category_id = ? AND parent_id IS NULL", s.CategoryID)
+		}
+
+		var err error
+		if s.Slug, err = uniqueSlug(siblings, s.Name, nil); err != nil {
+			return err
+		}
+	}
+
+	// Запоминаем прежние значения, чтобы обновить потомков после сохранения
+	if s.ID != 0 {
+		var old Subcategory
+		if err := db.Select("path", "category_id").First(&old, s.ID).Error; err == nil {
+			s.oldPath = old.Path
+			s.oldCategoryID = old.CategoryID
+		}
+	}
+
+	if s.ParentID == nil {
+		s.Path = s.Slug
+		return nil
+	}
+
+	var parent Subcategory
+	if err := db.First(&parent, *s.ParentID).Error; err != nil {
+		return err
+	}
+	if s.oldPath != "" && parent.CategoryID == s.oldCategoryID &&
+		(parent.ID == s.ID || strings.HasPrefix(parent.Path+"/", s.oldPath+"/")) {
+		return ErrSubcategoryCycle
+	}
+
+	s.CategoryID = parent.CategoryID
+	s.Path = parent.Path + "/" + s.Slug
+	return nil
+}
+
+// AfterSave запоминает прежний путь и обновляет пути и категорию потомков,
+// если узел переименован или перемещен
+func (s *Subcategory) AfterSave(tx *gorm.DB) error {
+	if s.oldPath == "" || (s.oldPath == s.Path && s.oldCategoryID == s.CategoryID) {
+		return nil
+	}
+
+	db := tx.Session(&gorm.Session{NewDB: true})
+
+	// Прежний путь остается в истории, чтобы старые ссылки вели на новый адрес
+	history := SlugHistory{
+		EntityType: SlugEntitySubcategory,
+		EntityID:   s.ID,
+		Slug:       s.oldPath,
+		CategoryID: s.oldCategoryID,
+	}
+	if err := db.Create(&history).Error; err != nil {
+		return err
+	}
+
+	var children []Subcategory
+	if err := db.Where("parent_id = ?", s.ID).Find(&children).Error; err != nil {
+		return err
+	}
+
+	// Сохранение каждого потомка вызывает те же хуки, поэтому обновление идет рекурсивно
+	for i := range children {
+		if err := db.Save(&children[i]).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
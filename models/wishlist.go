@@ -0,0 +1,14 @@
+package models
+
+import "gorm.io/gorm"
+
+// WishlistItem товар, добавленный посетителем в избранное.
+// Если посетитель вошел в аккаунт, запись привязывается и к покупателю
+type WishlistItem struct {
+	gorm.Model
+	SessionID  string `gorm:"not null;size:64;index"`
+	CustomerID *uint  `gorm:"index"`
+	ProductID  uint   `gorm:"not null;index"`
+	Product    Product
+	Customer   *Customer
+}
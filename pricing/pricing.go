@@ -0,0 +1,190 @@
+// Package pricing массово меняет цены продуктов: на процент или фиксированную
+// сумму, с округлением и записью в историю цен
+package pricing
+
+import (
+	"cosmetics_catalog/models"
+	"cosmetics_catalog/repositories"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Способы изменения цены
+const (
+	ModePercent = "percent"
+	ModeFixed   = "fixed"
+)
+
+// Правила округления новой цены
+const (
+	RoundCents = ""      // до копеек
+	RoundWhole = "whole" // до целых рублей
+	Round90    = "90"    // до ближайшей цены, оканчивающейся на .90
+	Round99    = "99"    // до ближайшей цены, оканчивающейся на .99
+)
+
+// Update массовое изменение цен
+type Update struct {
+	Filter   repositories.ProductFilter
+	Mode     string
+	Amount   float64 // процент или сумма в рублях, отрицательные значения снижают цены
+	Rounding string
+	// Reason причина изменения для истории цен. Если не задана, используется Describe
+	Reason string
+}
+
+// Line изменение цены одного продукта
+type Line struct {
+	Product      models.Product
+	OldPrice     float64
+	NewPrice     float64
+	OldSalePrice float64
+	NewSalePrice float64
+}
+
+// OnSale сообщает, меняется ли вместе с ценой цена со скидкой
+func (l Line) OnSale() bool {
+	return l.Product.IsOnSale && l.OldSalePrice > 0
+}
+
+// Validate проверяет параметры изменения
+func (u Update) Validate() error {
+	switch u.Mode {
+	case ModePercent:
+		if u.Amount <= -100 {
+			return errors.New("цену нельзя снизить на 100% и больше")
+		}
+	case ModeFixed:
+	default:
+		return fmt.Errorf("неизвестный способ изменения цены %q", u.Mode)
+	}
+	if u.Amount == 0 {
+		return errors.New("не задана величина изменения цены")
+	}
+	switch u.Rounding {
+	case RoundCents, RoundWhole, Round90, Round99:
+	default:
+		return fmt.Errorf("неизвестное правило округления %q", u.Rounding)
+	}
+	return nil
+}
+
+// Describe описывает изменение, например "+10%, округление до .99"
+func (u Update) Describe() string {
+	amount := strconv.FormatFloat(u.Amount, 'f', -1, 64)
+	if u.Amount > 0 {
+		amount = "+" + amount
+	}
+	if u.Mode == ModePercent {
+		amount += "%"
+	} else {
+		amount += " ₽"
+	}
+
+	switch u.Rounding {
+	case RoundWhole:
+		amount += ", округление до рублей"
+	case Round90, Round99:
+		amount += ", округление до ." + u.Rounding
+	}
+	return amount
+}
+
+// Price вычисляет новую цену
+func (u Update) Price(price float64) float64 {
+	if u.Mode == ModePercent {
+		price *= 1 + u.Amount/100
+	} else {
+		price += u.Amount
+	}
+
+	switch u.Rounding {
+	case RoundWhole:
+		return math.Round(price)
+	case Round90:
+		return roundEnding(price, 0.90)
+	case Round99:
+		return roundEnding(price, 0.99)
+	}
+	return roundCents(price)
+}
+
+// Preview выбирает продукты по фильтру и вычисляет новые цены без записи в базу.
+// Вместе с изменениями возвращается ошибка, если с такими ценами изменение не будет применено
+func Preview(products repositories.Products, update Update) ([]Line, error) {
+	if err := update.Validate(); err != nil {
+		return nil, err
+	}
+	selected, err := products.GetByFilter(update.Filter)
+	if err != nil {
+		return nil, err
+	}
+	return Plan(selected, update)
+}
+
+// Apply записывает цены из строк, вычисленных Preview или Plan, одной транзакцией
+// и добавляет изменения в историю цен. Если цены продуктов изменились
+// после расчета, ничего не меняется и возвращается repositories.ErrPriceChanged
+func Apply(products repositories.Products, update Update, lines []Line) error {
+	if err := update.Validate(); err != nil {
+		return err
+	}
+	reason := update.Reason
+	if reason == "" {
+		reason = update.Describe()
+	}
+
+	history := make([]models.PriceHistory, 0, len(lines))
+	for _, line := range lines {
+		history = append(history, models.PriceHistory{
+			ProductID:    line.Product.ID,
+			OldPrice:     line.OldPrice,
+			NewPrice:     line.NewPrice,
+			OldSalePrice: line.OldSalePrice,
+			NewSalePrice: line.NewSalePrice,
+			Reason:       reason,
+		})
+	}
+	return products.UpdatePrices(history)
+}
+
+// Plan вычисляет новые цены продуктов. Цена со скидкой меняется
+// по тем же правилам, если продукт в акции
+func Plan(products []models.Product, update Update) ([]Line, error) {
+	lines := make([]Line, 0, len(products))
+	var invalid []string
+	for _, product := range products {
+		line := Line{
+			Product:      product,
+			OldPrice:     product.Price,
+			NewPrice:     update.Price(product.Price),
+			OldSalePrice: product.SalePrice,
+			NewSalePrice: product.SalePrice,
+		}
+		if line.OnSale() {
+			line.NewSalePrice = update.Price(product.SalePrice)
+		}
+
+		if line.NewPrice <= 0 || (line.OnSale() && (line.NewSalePrice <= 0 || line.NewSalePrice >= line.NewPrice)) {
+			invalid = append(invalid, product.Slug)
+		}
+		lines = append(lines, line)
+	}
+
+	if len(invalid) > 0 {
+		return lines, fmt.Errorf("недопустимые цены после изменения у продуктов: %s", strings.Join(invalid, ", "))
+	}
+	return lines, nil
+}
+
+// roundEnding округляет цену до ближайшей, у которой копейки равны ending
+func roundEnding(price, ending float64) float64 {
+	return roundCents(math.Round(price-ending) + ending)
+}
+
+func roundCents(price float64) float64 {
+	return math.Round(price*100) / 100
+}
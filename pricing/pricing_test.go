@@ -0,0 +1,111 @@
+package pricing
+
+import (
+	"cosmetics_catalog/repositories"
+	"cosmetics_catalog/repositories/memory"
+	"cosmetics_catalog/repositories/repotest"
+	"errors"
+	"testing"
+)
+
+func TestUpdatePrice(t *testing.T) {
+	tests := []struct {
+		update Update
+		price  float64
+		want   float64
+	}{
+		{Update{Mode: ModePercent, Amount: 10}, 1000, 1100},
+		{Update{Mode: ModePercent, Amount: -15}, 999, 849.15},
+		{Update{Mode: ModePercent, Amount: 10, Rounding: RoundWhole}, 999, 1099},
+		{Update{Mode: ModeFixed, Amount: 100, Rounding: Round99}, 850, 949.99},
+		{Update{Mode: ModeFixed, Amount: -100, Rounding: Round90}, 850, 749.90},
+		{Update{Mode: ModePercent, Amount: 5, Rounding: Round99}, 1000, 1049.99},
+	}
+	for _, tt := range tests {
+		if got := tt.update.Price(tt.price); got != tt.want {
+			t.Errorf("%s: Price(%v) = %v, want %v", tt.update.Describe(), tt.price, got, tt.want)
+		}
+	}
+}
+
+// newCatalog каталог в памяти из repotest.Seed, в котором маска в акции
+func newCatalog(t *testing.T) (repositories.Catalog, *repotest.Fixture) {
+	t.Helper()
+	catalog := memory.NewCatalog().Repositories()
+	f := repotest.Seed(t, catalog)
+	f.Mask.IsOnSale = true
+	f.Mask.SalePrice = 400
+	if err := catalog.Products.Update(&f.Mask); err != nil {
+		t.Fatal(err)
+	}
+	return catalog, f
+}
+
+func TestApply(t *testing.T) {
+	catalog, f := newCatalog(t)
+
+	update := Update{Filter: repositories.ProductFilter{BrandID: f.Alpha.ID}, Mode: ModePercent, Amount: 10}
+	lines, err := Preview(catalog.Products, update)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 2 {
+		t.Fatalf("выбрано %d продуктов, want 2", len(lines))
+	}
+	if err := Apply(catalog.Products, update, lines); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[uint][2]float64{f.Cream.ID: {1100, 0}, f.Mask.ID: {550, 440}, f.Ink.ID: {700, 0}}
+	products, err := catalog.Products.GetAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, product := range products {
+		if prices := want[product.ID]; product.Price != prices[0] || product.SalePrice != prices[1] {
+			t.Errorf("%s: цены %v %v, want %v", product.Slug, product.Price, product.SalePrice, prices)
+		}
+	}
+}
+
+func TestApplyInvalid(t *testing.T) {
+	catalog, _ := newCatalog(t)
+
+	// Скидка в фиксированную сумму обнуляет цену маски со скидкой
+	update := Update{Mode: ModeFixed, Amount: -450}
+	if _, err := Preview(catalog.Products, update); err == nil {
+		t.Fatal("Preview с недопустимыми ценами должен вернуть ошибку")
+	}
+
+	if _, err := Preview(catalog.Products, Update{Mode: "double", Amount: 1}); err == nil {
+		t.Error("Preview с неизвестным способом должен вернуть ошибку")
+	}
+}
+
+func TestApplyPriceChanged(t *testing.T) {
+	catalog, f := newCatalog(t)
+
+	update := Update{Filter: repositories.ProductFilter{BrandID: f.Alpha.ID}, Mode: ModePercent, Amount: 10}
+	lines, err := Preview(catalog.Products, update)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Цену крема изменили после предпросмотра
+	cream := lines[0].Product
+	cream.Price = 1200
+	if err := catalog.Products.Update(&cream); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Apply(catalog.Products, update, lines); !errors.Is(err, repositories.ErrPriceChanged) {
+		t.Fatalf("Apply после изменения цены: %v, want ErrPriceChanged", err)
+	}
+	mask, err := catalog.Products.GetByID(f.Mask.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mask.Price != 500 {
+		t.Errorf("цена маски %v изменилась несмотря на ошибку", mask.Price)
+	}
+}
@@ -0,0 +1,185 @@
+package promo
+
+import (
+	"cosmetics_catalog/models"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Ошибки проверки промокода. Текст ошибок показывается покупателю
+var (
+	ErrNotFound      = errors.New("промокод не найден")
+	ErrInactive      = errors.New("промокод больше не действует")
+	ErrNotStarted    = errors.New("срок действия промокода еще не начался")
+	ErrExpired       = errors.New("срок действия промокода истек")
+	ErrUsageLimit    = errors.New("промокод уже использован максимальное количество раз")
+	ErrCustomerLimit = errors.New("вы уже использовали этот промокод")
+	ErrLoginRequired = errors.New("войдите в аккаунт, чтобы применить этот промокод")
+	ErrNotApplicable = errors.New("промокод не действует на товары в корзине")
+)
+
+// MinTotalError возвращается, если сумма корзины меньше минимальной для промокода
+type MinTotalError struct {
+	MinTotal float64
+}
+
+func (e *MinTotalError) Error() string {
+	return fmt.Sprintf("промокод действует при заказе от %.2f ₽", e.MinTotal)
+}
+
+// IsValidationError сообщает, что ошибка описывает причину отказа
+// в применении промокода, а не сбой при проверке
+func IsValidationError(err error) bool {
+	var minTotal *MinTotalError
+	return errors.As(err, &minTotal) ||
+		errors.Is(err, ErrNotFound) ||
+		errors.Is(err, ErrInactive) ||
+		errors.Is(err, ErrNotStarted) ||
+		errors.Is(err, ErrExpired) ||
+		errors.Is(err, ErrUsageLimit) ||
+		errors.Is(err, ErrCustomerLimit) ||
+		errors.Is(err, ErrLoginRequired) ||
+		errors.Is(err, ErrNotApplicable)
+}
+
+// Line позиция корзины. Продукт должен быть загружен вместе с брендом и подкатегорией
+type Line struct {
+	Product  models.Product
+	Quantity int
+}
+
+// UnitPrice возвращает цену единицы товара с учетом акции
+func (l Line) UnitPrice() float64 {
+	if l.Product.IsOnSale {
+		return l.Product.SalePrice
+	}
+	return l.Product.Price
+}
+
+// Total возвращает стоимость позиции
+func (l Line) Total() float64 {
+	return l.UnitPrice() * float64(l.Quantity)
+}
+
+// Usage сведения об использовании промокода
+type Usage struct {
+	Total      int64 // Сколько раз промокод использован всеми покупателями
+	CustomerID uint  // Покупатель, применяющий промокод; 0 для гостя
+	Customer   int64 // Сколько раз промокод использовал этот покупатель
+}
+
+// NormalizeCode приводит введенный промокод к виду, в котором он хранится
+func NormalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// Subtotal возвращает сумму корзины без скидки
+func Subtotal(lines []Line) float64 {
+	var total float64
+	for _, line := range lines {
+		total += line.Total()
+	}
+	return total
+}
+
+// Validate проверяет, можно ли применить промокод к корзине в момент now
+func Validate(code *models.PromoCode, lines []Line, usage Usage, now time.Time) error {
+	switch {
+	case !code.IsActive:
+		return ErrInactive
+	case code.ValidFrom != nil && now.Before(*code.ValidFrom):
+		return ErrNotStarted
+	case code.ValidUntil != nil && now.After(*code.ValidUntil):
+		return ErrExpired
+	}
+	if err := CheckLimits(code, usage); err != nil {
+		return err
+	}
+
+	if Subtotal(lines) < code.MinCartTotal {
+		return &MinTotalError{MinTotal: code.MinCartTotal}
+	}
+
+	if eligibleTotal(code, lines) == 0 {
+		return ErrNotApplicable
+	}
+	return nil
+}
+
+// CheckLimits проверяет ограничения на число использований промокода.
+// Перед оформлением заказа проверка повторяется в транзакции, так как
+// промокод могли использовать другие покупатели
+func CheckLimits(code *models.PromoCode, usage Usage) error {
+	switch {
+	case code.MaxUses > 0 && usage.Total >= int64(code.MaxUses):
+		return ErrUsageLimit
+	case code.MaxUsesPerCustomer > 0 && usage.CustomerID == 0:
+		return ErrLoginRequired
+	case code.MaxUsesPerCustomer > 0 && usage.Customer >= int64(code.MaxUsesPerCustomer):
+		return ErrCustomerLimit
+	}
+	return nil
+}
+
+// Discount рассчитывает скидку по промокоду для корзины.
+// Скидка действует только на товары разрешенных брендов и категорий
+// и не превышает их стоимость
+func Discount(code *models.PromoCode, lines []Line) float64 {
+	eligible := eligibleTotal(code, lines)
+
+	var discount float64
+	switch code.DiscountType {
+	case models.DiscountPercent:
+		discount = eligible * code.DiscountValue / 100
+	case models.DiscountFixed:
+		discount = code.DiscountValue
+	}
+
+	discount = math.Min(discount, eligible)
+	return math.Round(discount*100) / 100
+}
+
+// eligibleTotal возвращает стоимость позиций, на которые действует промокод
+func eligibleTotal(code *models.PromoCode, lines []Line) float64 {
+	var total float64
+	for _, line := range lines {
+		if appliesTo(code, line.Product) {
+			total += line.Total()
+		}
+	}
+	return total
+}
+
+// appliesTo проверяет ограничения промокода по бренду и категории
+func appliesTo(code *models.PromoCode, product models.Product) bool {
+	if len(code.Brands) > 0 {
+		found := false
+		for _, brand := range code.Brands {
+			if brand.ID == product.BrandID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(code.Categories) > 0 {
+		found := false
+		for _, category := range code.Categories {
+			if category.ID == product.Subcategory.CategoryID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"html/template"
+	"net/http"
+	"path/filepath"
+)
+
+// parseTemplate загружает шаблон из каталога шаблонов вместе с общими частями из partials
+// и подключает к нему общие функции. Функция url строит адрес именованного маршрута:
+// {{url "brand" "brand" .Slug}} дает /catalog/brands/{slug}
+func (app *App) parseTemplate(name string) (*template.Template, error) {
+	tmpl, err := template.New(name).
+		Funcs(template.FuncMap{"url": app.routes.URL}).
+		ParseFiles(filepath.Join(app.config.TemplateDir, name))
+	if err != nil {
+		return nil, err
+	}
+	return tmpl.ParseGlob(filepath.Join(app.config.TemplateDir, "partials", "*.html"))
+}
+
+// renderTemplate загружает шаблон и рендерит его с данными
+func (app *App) renderTemplate(w http.ResponseWriter, name string, data any) {
+	tmpl, err := app.parseTemplate(name)
+	if err != nil {
+		http.Error(w, "Ошибка загрузки шаблона", http.StatusInternalServerError)
+		return
+	}
+
+	if err := tmpl.Execute(w, data); err != nil {
+		http.Error(w, "Ошибка рендеринга", http.StatusInternalServerError)
+	}
+}
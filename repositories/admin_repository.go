@@ -0,0 +1,30 @@
+package repositories
+
+import (
+	"cosmetics_catalog/models"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type AdminRepository struct {
+	db *gorm.DB
+}
+
+// NewAdminRepository создает новый экземпляр репозитория администраторов
+func NewAdminRepository(db *gorm.DB) *AdminRepository {
+	return &AdminRepository{db: db}
+}
+
+// Create добавляет администратора. Логин хранится в нижнем регистре
+func (r *AdminRepository) Create(admin *models.AdminUser) error {
+	admin.Login = strings.ToLower(strings.TrimSpace(admin.Login))
+	return r.db.Create(admin).Error
+}
+
+// GetByLogin возвращает администратора по логину
+func (r *AdminRepository) GetByLogin(login string) (*models.AdminUser, error) {
+	var admin models.AdminUser
+	err := r.db.Where("login = ?", strings.ToLower(strings.TrimSpace(login))).First(&admin).Error
+	return &admin, err
+}
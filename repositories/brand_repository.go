@@ -0,0 +1,104 @@
+package repositories
+
+import (
+	"cosmetics_catalog/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BrandRepository struct {
+	db *gorm.DB
+}
+
+// NewBrandRepository создает новый экземпляр репозитория брендов
+func NewBrandRepository(db *gorm.DB) *BrandRepository {
+	return &BrandRepository{db: db}
+}
+
+// Create добавляет бренд. Слаг генерируется из названия, если не задан
+func (r *BrandRepository) Create(brand *models.Brand) error {
+	return r.db.Omit(clause.Associations).Create(brand).Error
+}
+
+// Update сохраняет название, слаг и SEO бренда. Продукты бренда не меняются,
+// прежний слаг попадает в историю
+func (r *BrandRepository) Update(brand *models.Brand) error {
+	if err := r.db.First(&models.Brand{}, brand.ID).Error; err != nil {
+		return err
+	}
+	return r.db.Omit(clause.Associations).Save(brand).Error
+}
+
+// Delete удаляет бренд без продуктов вместе с его ограничениями в промокодах.
+// Бренд с продуктами не удаляется, возвращается ErrBrandHasProducts
+func (r *BrandRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&models.Brand{}, id).Error; err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&models.Product{}).Where("brand_id = ?", id).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrBrandHasProducts
+		}
+
+		if err := tx.Exec("DELETE FROM promo_code_brands WHERE brand_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Brand{}, id).Error
+	})
+}
+
+// GetByID возвращает бренд по ID
+func (r *BrandRepository) GetByID(id uint) (*models.Brand, error) {
+	var brand models.Brand
+	err := r.db.First(&brand, id).Error
+	return &brand, err
+}
+
+// GetBySlug возвращает бренд по слагу вместе с продуктами
+func (r *BrandRepository) GetBySlug(slug string) (*models.Brand, error) {
+	var brand models.Brand
+	err := r.db.
+		Preload("Products", func(db *gorm.DB) *gorm.DB {
+			return db.Order("products.id")
+		}).
+		Preload("Products.Subcategory.Category").
+		Where("slug = ?", slug).
+		First(&brand).
+		Error
+	return &brand, err
+}
+
+// GetAll возвращает все бренды по алфавиту
+func (r *BrandRepository) GetAll() ([]models.Brand, error) {
+	var brands []models.Brand
+	err := r.db.Order("name").Find(&brands).Error
+	return brands, err
+}
+
+// List возвращает бренды с числом продуктов, по алфавиту
+// или, при order равном ListByProducts, начиная с самых больших
+func (r *BrandRepository) List(order string) ([]BrandItem, error) {
+	var items []BrandItem
+	err := r.db.
+		Model(&models.Brand{}).
+		Select("brands.*, COUNT(products.id) AS product_count").
+		Joins("LEFT JOIN products ON products.brand_id = brands.id AND products.deleted_at IS NULL").
+		Group("brands.id").
+		Order(listOrder(order, "brands", "name")).
+		Scan(&items).
+		Error
+	return items, err
+}
+
+// Search ищет бренды по названию без учета регистра, по алфавиту
+func (r *BrandRepository) Search(query string, limit int) ([]models.Brand, error) {
+	var brands []models.Brand
+	err := r.db.Scopes(nameContains(query), limitRows(limit)).Order("name").Find(&brands).Error
+	return brands, err
+}
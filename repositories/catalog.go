@@ -0,0 +1,147 @@
+package repositories
+
+import (
+	"cosmetics_catalog/models"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// Интерфейсы репозиториев каталога. Обработчики работают с каталогом только
+// через них, поэтому каталог можно хранить в базе через GORM или в памяти
+// (пакет repositories/memory). Если запись не найдена, методы возвращают
+// gorm.ErrRecordNotFound в обеих реализациях.
+// Через интерфейсы работают и выгрузка (пакет export), и изменение цен (пакет pricing).
+// Только импорт ассортимента командой import работает с базой напрямую: он
+// восстанавливает удаленные продукты и сохраняет файл одной транзакцией
+
+// Products репозиторий продуктов. Продукты возвращаются с брендом
+// и подкатегорией вместе с ее категорией, кроме GetByCategory и GetBySubcategory.
+// Параметр sort принимает значения "asc" и "desc" для сортировки по цене,
+// нулевые границы цены не ограничивают выборку.
+// GetByFilter возвращает продукты под ProductFilter по порядку добавления.
+// Batches передает в fn все продукты по порядку добавления пачками не больше size.
+// UpdatePrices меняет цены и записывает изменения в историю цен одной транзакцией:
+// если цена продукта уже не равна OldPrice или OldSalePrice, ничего не меняется
+// и возвращается ErrPriceChanged
+type Products interface {
+	Create(product *models.Product) error
+	Update(product *models.Product) error
+	Delete(id uint) error
+	GetByID(id uint) (*models.Product, error)
+	GetByIDs(ids []uint) ([]models.Product, error)
+	GetBySlug(slug string) (*models.Product, error)
+	GetAll() ([]models.Product, error)
+	GetByCategory(categoryID uint) ([]models.Product, error)
+	GetBySubcategory(subcategoryID uint) ([]models.Product, error)
+	GetBySubcategories(subcategoryIDs []uint, sort string, minPrice, maxPrice float64) ([]models.Product, error)
+	GetByBrand(brandID uint, sort string, minPrice, maxPrice float64) ([]models.Product, error)
+	GetOnSale(sort string, minPrice, maxPrice float64) ([]models.Product, error)
+	GetRecentSales(brandID uint, limit int) ([]models.Product, error)
+	GetNewArrivals(brandID uint, limit int) ([]models.Product, error)
+	SearchByName(query string, limit, offset int) ([]models.Product, error)
+	GetByFilter(filter ProductFilter) ([]models.Product, error)
+	Batches(size int, fn func(products []models.Product) error) error
+	UpdatePrices(changes []models.PriceHistory) error
+	OnSale(hook ProductHook)
+}
+
+// Brands репозиторий брендов. Порядок List задается константами
+// ListByName (по умолчанию) и ListByProducts
+type Brands interface {
+	Create(brand *models.Brand) error
+	Update(brand *models.Brand) error
+	Delete(id uint) error
+	GetByID(id uint) (*models.Brand, error)
+	GetBySlug(slug string) (*models.Brand, error)
+	GetAll() ([]models.Brand, error)
+	List(order string) ([]BrandItem, error)
+	Search(query string, limit int) ([]models.Brand, error)
+}
+
+// Categories репозиторий категорий. List по умолчанию возвращает категории
+// в порядке добавления, порядок меняется константами ListByName и ListByProducts
+type Categories interface {
+	Create(category *models.Category) error
+	Update(category *models.Category) error
+	Delete(id uint) error
+	GetByID(id uint) (*models.Category, error)
+	GetBySlug(slug string) (*models.Category, error)
+	GetAll() ([]models.Category, error)
+	List(order string) ([]CategoryItem, error)
+	Search(query string, limit int) ([]models.Category, error)
+	// Reserve запрещает использовать слова как слаги категорий: Create подбирает
+	// другой слаг, а Create и Update с таким слагом возвращают models.ErrReservedSlug
+	Reserve(slugs ...string)
+	// IsReserved сообщает, запрещено ли слово как слаг категории
+	IsReserved(slug string) bool
+}
+
+// Subcategories репозиторий дерева подкатегорий
+type Subcategories interface {
+	Create(subcategory *models.Subcategory) error
+	Update(subcategory *models.Subcategory) error
+	GetByID(id uint) (*models.Subcategory, error)
+	GetByPath(categoryID uint, path string) (*models.Subcategory, error)
+	GetAll() ([]models.Subcategory, error)
+	GetRoots(categoryID uint) ([]models.Subcategory, error)
+	GetAncestors(subcategory *models.Subcategory) ([]models.Subcategory, error)
+	GetDescendantIDs(subcategory *models.Subcategory) ([]uint, error)
+}
+
+// ProductFilter выбирает продукты для массовых операций. Нулевые поля не ограничивают выборку
+type ProductFilter struct {
+	BrandID    uint
+	CategoryID uint
+	// SubcategoryIDs подкатегория вместе с потомками
+	SubcategoryIDs []uint
+	// OnSale только продукты в акции
+	OnSale bool
+}
+
+// BrandItem бренд в списке вместе с числом его продуктов
+type BrandItem struct {
+	models.Brand
+	ProductCount int
+}
+
+// CategoryItem категория в списке вместе с числом продуктов во всех ее подкатегориях
+type CategoryItem struct {
+	models.Category
+	ProductCount int
+}
+
+// Ошибки удаления: бренды и категории с продуктами не удаляются,
+// продукты нужно сначала удалить или перенести
+var (
+	ErrBrandHasProducts    = errors.New("у бренда есть продукты, сначала удалите или перенесите их")
+	ErrCategoryHasProducts = errors.New("в категории есть продукты, сначала удалите или перенесите их")
+)
+
+// ErrPriceChanged цена продукта изменилась после расчета новых цен
+var ErrPriceChanged = errors.New("цены продуктов изменились, пересчитайте изменение")
+
+// Catalog репозитории каталога, работающие с одним хранилищем
+type Catalog struct {
+	Products      Products
+	Brands        Brands
+	Categories    Categories
+	Subcategories Subcategories
+}
+
+// NewCatalog возвращает репозитории каталога в базе
+func NewCatalog(db *gorm.DB) Catalog {
+	return Catalog{
+		Products:      NewProductRepository(db),
+		Brands:        NewBrandRepository(db),
+		Categories:    NewCategoryRepository(db),
+		Subcategories: NewSubcategoryRepository(db),
+	}
+}
+
+var (
+	_ Products      = (*ProductRepository)(nil)
+	_ Brands        = (*BrandRepository)(nil)
+	_ Categories    = (*CategoryRepository)(nil)
+	_ Subcategories = (*SubcategoryRepository)(nil)
+)
@@ -0,0 +1,501 @@
+package repositories_test
+
+import (
+	"cosmetics_catalog/database/dbtest"
+	"cosmetics_catalog/models"
+	"cosmetics_catalog/repositories"
+	"cosmetics_catalog/repositories/memory"
+	"cosmetics_catalog/repositories/repotest"
+	"errors"
+	"slices"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// Контрактные тесты: одни и те же проверки выполняются для каталога
+// в памяти и для репозиториев GORM в SQLite и PostgreSQL, чтобы реализации
+// вели себя одинаково. PostgreSQL подключается через dbtest.PostgresDSN
+
+// storage способ открыть пустой каталог
+type storage struct {
+	name string
+	open func(t *testing.T) repositories.Catalog
+}
+
+func storages() []storage {
+	list := []storage{{name: "memory", open: func(*testing.T) repositories.Catalog {
+		return memory.NewCatalog().Repositories()
+	}}}
+	for _, driver := range dbtest.Drivers {
+		list = append(list, storage{name: driver, open: func(t *testing.T) repositories.Catalog {
+			return repositories.NewCatalog(dbtest.OpenMigrated(t, driver))
+		}})
+	}
+	return list
+}
+
+// forEachStorage запускает тест для каждой реализации каталога
+func forEachStorage(t *testing.T, test func(t *testing.T, catalog repositories.Catalog)) {
+	for _, s := range storages() {
+		t.Run(s.name, func(t *testing.T) {
+			test(t, s.open(t))
+		})
+	}
+}
+
+func mustOK(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func productIDs(products []models.Product) []uint {
+	ids := make([]uint, 0, len(products))
+	for _, product := range products {
+		ids = append(ids, product.ID)
+	}
+	return ids
+}
+
+func TestProductsGet(t *testing.T) {
+	forEachStorage(t, func(t *testing.T, catalog repositories.Catalog) {
+		f := repotest.Seed(t, catalog)
+
+		product, err := catalog.Products.GetBySlug(f.Cream.Slug)
+		mustOK(t, err)
+		if product.ID != f.Cream.ID || product.Brand.Name != "Альфа" || product.Subcategory.Category.Name != "Уход" {
+			t.Errorf("GetBySlug(%q) = %+v, ожидался продукт с брендом и категорией", f.Cream.Slug, product)
+		}
+		if want := "/catalog/" + f.Care.Slug + "/" + f.Creams.Path + "/" + f.Cream.Slug; product.URL() != want {
+			t.Errorf("URL() = %q, want %q", product.URL(), want)
+		}
+
+		if _, err := catalog.Products.GetByID(9999); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Errorf("GetByID(9999) error = %v, ожидалась gorm.ErrRecordNotFound", err)
+		}
+		if _, err := catalog.Products.GetBySlug("net-takogo"); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Errorf("GetBySlug error = %v, ожидалась gorm.ErrRecordNotFound", err)
+		}
+
+		products, err := catalog.Products.GetByIDs([]uint{f.Ink.ID, 9999, f.Cream.ID})
+		mustOK(t, err)
+		if got, want := productIDs(products), []uint{f.Ink.ID, f.Cream.ID}; !slices.Equal(got, want) {
+			t.Errorf("GetByIDs = %v, want %v", got, want)
+		}
+
+		products, err = catalog.Products.GetByCategory(f.Care.ID)
+		mustOK(t, err)
+		if got, want := productIDs(products), []uint{f.Cream.ID, f.Mask.ID}; !slices.Equal(got, want) {
+			t.Errorf("GetByCategory = %v, want %v", got, want)
+		}
+
+		products, err = catalog.Products.GetByBrand(f.Alpha.ID, "asc", 0, 0)
+		mustOK(t, err)
+		if got, want := productIDs(products), []uint{f.Mask.ID, f.Cream.ID}; !slices.Equal(got, want) {
+			t.Errorf("GetByBrand по возрастанию цены = %v, want %v", got, want)
+		}
+	})
+}
+
+func TestProductsCreateSlug(t *testing.T) {
+	forEachStorage(t, func(t *testing.T, catalog repositories.Catalog) {
+		f := repotest.Seed(t, catalog)
+
+		twin := models.Product{Name: f.Cream.Name, BrandID: f.Beta.ID, SubcategoryID: f.Creams.ID, Price: 900}
+		mustOK(t, catalog.Products.Create(&twin))
+		if twin.Slug != f.Cream.Slug+"-2" {
+			t.Errorf("слаг продукта с тем же названием = %q, want %q", twin.Slug, f.Cream.Slug+"-2")
+		}
+
+		duplicate := models.Product{Name: "Другой", Slug: f.Cream.Slug, BrandID: f.Beta.ID, SubcategoryID: f.Creams.ID, Price: 900}
+		if err := catalog.Products.Create(&duplicate); err == nil {
+			t.Error("Create с занятым слагом должен вернуть ошибку")
+		}
+	})
+}
+
+func TestProductsSearchByName(t *testing.T) {
+	forEachStorage(t, func(t *testing.T, catalog repositories.Catalog) {
+		f := repotest.Seed(t, catalog)
+
+		tests := []struct {
+			query string
+			want  []uint
+		}{
+			{"крем", []uint{f.Cream.ID, f.Mask.ID}},
+			{"КРЕМ", []uint{f.Cream.ID, f.Mask.ID}},
+			{"Крем", []uint{f.Cream.ID, f.Mask.ID}},
+			{"тушь", []uint{f.Ink.ID}},
+			{"100%", []uint{f.Ink.ID}},
+			{"%", []uint{f.Ink.ID}},
+			{"_", nil},
+			{"пудра", nil},
+		}
+		for _, tt := range tests {
+			products, err := catalog.Products.SearchByName(tt.query, 10, 0)
+			mustOK(t, err)
+			got := productIDs(products)
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("SearchByName(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		}
+
+		brands, err := catalog.Brands.Search("АЛЬФ", 10)
+		mustOK(t, err)
+		if len(brands) != 1 || brands[0].ID != f.Alpha.ID {
+			t.Errorf("Brands.Search(АЛЬФ) = %+v", brands)
+		}
+		categories, err := catalog.Categories.Search("уход", 10)
+		mustOK(t, err)
+		if len(categories) != 1 || categories[0].ID != f.Care.ID {
+			t.Errorf("Categories.Search(уход) = %+v", categories)
+		}
+	})
+}
+
+func TestBrandsList(t *testing.T) {
+	forEachStorage(t, func(t *testing.T, catalog repositories.Catalog) {
+		f := repotest.Seed(t, catalog)
+
+		tests := []struct {
+			order string
+			names []string
+			count []int
+		}{
+			{order: "", names: []string{"Альфа", "Бета", "Гамма"}, count: []int{2, 1, 0}},
+			{order: repositories.ListByName, names: []string{"Альфа", "Бета", "Гамма"}, count: []int{2, 1, 0}},
+			{order: repositories.ListByProducts, names: []string{"Альфа", "Бета", "Гамма"}, count: []int{2, 1, 0}},
+		}
+		for _, tt := range tests {
+			items, err := catalog.Brands.List(tt.order)
+			mustOK(t, err)
+			checkBrandItems(t, tt.order, items, tt.names, tt.count)
+		}
+
+		// Удаленные продукты не учитываются, при равенстве бренды идут по алфавиту
+		mustOK(t, catalog.Products.Delete(f.Cream.ID))
+		items, err := catalog.Brands.List(repositories.ListByProducts)
+		mustOK(t, err)
+		checkBrandItems(t, "после удаления", items, []string{"Альфа", "Бета", "Гамма"}, []int{1, 1, 0})
+
+		mustOK(t, catalog.Products.Delete(f.Mask.ID))
+		items, err = catalog.Brands.List(repositories.ListByProducts)
+		mustOK(t, err)
+		checkBrandItems(t, "после удаления", items, []string{"Бета", "Альфа", "Гамма"}, []int{1, 0, 0})
+	})
+}
+
+func checkBrandItems(t *testing.T, order string, items []repositories.BrandItem, names []string, count []int) {
+	t.Helper()
+	var gotNames []string
+	var gotCount []int
+	for _, item := range items {
+		gotNames = append(gotNames, item.Name)
+		gotCount = append(gotCount, item.ProductCount)
+	}
+	if !slices.Equal(gotNames, names) || !slices.Equal(gotCount, count) {
+		t.Errorf("Brands.List(%q) = %v %v, want %v %v", order, gotNames, gotCount, names, count)
+	}
+}
+
+func TestCategoriesList(t *testing.T) {
+	forEachStorage(t, func(t *testing.T, catalog repositories.Catalog) {
+		f := repotest.Seed(t, catalog)
+		empty := models.Category{Name: "Аксессуары"}
+		mustOK(t, catalog.Categories.Create(&empty))
+
+		tests := []struct {
+			order string
+			ids   []uint
+			count []int
+		}{
+			{order: "", ids: []uint{f.Care.ID, f.Makeup.ID, empty.ID}, count: []int{2, 1, 0}},
+			{order: repositories.ListByName, ids: []uint{empty.ID, f.Makeup.ID, f.Care.ID}, count: []int{0, 1, 2}},
+			{order: repositories.ListByProducts, ids: []uint{f.Care.ID, f.Makeup.ID, empty.ID}, count: []int{2, 1, 0}},
+		}
+		for _, tt := range tests {
+			items, err := catalog.Categories.List(tt.order)
+			mustOK(t, err)
+			var ids []uint
+			var count []int
+			for _, item := range items {
+				ids = append(ids, item.ID)
+				count = append(count, item.ProductCount)
+			}
+			if !slices.Equal(ids, tt.ids) || !slices.Equal(count, tt.count) {
+				t.Errorf("Categories.List(%q) = %v %v, want %v %v", tt.order, ids, count, tt.ids, tt.count)
+			}
+		}
+	})
+}
+
+func TestCategoriesReserve(t *testing.T) {
+	forEachStorage(t, func(t *testing.T, catalog repositories.Catalog) {
+		catalog.Categories.Reserve("sales", "brands")
+		if !catalog.Categories.IsReserved("sales") || catalog.Categories.IsReserved("uxod") {
+			t.Error("IsReserved не совпадает с зарезервированными словами")
+		}
+
+		sales := models.Category{Name: "Sales"}
+		mustOK(t, catalog.Categories.Create(&sales))
+		if sales.Slug != "sales-2" {
+			t.Errorf("слаг категории %q, ожидался sales-2", sales.Slug)
+		}
+		if err := catalog.Categories.Create(&models.Category{Name: "Бренды", Slug: "brands"}); !errors.Is(err, models.ErrReservedSlug) {
+			t.Errorf("Create с зарезервированным слагом error = %v", err)
+		}
+		sales.Slug = "brands"
+		if err := catalog.Categories.Update(&sales); !errors.Is(err, models.ErrReservedSlug) {
+			t.Errorf("Update с зарезервированным слагом error = %v", err)
+		}
+	})
+}
+
+func TestDeleteRules(t *testing.T) {
+	forEachStorage(t, func(t *testing.T, catalog repositories.Catalog) {
+		f := repotest.Seed(t, catalog)
+
+		if err := catalog.Brands.Delete(f.Alpha.ID); !errors.Is(err, repositories.ErrBrandHasProducts) {
+			t.Errorf("Brands.Delete бренда с продуктами error = %v", err)
+		}
+		if err := catalog.Categories.Delete(f.Care.ID); !errors.Is(err, repositories.ErrCategoryHasProducts) {
+			t.Errorf("Categories.Delete категории с продуктами error = %v", err)
+		}
+
+		mustOK(t, catalog.Brands.Delete(f.Gamma.ID))
+		if _, err := catalog.Brands.GetByID(f.Gamma.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Errorf("GetByID удаленного бренда error = %v", err)
+		}
+		if err := catalog.Brands.Delete(f.Gamma.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Errorf("повторный Delete error = %v", err)
+		}
+		if err := catalog.Products.Delete(9999); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Errorf("Products.Delete(9999) error = %v", err)
+		}
+
+		mustOK(t, catalog.Products.Delete(f.Ink.ID))
+		mustOK(t, catalog.Brands.Delete(f.Beta.ID))
+		mustOK(t, catalog.Categories.Delete(f.Makeup.ID))
+	})
+}
+
+func TestSubcategoriesTree(t *testing.T) {
+	forEachStorage(t, func(t *testing.T, catalog repositories.Catalog) {
+		f := repotest.Seed(t, catalog)
+
+		if f.Creams.Path != f.Face.Path+"/"+f.Creams.Slug {
+			t.Errorf("путь вложенной подкатегории = %q, родитель %q", f.Creams.Path, f.Face.Path)
+		}
+
+		found, err := catalog.Subcategories.GetByPath(f.Care.ID, f.Creams.Path)
+		mustOK(t, err)
+		if found.ID != f.Creams.ID || found.Category.ID != f.Care.ID {
+			t.Errorf("GetByPath(%q) = %+v", f.Creams.Path, found)
+		}
+		if _, err := catalog.Subcategories.GetByPath(f.Makeup.ID, f.Creams.Path); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Errorf("GetByPath в чужой категории error = %v", err)
+		}
+
+		ancestors, err := catalog.Subcategories.GetAncestors(&f.Creams)
+		mustOK(t, err)
+		if len(ancestors) != 1 || ancestors[0].ID != f.Face.ID {
+			t.Errorf("GetAncestors = %+v", ancestors)
+		}
+
+		ids, err := catalog.Subcategories.GetDescendantIDs(&f.Face)
+		mustOK(t, err)
+		slices.Sort(ids)
+		if !slices.Equal(ids, []uint{f.Face.ID, f.Creams.ID}) {
+			t.Errorf("GetDescendantIDs = %v", ids)
+		}
+
+		roots, err := catalog.Subcategories.GetRoots(f.Care.ID)
+		mustOK(t, err)
+		if len(roots) != 1 || roots[0].ID != f.Face.ID {
+			t.Errorf("GetRoots = %+v", roots)
+		}
+
+		face := f.Face
+		face.ParentID = &f.Creams.ID
+		if err := catalog.Subcategories.Update(&face); !errors.Is(err, models.ErrSubcategoryCycle) {
+			t.Errorf("Update с циклом error = %v", err)
+		}
+
+		// Переименование узла меняет пути потомков
+		face = f.Face
+		face.Slug = "lico-i-shea"
+		mustOK(t, catalog.Subcategories.Update(&face))
+		creams, err := catalog.Subcategories.GetByID(f.Creams.ID)
+		mustOK(t, err)
+		if creams.Path != "lico-i-shea/"+f.Creams.Slug {
+			t.Errorf("путь потомка после переименования = %q", creams.Path)
+		}
+	})
+}
+
+func TestProductsOnSale(t *testing.T) {
+	forEachStorage(t, func(t *testing.T, catalog repositories.Catalog) {
+		f := repotest.Seed(t, catalog)
+
+		var notified []uint
+		catalog.Products.OnSale(func(product *models.Product) {
+			notified = append(notified, product.ID)
+		})
+
+		product, err := catalog.Products.GetByID(f.Cream.ID)
+		mustOK(t, err)
+		product.IsOnSale = true
+		product.SalePrice = 800
+		mustOK(t, catalog.Products.Update(product))
+		if product.SaleStartedAt == nil {
+			t.Error("дата начала акции не заполнена")
+		}
+
+		// Повторное сохранение продукта в акции не уведомляет подписчиков
+		product, err = catalog.Products.GetByID(f.Cream.ID)
+		mustOK(t, err)
+		product.SalePrice = 750
+		mustOK(t, catalog.Products.Update(product))
+		if !slices.Equal(notified, []uint{f.Cream.ID}) {
+			t.Errorf("уведомления = %v, want %v", notified, []uint{f.Cream.ID})
+		}
+
+		onSale, err := catalog.Products.GetOnSale("", 0, 0)
+		mustOK(t, err)
+		if !slices.Equal(productIDs(onSale), []uint{f.Cream.ID}) {
+			t.Errorf("GetOnSale = %v", productIDs(onSale))
+		}
+
+		product.IsOnSale = false
+		mustOK(t, catalog.Products.Update(product))
+		product, err = catalog.Products.GetByID(f.Cream.ID)
+		mustOK(t, err)
+		if product.SaleStartedAt != nil {
+			t.Error("дата начала акции не сброшена после окончания акции")
+		}
+
+		missing := models.Product{Name: "Нет", BrandID: f.Alpha.ID, SubcategoryID: f.Face.ID, Price: 1}
+		missing.ID = 9999
+		if err := catalog.Products.Update(&missing); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Errorf("Update несуществующего продукта error = %v", err)
+		}
+	})
+}
+
+func TestProductsGetByFilter(t *testing.T) {
+	forEachStorage(t, func(t *testing.T, catalog repositories.Catalog) {
+		f := repotest.Seed(t, catalog)
+
+		product, err := catalog.Products.GetByID(f.Mask.ID)
+		mustOK(t, err)
+		product.IsOnSale = true
+		product.SalePrice = 400
+		mustOK(t, catalog.Products.Update(product))
+
+		tests := []struct {
+			filter repositories.ProductFilter
+			want   []uint
+		}{
+			{repositories.ProductFilter{}, []uint{f.Cream.ID, f.Mask.ID, f.Ink.ID}},
+			{repositories.ProductFilter{BrandID: f.Alpha.ID}, []uint{f.Cream.ID, f.Mask.ID}},
+			{repositories.ProductFilter{CategoryID: f.Makeup.ID}, []uint{f.Ink.ID}},
+			{repositories.ProductFilter{SubcategoryIDs: []uint{f.Creams.ID, f.Eyes.ID}}, []uint{f.Cream.ID, f.Ink.ID}},
+			{repositories.ProductFilter{BrandID: f.Alpha.ID, OnSale: true}, []uint{f.Mask.ID}},
+			{repositories.ProductFilter{BrandID: f.Beta.ID, CategoryID: f.Care.ID}, nil},
+		}
+		for _, tt := range tests {
+			products, err := catalog.Products.GetByFilter(tt.filter)
+			mustOK(t, err)
+			if got := productIDs(products); !slices.Equal(got, tt.want) {
+				t.Errorf("GetByFilter(%+v) = %v, want %v", tt.filter, got, tt.want)
+			}
+			for _, product := range products {
+				if product.Brand.ID != product.BrandID || product.Subcategory.Category.ID == 0 {
+					t.Errorf("продукт %d без бренда или категории", product.ID)
+				}
+			}
+		}
+	})
+}
+
+func TestProductsBatches(t *testing.T) {
+	forEachStorage(t, func(t *testing.T, catalog repositories.Catalog) {
+		f := repotest.Seed(t, catalog)
+
+		var sizes []int
+		var ids []uint
+		err := catalog.Products.Batches(2, func(products []models.Product) error {
+			sizes = append(sizes, len(products))
+			for _, product := range products {
+				if product.Brand.ID != product.BrandID || product.Subcategory.Category.ID == 0 {
+					t.Errorf("продукт %d без бренда или категории", product.ID)
+				}
+				ids = append(ids, product.ID)
+			}
+			return nil
+		})
+		mustOK(t, err)
+		if !slices.Equal(sizes, []int{2, 1}) || !slices.Equal(ids, []uint{f.Cream.ID, f.Mask.ID, f.Ink.ID}) {
+			t.Errorf("Batches(2) = %v %v", sizes, ids)
+		}
+
+		stop := errors.New("stop")
+		calls := 0
+		err = catalog.Products.Batches(1, func([]models.Product) error {
+			calls++
+			return stop
+		})
+		if !errors.Is(err, stop) || calls != 1 {
+			t.Errorf("Batches должен остановиться на ошибке: error = %v, вызовов %d", err, calls)
+		}
+	})
+}
+
+func TestProductsUpdatePrices(t *testing.T) {
+	forEachStorage(t, func(t *testing.T, catalog repositories.Catalog) {
+		f := repotest.Seed(t, catalog)
+
+		product, err := catalog.Products.GetByID(f.Cream.ID)
+		mustOK(t, err)
+		product.IsOnSale = true
+		product.SalePrice = 800
+		mustOK(t, catalog.Products.Update(product))
+		product, err = catalog.Products.GetByID(f.Cream.ID)
+		mustOK(t, err)
+		startedAt := product.SaleStartedAt
+
+		err = catalog.Products.UpdatePrices([]models.PriceHistory{
+			{ProductID: f.Cream.ID, OldPrice: 1000, NewPrice: 1100, OldSalePrice: 800, NewSalePrice: 880},
+			{ProductID: f.Mask.ID, OldPrice: 500, NewPrice: 550},
+		})
+		mustOK(t, err)
+
+		product, err = catalog.Products.GetByID(f.Cream.ID)
+		mustOK(t, err)
+		if product.Price != 1100 || product.SalePrice != 880 {
+			t.Errorf("цены после UpdatePrices = %v %v", product.Price, product.SalePrice)
+		}
+		if !product.IsOnSale || product.SaleStartedAt == nil || !product.SaleStartedAt.Equal(*startedAt) {
+			t.Errorf("изменение цен не должно менять акцию: %v %v", product.IsOnSale, product.SaleStartedAt)
+		}
+
+		// Если одна из цен устарела, не меняется ни одна
+		err = catalog.Products.UpdatePrices([]models.PriceHistory{
+			{ProductID: f.Ink.ID, OldPrice: 700, NewPrice: 770},
+			{ProductID: f.Mask.ID, OldPrice: 500, NewPrice: 600},
+		})
+		if !errors.Is(err, repositories.ErrPriceChanged) {
+			t.Errorf("UpdatePrices с устаревшей ценой error = %v", err)
+		}
+		ink, err := catalog.Products.GetByID(f.Ink.ID)
+		mustOK(t, err)
+		if ink.Price != 700 {
+			t.Errorf("цена изменилась несмотря на ошибку: %v", ink.Price)
+		}
+
+		mustOK(t, catalog.Products.UpdatePrices(nil))
+	})
+}
@@ -0,0 +1,125 @@
+package repositories
+
+import (
+	"cosmetics_catalog/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CategoryRepository struct {
+	db *gorm.DB
+}
+
+// NewCategoryRepository создает новый экземпляр репозитория категорий
+func NewCategoryRepository(db *gorm.DB) *CategoryRepository {
+	return &CategoryRepository{db: db}
+}
+
+// Reserve запрещает слаги категорий в запросах репозитория,
+// см. models.WithReservedCategorySlugs
+func (r *CategoryRepository) Reserve(slugs ...string) {
+	r.db = r.db.WithContext(models.WithReservedCategorySlugs(r.db.Statement.Context, slugs...))
+}
+
+// IsReserved сообщает, запрещено ли слово как слаг категории
+func (r *CategoryRepository) IsReserved(slug string) bool {
+	return models.IsReservedCategorySlug(r.db.Statement.Context, slug)
+}
+
+// Create добавляет категорию вместе с вложенными подкатегориями
+func (r *CategoryRepository) Create(category *models.Category) error {
+	return r.db.Create(category).Error
+}
+
+// Update сохраняет название, слаг и SEO категории. Подкатегории не меняются,
+// прежний слаг попадает в историю
+func (r *CategoryRepository) Update(category *models.Category) error {
+	if err := r.db.First(&models.Category{}, category.ID).Error; err != nil {
+		return err
+	}
+	return r.db.Omit(clause.Associations).Save(category).Error
+}
+
+// Delete удаляет категорию со всем деревом подкатегорий и ее ограничениями
+// в промокодах. Если в подкатегориях есть продукты, ничего не удаляется
+// и возвращается ErrCategoryHasProducts
+func (r *CategoryRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&models.Category{}, id).Error; err != nil {
+			return err
+		}
+
+		var count int64
+		err := tx.
+			Model(&models.Product{}).
+			Where("subcategory_id IN (?)", tx.Model(&models.Subcategory{}).Select("id").Where("category_id = ?", id)).
+			Count(&count).
+			Error
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrCategoryHasProducts
+		}
+
+		if err := tx.Where("category_id = ?", id).Delete(&models.Subcategory{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM promo_code_categories WHERE category_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Category{}, id).Error
+	})
+}
+
+// GetByID возвращает категорию по ID
+func (r *CategoryRepository) GetByID(id uint) (*models.Category, error) {
+	var category models.Category
+	err := r.db.First(&category, id).Error
+	return &category, err
+}
+
+// GetBySlug возвращает категорию по слагу вместе с подкатегориями верхнего уровня
+func (r *CategoryRepository) GetBySlug(slug string) (*models.Category, error) {
+	var category models.Category
+	err := r.db.
+		Preload("Subcategories", func(db *gorm.DB) *gorm.DB {
+			return db.Where("parent_id IS NULL").Order("subcategories.id")
+		}).
+		Where("slug = ?", slug).
+		First(&category).
+		Error
+	return &category, err
+}
+
+// GetAll возвращает все категории в порядке добавления
+func (r *CategoryRepository) GetAll() ([]models.Category, error) {
+	var categories []models.Category
+	err := r.db.Order("id").Find(&categories).Error
+	return categories, err
+}
+
+// List возвращает категории с числом продуктов во всех подкатегориях:
+// в порядке добавления, по алфавиту при ListByName
+// или начиная с самых больших при ListByProducts
+func (r *CategoryRepository) List(order string) ([]CategoryItem, error) {
+	var items []CategoryItem
+	err := r.db.
+		Model(&models.Category{}).
+		Select("categories.*, COUNT(products.id) AS product_count").
+		Joins("LEFT JOIN subcategories ON subcategories.category_id = categories.id AND subcategories.deleted_at IS NULL").
+		Joins("LEFT JOIN products ON products.subcategory_id = subcategories.id AND products.deleted_at IS NULL").
+		Group("categories.id").
+		Order(listOrder(order, "categories", "id")).
+		Scan(&items).
+		Error
+	return items, err
+}
+
+// Search ищет категории по названию без учета регистра, по алфавиту
+func (r *CategoryRepository) Search(query string, limit int) ([]models.Category, error) {
+	var categories []models.Category
+	err := r.db.Scopes(nameContains(query), limitRows(limit)).Order("name").Find(&categories).Error
+	return categories, err
+}
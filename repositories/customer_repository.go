@@ -0,0 +1,216 @@
+package repositories
+
+import (
+	"cosmetics_catalog/auth"
+	"cosmetics_catalog/models"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidToken возвращается для неизвестного, использованного или просроченного токена
+var ErrInvalidToken = errors.New("ссылка недействительна или устарела")
+
+type CustomerRepository struct {
+	db *gorm.DB
+}
+
+// NewCustomerRepository создает новый экземпляр репозитория покупателей
+func NewCustomerRepository(db *gorm.DB) *CustomerRepository {
+	return &CustomerRepository{db: db}
+}
+
+// NormalizeEmail приводит email к виду, в котором он хранится в базе
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// Create добавляет нового покупателя
+func (r *CustomerRepository) Create(customer *models.Customer) error {
+	customer.Email = NormalizeEmail(customer.Email)
+	return r.db.Create(customer).Error
+}
+
+// GetByID возвращает покупателя по ID
+func (r *CustomerRepository) GetByID(id uint) (*models.Customer, error) {
+	var customer models.Customer
+	err := r.db.First(&customer, id).Error
+	return &customer, err
+}
+
+// GetByEmail возвращает покупателя по email
+func (r *CustomerRepository) GetByEmail(email string) (*models.Customer, error) {
+	var customer models.Customer
+	err := r.db.Where("email = ?", NormalizeEmail(email)).First(&customer).Error
+	return &customer, err
+}
+
+// UpdateProfile обновляет имя и телефон покупателя
+func (r *CustomerRepository) UpdateProfile(customer *models.Customer) error {
+	return r.db.
+		Model(customer).
+		Select("Name", "Phone").
+		Updates(customer).
+		Error
+}
+
+// UpdatePassword сохраняет новый хеш пароля покупателя
+func (r *CustomerRepository) UpdatePassword(customerID uint, passwordHash string) error {
+	return r.db.
+		Model(&models.Customer{}).
+		Where("id = ?", customerID).
+		Update("password_hash", passwordHash).
+		Error
+}
+
+// MarkVerified отмечает email покупателя как подтвержденный
+func (r *CustomerRepository) MarkVerified(customerID uint) error {
+	return r.db.
+		Model(&models.Customer{}).
+		Where("id = ? AND email_verified_at IS NULL", customerID).
+		Update("email_verified_at", time.Now()).
+		Error
+}
+
+// CreateToken выпускает одноразовый токен и возвращает его в открытом виде.
+// Ранее выпущенные неиспользованные токены с тем же назначением аннулируются
+func (r *CustomerRepository) CreateToken(customerID uint, purpose string, ttl time.Duration) (string, error) {
+	token, hash := auth.NewToken()
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.
+			Where("customer_id = ? AND purpose = ? AND used_at IS NULL", customerID, purpose).
+			Delete(&models.CustomerToken{}).
+			Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(&models.CustomerToken{
+			CustomerID: customerID,
+			Purpose:    purpose,
+			TokenHash:  hash,
+			ExpiresAt:  time.Now().Add(ttl),
+		}).Error
+	})
+	return token, err
+}
+
+// UseToken погашает одноразовый токен и возвращает его владельца
+func (r *CustomerRepository) UseToken(token, purpose string) (*models.Customer, error) {
+	var customer models.Customer
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var record models.CustomerToken
+		err := tx.
+			Where("token_hash = ? AND purpose = ?", auth.HashToken(token), purpose).
+			First(&record).
+			Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidToken
+		}
+		if err != nil {
+			return err
+		}
+		if record.UsedAt != nil || time.Now().After(record.ExpiresAt) {
+			return ErrInvalidToken
+		}
+
+		// Условие по used_at защищает от повторного использования при гонке
+		result := tx.
+			Model(&record).
+			Where("used_at IS NULL").
+			Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInvalidToken
+		}
+
+		return tx.First(&customer, record.CustomerID).Error
+	})
+	return &customer, err
+}
+
+// GetAddresses возвращает адресную книгу покупателя, адрес по умолчанию первым
+func (r *CustomerRepository) GetAddresses(customerID uint) ([]models.Address, error) {
+	var addresses []models.Address
+	err := r.db.
+		Where("customer_id = ?", customerID).
+		Order("is_default DESC, created_at").
+		Find(&addresses).
+		Error
+	return addresses, err
+}
+
+// CreateAddress добавляет адрес в адресную книгу.
+// Первый адрес покупателя становится адресом по умолчанию
+func (r *CustomerRepository) CreateAddress(address *models.Address) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		err := tx.
+			Model(&models.Address{}).
+			Where("customer_id = ?", address.CustomerID).
+			Count(&count).
+			Error
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			address.IsDefault = true
+		}
+
+		if address.IsDefault {
+			if err := resetDefaultAddress(tx, address.CustomerID); err != nil {
+				return err
+			}
+		}
+		return tx.Create(address).Error
+	})
+}
+
+// SetDefaultAddress делает адрес покупателя адресом по умолчанию
+func (r *CustomerRepository) SetDefaultAddress(customerID, addressID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Проверяем, что адрес принадлежит покупателю
+		var address models.Address
+		err := tx.
+			Where("id = ? AND customer_id = ?", addressID, customerID).
+			First(&address).
+			Error
+		if err != nil {
+			return err
+		}
+
+		if err := resetDefaultAddress(tx, customerID); err != nil {
+			return err
+		}
+		return tx.Model(&address).Update("is_default", true).Error
+	})
+}
+
+// DeleteAddress удаляет адрес покупателя
+func (r *CustomerRepository) DeleteAddress(customerID, addressID uint) error {
+	result := r.db.
+		Where("id = ? AND customer_id = ?", addressID, customerID).
+		Delete(&models.Address{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// resetDefaultAddress снимает признак адреса по умолчанию со всех адресов покупателя
+func resetDefaultAddress(tx *gorm.DB, customerID uint) error {
+	return tx.
+		Model(&models.Address{}).
+		Where("customer_id = ? AND is_default = ?", customerID, true).
+		Update("is_default", false).
+		Error
+}
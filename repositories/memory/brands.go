@@ -0,0 +1,183 @@
+package memory
+
+import (
+	"cosmetics_catalog/models"
+	"cosmetics_catalog/repositories"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// BrandRepository бренды каталога в памяти
+type BrandRepository struct {
+	c *Catalog
+}
+
+// Create добавляет бренд. Слаг генерируется из названия, если не задан
+func (r *BrandRepository) Create(brand *models.Brand) error {
+	r.c.mu.Lock()
+	defer r.c.mu.Unlock()
+
+	for _, other := range r.c.brands {
+		if other.Name == brand.Name {
+			return fmt.Errorf("бренд %q уже существует", brand.Name)
+		}
+	}
+	if brand.Slug == "" {
+		brand.Slug = uniqueSlug(brand.Name, r.slugTaken)
+	} else if r.slugTaken(brand.Slug) {
+		return errSlugTaken("бренда", brand.Slug)
+	}
+
+	r.c.lastBrandID++
+	brand.ID = r.c.lastBrandID
+	touch(&brand.Model, true)
+	stored := *brand
+	stored.Products = nil
+	r.c.brands[brand.ID] = stored
+	return nil
+}
+
+// Update сохраняет название, слаг и SEO бренда
+func (r *BrandRepository) Update(brand *models.Brand) error {
+	r.c.mu.Lock()
+	defer r.c.mu.Unlock()
+
+	current, ok := r.c.brands[brand.ID]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	for id, other := range r.c.brands {
+		if id == brand.ID {
+			continue
+		}
+		if other.Name == brand.Name {
+			return fmt.Errorf("бренд %q уже существует", brand.Name)
+		}
+		if other.Slug == brand.Slug {
+			return errSlugTaken("бренда", brand.Slug)
+		}
+	}
+
+	brand.CreatedAt = current.CreatedAt
+	touch(&brand.Model, false)
+	stored := *brand
+	stored.Products = nil
+	r.c.brands[brand.ID] = stored
+	return nil
+}
+
+// Delete удаляет бренд без продуктов. Бренд с продуктами не удаляется,
+// возвращается repositories.ErrBrandHasProducts
+func (r *BrandRepository) Delete(id uint) error {
+	r.c.mu.Lock()
+	defer r.c.mu.Unlock()
+
+	if _, ok := r.c.brands[id]; !ok {
+		return gorm.ErrRecordNotFound
+	}
+	for _, product := range r.c.products {
+		if product.BrandID == id {
+			return repositories.ErrBrandHasProducts
+		}
+	}
+	delete(r.c.brands, id)
+	return nil
+}
+
+// GetByID возвращает бренд по ID
+func (r *BrandRepository) GetByID(id uint) (*models.Brand, error) {
+	r.c.mu.RLock()
+	defer r.c.mu.RUnlock()
+
+	brand, ok := r.c.brands[id]
+	if !ok {
+		return &models.Brand{}, gorm.ErrRecordNotFound
+	}
+	return &brand, nil
+}
+
+// GetBySlug возвращает бренд по слагу вместе с продуктами
+func (r *BrandRepository) GetBySlug(slug string) (*models.Brand, error) {
+	r.c.mu.RLock()
+	defer r.c.mu.RUnlock()
+
+	for _, brand := range r.c.brands {
+		if brand.Slug != slug {
+			continue
+		}
+		for _, id := range sortedIDs(r.c.products) {
+			if product := r.c.products[id]; product.BrandID == brand.ID {
+				product.Subcategory = r.c.subcategory(r.c.subcategories[product.SubcategoryID])
+				brand.Products = append(brand.Products, product)
+			}
+		}
+		return &brand, nil
+	}
+	return &models.Brand{}, gorm.ErrRecordNotFound
+}
+
+// GetAll возвращает все бренды по алфавиту
+func (r *BrandRepository) GetAll() ([]models.Brand, error) {
+	return r.find(func(models.Brand) bool { return true }), nil
+}
+
+// List возвращает бренды с числом продуктов, по алфавиту
+// или, при order равном ListByProducts, начиная с самых больших
+func (r *BrandRepository) List(order string) ([]repositories.BrandItem, error) {
+	brands := r.find(func(models.Brand) bool { return true })
+
+	r.c.mu.RLock()
+	counts := map[uint]int{}
+	for _, product := range r.c.products {
+		counts[product.BrandID]++
+	}
+	r.c.mu.RUnlock()
+
+	items := make([]repositories.BrandItem, len(brands))
+	for i, brand := range brands {
+		items[i] = repositories.BrandItem{Brand: brand, ProductCount: counts[brand.ID]}
+	}
+	if order == repositories.ListByProducts {
+		sort.SliceStable(items, func(i, j int) bool { return items[i].ProductCount > items[j].ProductCount })
+	}
+	return items, nil
+}
+
+// Search ищет бренды по названию без учета регистра, по алфавиту
+func (r *BrandRepository) Search(query string, limit int) ([]models.Brand, error) {
+	query = strings.ToLower(query)
+	brands := r.find(func(brand models.Brand) bool {
+		return strings.Contains(strings.ToLower(brand.Name), query)
+	})
+	if limit > 0 && len(brands) > limit {
+		brands = brands[:limit]
+	}
+	return brands, nil
+}
+
+// find возвращает бренды по алфавиту, подходящие под условие
+func (r *BrandRepository) find(match func(models.Brand) bool) []models.Brand {
+	r.c.mu.RLock()
+	defer r.c.mu.RUnlock()
+
+	var brands []models.Brand
+	for _, id := range sortedIDs(r.c.brands) {
+		if brand := r.c.brands[id]; match(brand) {
+			brands = append(brands, brand)
+		}
+	}
+	sort.SliceStable(brands, func(i, j int) bool { return brands[i].Name < brands[j].Name })
+	return brands
+}
+
+func (r *BrandRepository) slugTaken(candidate string) bool {
+	for _, brand := range r.c.brands {
+		if brand.Slug == candidate {
+			return true
+		}
+	}
+	return false
+}
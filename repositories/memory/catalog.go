@@ -0,0 +1,129 @@
+// Package memory хранит каталог в памяти и реализует интерфейсы репозиториев
+// каталога из пакета repositories. Используется в тестах и в демо-режиме
+// сервера, когда каталог не нужно сохранять в базе.
+// Как и репозитории GORM, при отсутствии записи методы возвращают gorm.ErrRecordNotFound
+package memory
+
+import (
+	"cosmetics_catalog/models"
+	"cosmetics_catalog/repositories"
+	"cosmetics_catalog/slug"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Catalog общее хранилище брендов, категорий, подкатегорий и продуктов.
+// Репозитории каталога работают с одним хранилищем, чтобы продукты
+// возвращались вместе с брендом и подкатегорией
+type Catalog struct {
+	mu sync.RWMutex
+
+	brands        map[uint]models.Brand
+	categories    map[uint]models.Category
+	subcategories map[uint]models.Subcategory
+	products      map[uint]models.Product
+
+	// Последние выданные ID. Как в базе, ID каждой таблицы начинаются с 1
+	lastBrandID, lastCategoryID, lastSubcategoryID, lastProductID uint
+
+	onSale []repositories.ProductHook
+	// reserved слова, запрещенные для слагов категорий
+	reserved map[string]bool
+}
+
+// NewCatalog создает пустой каталог
+func NewCatalog() *Catalog {
+	return &Catalog{
+		brands:        map[uint]models.Brand{},
+		categories:    map[uint]models.Category{},
+		subcategories: map[uint]models.Subcategory{},
+		products:      map[uint]models.Product{},
+		reserved:      map[string]bool{},
+	}
+}
+
+// Products возвращает репозиторий продуктов каталога
+func (c *Catalog) Products() *ProductRepository {
+	return &ProductRepository{c: c}
+}
+
+// Brands возвращает репозиторий брендов каталога
+func (c *Catalog) Brands() *BrandRepository {
+	return &BrandRepository{c: c}
+}
+
+// Categories возвращает репозиторий категорий каталога
+func (c *Catalog) Categories() *CategoryRepository {
+	return &CategoryRepository{c: c}
+}
+
+// Subcategories возвращает репозиторий подкатегорий каталога
+func (c *Catalog) Subcategories() *SubcategoryRepository {
+	return &SubcategoryRepository{c: c}
+}
+
+// Repositories возвращает все репозитории каталога
+func (c *Catalog) Repositories() repositories.Catalog {
+	return repositories.Catalog{
+		Products:      c.Products(),
+		Brands:        c.Brands(),
+		Categories:    c.Categories(),
+		Subcategories: c.Subcategories(),
+	}
+}
+
+// product возвращает продукт с брендом и подкатегорией, как Preload в GORM
+func (c *Catalog) product(product models.Product) models.Product {
+	product.Brand = c.brands[product.BrandID]
+	product.Subcategory = c.subcategory(c.subcategories[product.SubcategoryID])
+	return product
+}
+
+// subcategory возвращает подкатегорию с категорией
+func (c *Catalog) subcategory(subcategory models.Subcategory) models.Subcategory {
+	subcategory.Category = c.categories[subcategory.CategoryID]
+	return subcategory
+}
+
+// uniqueSlug подбирает слаг из названия, которого нет среди taken
+func uniqueSlug(name string, taken func(candidate string) bool) string {
+	value, _ := slug.Unique(slug.Make(name), func(candidate string) (bool, error) {
+		return taken(candidate), nil
+	})
+	return value
+}
+
+// errSlugTaken повторяет ошибку уникального индекса базы
+func errSlugTaken(entity, value string) error {
+	return fmt.Errorf("слаг %s %q уже занят", entity, value)
+}
+
+// touch заполняет даты создания и изменения записи
+func touch(model *gorm.Model, isNew bool) {
+	now := time.Now()
+	if isNew {
+		model.CreatedAt = now
+	}
+	model.UpdatedAt = now
+}
+
+// sortedIDs возвращает ключи в порядке возрастания, то есть в порядке добавления
+func sortedIDs[T any](items map[uint]T) []uint {
+	ids := make([]uint, 0, len(items))
+	for id := range items {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+var (
+	_ repositories.Products      = (*ProductRepository)(nil)
+	_ repositories.Brands        = (*BrandRepository)(nil)
+	_ repositories.Categories    = (*CategoryRepository)(nil)
+	_ repositories.Subcategories = (*SubcategoryRepository)(nil)
+)
@@ -0,0 +1,230 @@
+package memory
+
+import (
+	"cosmetics_catalog/models"
+	"cosmetics_catalog/repositories"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// CategoryRepository категории каталога в памяти
+type CategoryRepository struct {
+	c *Catalog
+}
+
+// Create добавляет категорию вместе с вложенными подкатегориями.
+// Как и GORM, записывает выданные ID в переданные структуры
+func (r *CategoryRepository) Create(category *models.Category) error {
+	r.c.mu.Lock()
+	defer r.c.mu.Unlock()
+
+	for _, other := range r.c.categories {
+		if other.Name == category.Name {
+			return fmt.Errorf("категория %q уже существует", category.Name)
+		}
+	}
+	if category.Slug == "" {
+		category.Slug = uniqueSlug(category.Name, func(candidate string) bool {
+			return r.c.reserved[candidate] || r.slugTaken(candidate)
+		})
+	} else if r.c.reserved[category.Slug] {
+		return models.ErrReservedSlug
+	} else if r.slugTaken(category.Slug) {
+		return errSlugTaken("категории", category.Slug)
+	}
+
+	r.c.lastCategoryID++
+	category.ID = r.c.lastCategoryID
+	touch(&category.Model, true)
+	stored := *category
+	stored.Subcategories = nil
+	r.c.categories[category.ID] = stored
+
+	for i := range category.Subcategories {
+		subcategory := &category.Subcategories[i]
+		subcategory.CategoryID = category.ID
+		subcategory.ParentID = nil
+		if err := r.c.createSubcategory(subcategory); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Update сохраняет название, слаг и SEO категории
+func (r *CategoryRepository) Update(category *models.Category) error {
+	r.c.mu.Lock()
+	defer r.c.mu.Unlock()
+
+	current, ok := r.c.categories[category.ID]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	if r.c.reserved[category.Slug] {
+		return models.ErrReservedSlug
+	}
+	for id, other := range r.c.categories {
+		if id == category.ID {
+			continue
+		}
+		if other.Name == category.Name {
+			return fmt.Errorf("категория %q уже существует", category.Name)
+		}
+		if other.Slug == category.Slug {
+			return errSlugTaken("категории", category.Slug)
+		}
+	}
+
+	category.CreatedAt = current.CreatedAt
+	touch(&category.Model, false)
+	stored := *category
+	stored.Subcategories = nil
+	r.c.categories[category.ID] = stored
+	return nil
+}
+
+// Delete удаляет категорию со всем деревом подкатегорий. Если в подкатегориях
+// есть продукты, ничего не удаляется и возвращается repositories.ErrCategoryHasProducts
+func (r *CategoryRepository) Delete(id uint) error {
+	r.c.mu.Lock()
+	defer r.c.mu.Unlock()
+
+	if _, ok := r.c.categories[id]; !ok {
+		return gorm.ErrRecordNotFound
+	}
+	for _, product := range r.c.products {
+		if r.c.subcategories[product.SubcategoryID].CategoryID == id {
+			return repositories.ErrCategoryHasProducts
+		}
+	}
+
+	for subcategoryID, subcategory := range r.c.subcategories {
+		if subcategory.CategoryID == id {
+			delete(r.c.subcategories, subcategoryID)
+		}
+	}
+	delete(r.c.categories, id)
+	return nil
+}
+
+// GetByID возвращает категорию по ID
+func (r *CategoryRepository) GetByID(id uint) (*models.Category, error) {
+	r.c.mu.RLock()
+	defer r.c.mu.RUnlock()
+
+	category, ok := r.c.categories[id]
+	if !ok {
+		return &models.Category{}, gorm.ErrRecordNotFound
+	}
+	return &category, nil
+}
+
+// GetBySlug возвращает категорию по слагу вместе с подкатегориями верхнего уровня
+func (r *CategoryRepository) GetBySlug(slug string) (*models.Category, error) {
+	r.c.mu.RLock()
+	defer r.c.mu.RUnlock()
+
+	for _, category := range r.c.categories {
+		if category.Slug != slug {
+			continue
+		}
+		for _, id := range sortedIDs(r.c.subcategories) {
+			if subcategory := r.c.subcategories[id]; subcategory.CategoryID == category.ID && subcategory.ParentID == nil {
+				category.Subcategories = append(category.Subcategories, subcategory)
+			}
+		}
+		return &category, nil
+	}
+	return &models.Category{}, gorm.ErrRecordNotFound
+}
+
+// GetAll возвращает все категории в порядке добавления
+func (r *CategoryRepository) GetAll() ([]models.Category, error) {
+	return r.find(func(models.Category) bool { return true }), nil
+}
+
+// List возвращает категории с числом продуктов во всех подкатегориях:
+// в порядке добавления, по алфавиту при ListByName
+// или начиная с самых больших при ListByProducts
+func (r *CategoryRepository) List(order string) ([]repositories.CategoryItem, error) {
+	categories := r.find(func(models.Category) bool { return true })
+
+	r.c.mu.RLock()
+	counts := map[uint]int{}
+	for _, product := range r.c.products {
+		counts[r.c.subcategories[product.SubcategoryID].CategoryID]++
+	}
+	r.c.mu.RUnlock()
+
+	items := make([]repositories.CategoryItem, len(categories))
+	for i, category := range categories {
+		items[i] = repositories.CategoryItem{Category: category, ProductCount: counts[category.ID]}
+	}
+	switch order {
+	case repositories.ListByName:
+		sort.SliceStable(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+	case repositories.ListByProducts:
+		sort.SliceStable(items, func(i, j int) bool {
+			if items[i].ProductCount != items[j].ProductCount {
+				return items[i].ProductCount > items[j].ProductCount
+			}
+			return items[i].Name < items[j].Name
+		})
+	}
+	return items, nil
+}
+
+// Search ищет категории по названию без учета регистра, по алфавиту
+func (r *CategoryRepository) Search(query string, limit int) ([]models.Category, error) {
+	query = strings.ToLower(query)
+	categories := r.find(func(category models.Category) bool {
+		return strings.Contains(strings.ToLower(category.Name), query)
+	})
+	sort.SliceStable(categories, func(i, j int) bool { return categories[i].Name < categories[j].Name })
+	if limit > 0 && len(categories) > limit {
+		categories = categories[:limit]
+	}
+	return categories, nil
+}
+
+// find возвращает категории в порядке добавления, подходящие под условие
+func (r *CategoryRepository) find(match func(models.Category) bool) []models.Category {
+	r.c.mu.RLock()
+	defer r.c.mu.RUnlock()
+
+	var categories []models.Category
+	for _, id := range sortedIDs(r.c.categories) {
+		if category := r.c.categories[id]; match(category) {
+			categories = append(categories, category)
+		}
+	}
+	return categories
+}
+
+func (r *CategoryRepository) slugTaken(candidate string) bool {
+	for _, category := range r.c.categories {
+		if category.Slug == candidate {
+			return true
+		}
+	}
+	return false
+}
+
+// Reserve запрещает использовать слова как слаги категорий
+func (r *CategoryRepository) Reserve(slugs ...string) {
+	r.c.mu.Lock()
+	defer r.c.mu.Unlock()
+	for _, s := range slugs {
+		r.c.reserved[s] = true
+	}
+}
+
+// IsReserved сообщает, запрещено ли слово как слаг категории
+func (r *CategoryRepository) IsReserved(slug string) bool {
+	r.c.mu.RLock()
+	defer r.c.mu.RUnlock()
+	return r.c.reserved[slug]
+}
@@ -0,0 +1,304 @@
+package memory
+
+import (
+	"cosmetics_catalog/models"
+	"cosmetics_catalog/repositories"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ProductRepository продукты каталога в памяти
+type ProductRepository struct {
+	c *Catalog
+}
+
+// Create добавляет продукт. Слаг генерируется из названия, если не задан
+func (r *ProductRepository) Create(product *models.Product) error {
+	r.c.mu.Lock()
+	defer r.c.mu.Unlock()
+
+	if product.Slug == "" {
+		product.Slug = uniqueSlug(product.Name, r.slugTaken)
+	} else if r.slugTaken(product.Slug) {
+		return errSlugTaken("продукта", product.Slug)
+	}
+	if product.IsOnSale && product.SaleStartedAt == nil {
+		now := time.Now()
+		product.SaleStartedAt = &now
+	}
+
+	r.c.lastProductID++
+	product.ID = r.c.lastProductID
+	touch(&product.Model, true)
+	r.c.products[product.ID] = *product
+	return nil
+}
+
+// Update сохраняет продукт и вызывает обработчики OnSale,
+// если продукт только что попал в акцию
+func (r *ProductRepository) Update(product *models.Product) error {
+	r.c.mu.Lock()
+	current, ok := r.c.products[product.ID]
+	if !ok {
+		r.c.mu.Unlock()
+		return gorm.ErrRecordNotFound
+	}
+	for id, other := range r.c.products {
+		if id != product.ID && other.Slug == product.Slug {
+			r.c.mu.Unlock()
+			return errSlugTaken("продукта", product.Slug)
+		}
+	}
+
+	switch {
+	case !product.IsOnSale:
+		product.SaleStartedAt = nil
+	case !current.IsOnSale:
+		now := time.Now()
+		product.SaleStartedAt = &now
+	case product.SaleStartedAt == nil:
+		product.SaleStartedAt = current.SaleStartedAt
+	}
+
+	product.CreatedAt = current.CreatedAt
+	touch(&product.Model, false)
+	r.c.products[product.ID] = *product
+	hooks := r.c.onSale
+	r.c.mu.Unlock()
+
+	// Обработчики вызываются без блокировки, они могут читать каталог
+	if !current.IsOnSale && product.IsOnSale {
+		for _, hook := range hooks {
+			hook(product)
+		}
+	}
+	return nil
+}
+
+// Delete удаляет продукт
+func (r *ProductRepository) Delete(id uint) error {
+	r.c.mu.Lock()
+	defer r.c.mu.Unlock()
+
+	if _, ok := r.c.products[id]; !ok {
+		return gorm.ErrRecordNotFound
+	}
+	delete(r.c.products, id)
+	return nil
+}
+
+// GetByID возвращает продукт по ID
+func (r *ProductRepository) GetByID(id uint) (*models.Product, error) {
+	r.c.mu.RLock()
+	defer r.c.mu.RUnlock()
+
+	product, ok := r.c.products[id]
+	if !ok {
+		return &models.Product{}, gorm.ErrRecordNotFound
+	}
+	product = r.c.product(product)
+	return &product, nil
+}
+
+// GetByIDs возвращает продукты по списку ID в том же порядке.
+// Отсутствующие ID пропускаются
+func (r *ProductRepository) GetByIDs(ids []uint) ([]models.Product, error) {
+	r.c.mu.RLock()
+	defer r.c.mu.RUnlock()
+
+	products := make([]models.Product, 0, len(ids))
+	for _, id := range ids {
+		if product, ok := r.c.products[id]; ok {
+			products = append(products, r.c.product(product))
+		}
+	}
+	return products, nil
+}
+
+// GetBySlug возвращает продукт по слагу
+func (r *ProductRepository) GetBySlug(slug string) (*models.Product, error) {
+	products := r.find(func(product models.Product) bool { return product.Slug == slug })
+	if len(products) == 0 {
+		return &models.Product{}, gorm.ErrRecordNotFound
+	}
+	return &products[0], nil
+}
+
+// GetAll возвращает все продукты каталога по порядку добавления
+func (r *ProductRepository) GetAll() ([]models.Product, error) {
+	return r.find(func(models.Product) bool { return true }), nil
+}
+
+// GetByCategory возвращает продукты категории
+func (r *ProductRepository) GetByCategory(categoryID uint) ([]models.Product, error) {
+	return r.find(func(product models.Product) bool {
+		return product.Subcategory.CategoryID == categoryID
+	}), nil
+}
+
+// GetBySubcategory возвращает продукты подкатегории без потомков
+func (r *ProductRepository) GetBySubcategory(subcategoryID uint) ([]models.Product, error) {
+	return r.find(func(product models.Product) bool { return product.SubcategoryID == subcategoryID }), nil
+}
+
+// GetBySubcategories возвращает продукты из списка подкатегорий с фильтром по цене
+func (r *ProductRepository) GetBySubcategories(subcategoryIDs []uint, sort string, minPrice, maxPrice float64) ([]models.Product, error) {
+	ids := make(map[uint]bool, len(subcategoryIDs))
+	for _, id := range subcategoryIDs {
+		ids[id] = true
+	}
+	products := r.find(func(product models.Product) bool { return ids[product.SubcategoryID] })
+	return filterByPrice(products, sort, minPrice, maxPrice), nil
+}
+
+// GetByBrand возвращает продукты бренда с фильтром по цене
+func (r *ProductRepository) GetByBrand(brandID uint, sort string, minPrice, maxPrice float64) ([]models.Product, error) {
+	products := r.find(func(product models.Product) bool { return product.BrandID == brandID })
+	return filterByPrice(products, sort, minPrice, maxPrice), nil
+}
+
+// GetOnSale возвращает товары со скидкой с фильтром по цене
+func (r *ProductRepository) GetOnSale(sort string, minPrice, maxPrice float64) ([]models.Product, error) {
+	products := r.find(func(product models.Product) bool { return product.IsOnSale })
+	return filterByPrice(products, sort, minPrice, maxPrice), nil
+}
+
+// GetRecentSales возвращает продукты, недавно попавшие в акцию, новые сверху
+func (r *ProductRepository) GetRecentSales(brandID uint, limit int) ([]models.Product, error) {
+	products := r.find(func(product models.Product) bool {
+		return product.IsOnSale && (brandID == 0 || product.BrandID == brandID)
+	})
+	started := func(product models.Product) time.Time {
+		if product.SaleStartedAt != nil {
+			return *product.SaleStartedAt
+		}
+		return product.CreatedAt
+	}
+	sort.SliceStable(products, func(i, j int) bool { return started(products[i]).After(started(products[j])) })
+	return limitProducts(products, limit), nil
+}
+
+// GetNewArrivals возвращает недавно добавленные продукты, новые сверху
+func (r *ProductRepository) GetNewArrivals(brandID uint, limit int) ([]models.Product, error) {
+	products := r.find(func(product models.Product) bool { return brandID == 0 || product.BrandID == brandID })
+	sort.SliceStable(products, func(i, j int) bool { return products[i].CreatedAt.After(products[j].CreatedAt) })
+	return limitProducts(products, limit), nil
+}
+
+// SearchByName поиск по названию без учета регистра с пагинацией
+func (r *ProductRepository) SearchByName(query string, limit, offset int) ([]models.Product, error) {
+	query = strings.ToLower(query)
+	products := r.find(func(product models.Product) bool {
+		return strings.Contains(strings.ToLower(product.Name), query)
+	})
+	if offset >= len(products) {
+		return nil, nil
+	}
+	return limitProducts(products[offset:], limit), nil
+}
+
+// GetByFilter возвращает продукты под фильтром по порядку добавления
+func (r *ProductRepository) GetByFilter(filter repositories.ProductFilter) ([]models.Product, error) {
+	return r.find(func(product models.Product) bool {
+		return (filter.BrandID == 0 || product.BrandID == filter.BrandID) &&
+			(filter.CategoryID == 0 || product.Subcategory.CategoryID == filter.CategoryID) &&
+			(len(filter.SubcategoryIDs) == 0 || slices.Contains(filter.SubcategoryIDs, product.SubcategoryID)) &&
+			(!filter.OnSale || product.IsOnSale)
+	}), nil
+}
+
+// Batches передает продукты пачками по порядку добавления
+func (r *ProductRepository) Batches(size int, fn func(products []models.Product) error) error {
+	products := r.find(func(models.Product) bool { return true })
+	for start := 0; start < len(products); start += size {
+		end := min(start+size, len(products))
+		if err := fn(products[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpdatePrices меняет цены, только если все продукты найдены со старыми ценами.
+// Историю цен каталог в памяти не хранит
+func (r *ProductRepository) UpdatePrices(changes []models.PriceHistory) error {
+	r.c.mu.Lock()
+	defer r.c.mu.Unlock()
+
+	for _, change := range changes {
+		product, ok := r.c.products[change.ProductID]
+		if !ok || product.Price != change.OldPrice || product.SalePrice != change.OldSalePrice {
+			return repositories.ErrPriceChanged
+		}
+	}
+	for _, change := range changes {
+		product := r.c.products[change.ProductID]
+		product.Price = change.NewPrice
+		product.SalePrice = change.NewSalePrice
+		touch(&product.Model, false)
+		r.c.products[change.ProductID] = product
+	}
+	return nil
+}
+
+// OnSale регистрирует обработчик, вызываемый когда продукт попадает в акцию
+func (r *ProductRepository) OnSale(hook repositories.ProductHook) {
+	r.c.mu.Lock()
+	defer r.c.mu.Unlock()
+	r.c.onSale = append(r.c.onSale, hook)
+}
+
+// find возвращает продукты с брендом и подкатегорией, подходящие под условие
+func (r *ProductRepository) find(match func(models.Product) bool) []models.Product {
+	r.c.mu.RLock()
+	defer r.c.mu.RUnlock()
+
+	var products []models.Product
+	for _, id := range sortedIDs(r.c.products) {
+		product := r.c.product(r.c.products[id])
+		if match(product) {
+			products = append(products, product)
+		}
+	}
+	return products
+}
+
+func (r *ProductRepository) slugTaken(candidate string) bool {
+	for _, product := range r.c.products {
+		if product.Slug == candidate {
+			return true
+		}
+	}
+	return false
+}
+
+// filterByPrice ограничивает продукты ценой и сортирует по ней, как одноименная
+// функция репозитория GORM
+func filterByPrice(products []models.Product, order string, minPrice, maxPrice float64) []models.Product {
+	filtered := products[:0]
+	for _, product := range products {
+		if (minPrice > 0 && product.Price < minPrice) || (maxPrice > 0 && product.Price > maxPrice) {
+			continue
+		}
+		filtered = append(filtered, product)
+	}
+
+	switch order {
+	case "asc":
+		sort.SliceStable(filtered, func(i, j int) bool { return filtered[i].Price < filtered[j].Price })
+	case "desc":
+		sort.SliceStable(filtered, func(i, j int) bool { return filtered[i].Price > filtered[j].Price })
+	}
+	return filtered
+}
+
+func limitProducts(products []models.Product, limit int) []models.Product {
+	if limit > 0 && len(products) > limit {
+		return products[:limit]
+	}
+	return products
+}
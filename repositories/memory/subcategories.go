@@ -0,0 +1,253 @@
+package memory
+
+import (
+	"cosmetics_catalog/models"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// SubcategoryRepository дерево подкатегорий каталога в памяти
+type SubcategoryRepository struct {
+	c *Catalog
+}
+
+// Create добавляет подкатегорию вместе с вложенными потомками. Путь вычисляется по родителю
+func (r *SubcategoryRepository) Create(subcategory *models.Subcategory) error {
+	r.c.mu.Lock()
+	defer r.c.mu.Unlock()
+	return r.c.createSubcategory(subcategory)
+}
+
+// Update сохраняет подкатегорию и обновляет пути ее потомков
+func (r *SubcategoryRepository) Update(subcategory *models.Subcategory) error {
+	r.c.mu.Lock()
+	defer r.c.mu.Unlock()
+
+	old, ok := r.c.subcategories[subcategory.ID]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	if subcategory.ParentID != nil {
+		parent, ok := r.c.subcategories[*subcategory.ParentID]
+		if !ok {
+			return gorm.ErrRecordNotFound
+		}
+		if parent.CategoryID == old.CategoryID &&
+			(parent.ID == old.ID || strings.HasPrefix(parent.Path+"/", old.Path+"/")) {
+			return models.ErrSubcategoryCycle
+		}
+	}
+
+	subcategory.CreatedAt = old.CreatedAt
+	return r.c.saveSubcategory(subcategory)
+}
+
+// GetByID возвращает подкатегорию по ID вместе с категорией
+func (r *SubcategoryRepository) GetByID(id uint) (*models.Subcategory, error) {
+	r.c.mu.RLock()
+	defer r.c.mu.RUnlock()
+
+	subcategory, ok := r.c.subcategories[id]
+	if !ok {
+		return &models.Subcategory{}, gorm.ErrRecordNotFound
+	}
+	subcategory = r.c.subcategory(subcategory)
+	return &subcategory, nil
+}
+
+// GetByPath находит подкатегорию категории по пути из слагов вместе с дочерними узлами
+func (r *SubcategoryRepository) GetByPath(categoryID uint, path string) (*models.Subcategory, error) {
+	r.c.mu.RLock()
+	defer r.c.mu.RUnlock()
+
+	path = strings.ToLower(path)
+	for _, id := range sortedIDs(r.c.subcategories) {
+		subcategory := r.c.subcategories[id]
+		if subcategory.CategoryID != categoryID || subcategory.Path != path {
+			continue
+		}
+
+		subcategory = r.c.subcategory(subcategory)
+		subcategory.Children = r.c.children(subcategory.ID)
+		sort.SliceStable(subcategory.Children, func(i, j int) bool {
+			return subcategory.Children[i].Name < subcategory.Children[j].Name
+		})
+		return &subcategory, nil
+	}
+	return &models.Subcategory{}, gorm.ErrRecordNotFound
+}
+
+// GetAll возвращает все подкатегории с категориями, упорядоченные по категории и пути
+func (r *SubcategoryRepository) GetAll() ([]models.Subcategory, error) {
+	subcategories := r.find(func(models.Subcategory) bool { return true })
+	sort.SliceStable(subcategories, func(i, j int) bool {
+		if subcategories[i].CategoryID != subcategories[j].CategoryID {
+			return subcategories[i].CategoryID < subcategories[j].CategoryID
+		}
+		return subcategories[i].Path < subcategories[j].Path
+	})
+	return subcategories, nil
+}
+
+// GetRoots возвращает подкатегории верхнего уровня категории
+func (r *SubcategoryRepository) GetRoots(categoryID uint) ([]models.Subcategory, error) {
+	roots := r.find(func(subcategory models.Subcategory) bool {
+		return subcategory.CategoryID == categoryID && subcategory.ParentID == nil
+	})
+	for i := range roots {
+		roots[i].Category = models.Category{}
+	}
+	return roots, nil
+}
+
+// GetAncestors возвращает предков подкатегории от корня к родителю
+func (r *SubcategoryRepository) GetAncestors(subcategory *models.Subcategory) ([]models.Subcategory, error) {
+	paths := subcategory.AncestorPaths()
+	if len(paths) == 0 {
+		return nil, nil
+	}
+
+	byPath := make(map[string]models.Subcategory, len(paths))
+	for _, ancestor := range r.find(func(other models.Subcategory) bool { return other.CategoryID == subcategory.CategoryID }) {
+		byPath[ancestor.Path] = ancestor
+	}
+	ordered := make([]models.Subcategory, 0, len(paths))
+	for _, path := range paths {
+		if ancestor, ok := byPath[path]; ok {
+			ordered = append(ordered, ancestor)
+		}
+	}
+	return ordered, nil
+}
+
+// GetDescendantIDs возвращает ID подкатегории и всех ее потомков
+func (r *SubcategoryRepository) GetDescendantIDs(subcategory *models.Subcategory) ([]uint, error) {
+	var ids []uint
+	for _, other := range r.find(func(other models.Subcategory) bool {
+		return other.CategoryID == subcategory.CategoryID &&
+			(other.Path == subcategory.Path || strings.HasPrefix(other.Path, subcategory.Path+"/"))
+	}) {
+		ids = append(ids, other.ID)
+	}
+	return ids, nil
+}
+
+// find возвращает подкатегории с категориями, подходящие под условие
+func (r *SubcategoryRepository) find(match func(models.Subcategory) bool) []models.Subcategory {
+	r.c.mu.RLock()
+	defer r.c.mu.RUnlock()
+
+	var subcategories []models.Subcategory
+	for _, id := range sortedIDs(r.c.subcategories) {
+		subcategory := r.c.subcategory(r.c.subcategories[id])
+		if match(subcategory) {
+			subcategories = append(subcategories, subcategory)
+		}
+	}
+	return subcategories
+}
+
+// createSubcategory добавляет подкатегорию и ее потомков из Children.
+// Вызывается под блокировкой каталога
+func (c *Catalog) createSubcategory(subcategory *models.Subcategory) error {
+	if _, ok := c.categories[subcategory.CategoryID]; !ok && subcategory.ParentID == nil {
+		return gorm.ErrRecordNotFound
+	}
+
+	c.lastSubcategoryID++
+	subcategory.ID = c.lastSubcategoryID
+	if err := c.saveSubcategory(subcategory); err != nil {
+		delete(c.subcategories, subcategory.ID)
+		return err
+	}
+
+	for i := range subcategory.Children {
+		parentID := subcategory.ID
+		child := &subcategory.Children[i]
+		child.ParentID = &parentID
+		child.CategoryID = subcategory.CategoryID
+		if err := c.createSubcategory(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// saveSubcategory генерирует слаг, вычисляет путь по родителю, сохраняет
+// подкатегорию и пересчитывает пути уже сохраненных потомков, как хуки модели.
+// Вызывается под блокировкой каталога
+func (c *Catalog) saveSubcategory(subcategory *models.Subcategory) error {
+	if subcategory.Slug == "" {
+		subcategory.Slug = uniqueSlug(subcategory.Name, func(candidate string) bool {
+			for _, other := range c.subcategories {
+				if other.ID != subcategory.ID && other.Slug == candidate && isSibling(other, subcategory) {
+					return true
+				}
+			}
+			return false
+		})
+	}
+
+	if subcategory.ParentID == nil {
+		subcategory.Path = subcategory.Slug
+	} else {
+		parent, ok := c.subcategories[*subcategory.ParentID]
+		if !ok {
+			return gorm.ErrRecordNotFound
+		}
+		subcategory.CategoryID = parent.CategoryID
+		subcategory.Path = parent.Path + "/" + subcategory.Slug
+	}
+
+	for _, other := range c.subcategories {
+		if other.ID != subcategory.ID && other.CategoryID == subcategory.CategoryID && other.Path == subcategory.Path {
+			return fmt.Errorf("путь подкатегории %q уже занят", subcategory.Path)
+		}
+	}
+
+	_, exists := c.subcategories[subcategory.ID]
+	touch(&subcategory.Model, !exists)
+	stored := *subcategory
+	stored.Children = nil
+	stored.Products = nil
+	stored.Category = models.Category{}
+	if stored.ParentID != nil {
+		parentID := *stored.ParentID
+		stored.ParentID = &parentID
+	}
+	c.subcategories[subcategory.ID] = stored
+
+	if !exists {
+		return nil
+	}
+	for _, child := range c.children(subcategory.ID) {
+		child.Category = models.Category{}
+		if err := c.saveSubcategory(&child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// children возвращает дочерние узлы подкатегории в порядке добавления
+func (c *Catalog) children(parentID uint) []models.Subcategory {
+	var children []models.Subcategory
+	for _, id := range sortedIDs(c.subcategories) {
+		child := c.subcategories[id]
+		if child.ParentID != nil && *child.ParentID == parentID {
+			children = append(children, child)
+		}
+	}
+	return children
+}
+
+// isSibling сообщает, находятся ли подкатегории на одном уровне дерева
+func isSibling(a models.Subcategory, b *models.Subcategory) bool {
+	if a.ParentID == nil || b.ParentID == nil {
+		return a.ParentID == nil && b.ParentID == nil && a.CategoryID == b.CategoryID
+	}
+	return *a.ParentID == *b.ParentID
+}
@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"cosmetics_catalog/models"
+	"cosmetics_catalog/promo"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OrderRepository struct {
+	db *gorm.DB
+}
+
+// NewOrderRepository создает новый экземпляр репозитория заказов
+func NewOrderRepository(db *gorm.DB) *OrderRepository {
+	return &OrderRepository{db: db}
+}
+
+// Create добавляет новый заказ вместе с позициями
+func (r *OrderRepository) Create(order *models.Order) error {
+	return r.db.Create(order).Error
+}
+
+// Place оформляет заказ и, если к нему применен промокод,
+// фиксирует его использование в той же транзакции. Если лимит использований
+// промокода исчерпан, пока покупатель оформлял заказ, заказ не создается
+// и возвращается ошибка promo.ErrUsageLimit или promo.ErrCustomerLimit
+func (r *OrderRepository) Place(order *models.Order, promoCodeID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if promoCodeID != 0 {
+			if err := checkPromoLimits(tx, promoCodeID, order.CustomerID); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Create(order).Error; err != nil {
+			return err
+		}
+		if promoCodeID == 0 {
+			return nil
+		}
+
+		return tx.Create(&models.PromoRedemption{
+			PromoCodeID: promoCodeID,
+			CustomerID:  order.CustomerID,
+			OrderID:     order.ID,
+			Discount:    order.Discount,
+		}).Error
+	})
+}
+
+// checkPromoLimits пересчитывает использования промокода внутри транзакции.
+// Строка промокода блокируется до конца транзакции (SELECT ... FOR UPDATE
+// в PostgreSQL), поэтому параллельные заказы с тем же промокодом
+// проверяются по очереди. SQLite выполняет пишущие транзакции по одной
+// и пропускает блокировку
+func checkPromoLimits(tx *gorm.DB, codeID uint, customerID *uint) error {
+	var code models.PromoCode
+	err := tx.
+		Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).
+		First(&code, codeID).
+		Error
+	if err != nil {
+		return err
+	}
+
+	var usage promo.Usage
+	if customerID != nil {
+		usage.CustomerID = *customerID
+	}
+	usage.Total, usage.Customer, err = countRedemptions(tx, code.ID, usage.CustomerID)
+	if err != nil {
+		return err
+	}
+	return promo.CheckLimits(&code, usage)
+}
+
+// GetByCustomer возвращает заказы покупателя, новые сверху
+func (r *OrderRepository) GetByCustomer(customerID uint) ([]models.Order, error) {
+	var orders []models.Order
+	err := r.db.
+		Where("customer_id = ?", customerID).
+		Order("created_at DESC").
+		Preload("Items").
+		Find(&orders).
+		Error
+	return orders, err
+}
@@ -6,6 +6,9 @@ import (
 	"gorm.io/gorm"
 )
 
+// ProductHook функция, вызываемая при изменении продукта
+type ProductHook = models.SaleHook
+
 type ProductRepository struct {
 	db *gorm.DB
 }
@@ -31,18 +34,70 @@ func (r *ProductRepository) GetByID(id uint) (*models.Product, error) {
 	return &product, err
 }
 
-// GetOnSale возвращает товары со скидкой
-func (r *ProductRepository) GetProductsOnSale() ([]models.Product, error) {
+// GetByIDs возвращает продукты по списку ID в том же порядке.
+// Отсутствующие в базе ID пропускаются
+func (r *ProductRepository) GetByIDs(ids []uint) ([]models.Product, error) {
+	var found []models.Product
+	err := r.db.
+		Where("id IN ?", ids).
+		Preload("Brand").
+		Preload("Subcategory.Category").
+		Find(&found).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint]models.Product, len(found))
+	for _, product := range found {
+		byID[product.ID] = product
+	}
+
+	products := make([]models.Product, 0, len(found))
+	for _, id := range ids {
+		if product, ok := byID[id]; ok {
+			products = append(products, product)
+		}
+	}
+	return products, nil
+}
+
+// GetAll возвращает все продукты каталога по порядку добавления
+func (r *ProductRepository) GetAll() ([]models.Product, error) {
 	var products []models.Product
 	err := r.db.
-		Where("is_on_sale = ?", true).
 		Preload("Brand").
 		Preload("Subcategory.Category").
+		Order("id").
 		Find(&products).
 		Error
 	return products, err
 }
 
+// GetOnSale возвращает товары со скидкой с фильтром по цене
+func (r *ProductRepository) GetOnSale(sort string, minPrice, maxPrice float64) ([]models.Product, error) {
+	query := r.db.
+		Where("is_on_sale = ?", true).
+		Preload("Brand").
+		Preload("Subcategory.Category")
+
+	var products []models.Product
+	err := filterByPrice(query, sort, minPrice, maxPrice).Find(&products).Error
+	return products, err
+}
+
+// GetByBrand возвращает продукты бренда с фильтром по цене
+func (r *ProductRepository) GetByBrand(brandID uint, sort string, minPrice, maxPrice float64) ([]models.Product, error) {
+	query := r.db.
+		Where("brand_id = ?", brandID).
+		Preload("Brand").
+		Preload("Subcategory.Category")
+
+	var products []models.Product
+	err := filterByPrice(query, sort, minPrice, maxPrice).Find(&products).Error
+	return products, err
+}
+
 // Получить все продукты по категории
 func (r *ProductRepository) GetByCategory(categoryID uint) ([]models.Product, error) {
 	var products []models.Product
@@ -63,19 +118,166 @@ func (r *ProductRepository) GetBySubcategory(subcategoryID uint) ([]models.Produ
 	return products, err
 }
 
+// GetBySubcategories возвращает продукты из списка подкатегорий с фильтром по цене.
+// sort принимает значения "asc" и "desc", нулевые границы цены не ограничивают выборку
+func (r *ProductRepository) GetBySubcategories(subcategoryIDs []uint, sort string, minPrice, maxPrice float64) ([]models.Product, error) {
+	query := r.db.
+		Where("subcategory_id IN ?", subcategoryIDs).
+		Preload("Brand").
+		Preload("Subcategory.Category")
+
+	var products []models.Product
+	err := filterByPrice(query, sort, minPrice, maxPrice).Find(&products).Error
+	return products, err
+}
+
+// filterByPrice ограничивает выборку продуктов ценой и сортирует по ней
+func filterByPrice(query *gorm.DB, sort string, minPrice, maxPrice float64) *gorm.DB {
+	if minPrice > 0 {
+		query = query.Where("price >= ?", minPrice)
+	}
+	if maxPrice > 0 {
+		query = query.Where("price <= ?", maxPrice)
+	}
+
+	switch sort {
+	case "asc":
+		query = query.Order("price ASC")
+	case "desc":
+		query = query.Order("price DESC")
+	}
+	return query
+}
+
+// GetRecentSales возвращает продукты, недавно попавшие в акцию, новые сверху.
+// Если brandID не равен нулю, выбираются только продукты бренда.
+// Для продуктов без даты начала акции используется дата создания
+func (r *ProductRepository) GetRecentSales(brandID uint, limit int) ([]models.Product, error) {
+	query := r.db.
+		Where("is_on_sale = ?", true).
+		Preload("Brand").
+		Preload("Subcategory.Category")
+	if brandID != 0 {
+		query = query.Where("brand_id = ?", brandID)
+	}
+
+	var products []models.Product
+	err := query.
+		Order("COALESCE(sale_started_at, created_at) DESC").
+		Limit(limit).
+		Find(&products).
+		Error
+	return products, err
+}
+
+// GetNewArrivals возвращает недавно добавленные продукты, новые сверху.
+// Если brandID не равен нулю, выбираются только продукты бренда
+func (r *ProductRepository) GetNewArrivals(brandID uint, limit int) ([]models.Product, error) {
+	query := r.db.
+		Preload("Brand").
+		Preload("Subcategory.Category")
+	if brandID != 0 {
+		query = query.Where("brand_id = ?", brandID)
+	}
+
+	var products []models.Product
+	err := query.
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&products).
+		Error
+	return products, err
+}
+
 // Получить продукт по слагу
 func (r *ProductRepository) GetBySlug(slug string) (*models.Product, error) {
 	var product models.Product
-	err := r.db.Where("slug = ?", slug).First(&product).Error
+	err := r.db.
+		Preload("Brand").
+		Preload("Subcategory.Category").
+		Where("slug = ?", slug).
+		First(&product).
+		Error
 	return &product, err
 }
 
+// GetByFilter возвращает продукты под фильтром по порядку добавления
+func (r *ProductRepository) GetByFilter(filter ProductFilter) ([]models.Product, error) {
+	query := r.db.
+		Preload("Brand").
+		Preload("Subcategory.Category").
+		Order("id")
+	if filter.BrandID != 0 {
+		query = query.Where("brand_id = ?", filter.BrandID)
+	}
+	if filter.CategoryID != 0 {
+		query = query.Where("subcategory_id IN (?)",
+			r.db.Model(&models.Subcategory{}).Select("id").Where("category_id = ?", filter.CategoryID))
+	}
+	if len(filter.SubcategoryIDs) > 0 {
+		query = query.Where("subcategory_id IN ?", filter.SubcategoryIDs)
+	}
+	if filter.OnSale {
+		query = query.Where("is_on_sale = ?", true)
+	}
+
+	var products []models.Product
+	err := query.Find(&products).Error
+	return products, err
+}
+
+// Batches читает продукты пачками, чтобы не держать весь каталог в памяти
+func (r *ProductRepository) Batches(size int, fn func(products []models.Product) error) error {
+	var batch []models.Product
+	return r.db.
+		Preload("Brand").
+		Preload("Subcategory.Category").
+		Order("id").
+		FindInBatches(&batch, size, func(_ *gorm.DB, _ int) error {
+			return fn(batch)
+		}).
+		Error
+}
+
+// UpdatePrices меняет цены и записывает историю одной транзакцией.
+// Условие на старые цены защищает от изменений, сделанных после расчета
+func (r *ProductRepository) UpdatePrices(changes []models.PriceHistory) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, change := range changes {
+			result := tx.Model(&models.Product{}).
+				Where("id = ? AND price = ? AND sale_price = ?", change.ProductID, change.OldPrice, change.OldSalePrice).
+				Updates(map[string]interface{}{
+					"price":      change.NewPrice,
+					"sale_price": change.NewSalePrice,
+				})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return ErrPriceChanged
+			}
+		}
+		return tx.CreateInBatches(changes, 100).Error
+	})
+}
+
+// OnSale регистрирует обработчик, вызываемый когда продукт попадает в акцию.
+// Начало акции отслеживает хук модели, см. models.WithSaleHook
+func (r *ProductRepository) OnSale(hook ProductHook) {
+	r.db = r.db.WithContext(models.WithSaleHook(r.db.Statement.Context, hook))
+}
+
 // Update обновляет продукт с проверкой существования
 func (r *ProductRepository) Update(product *models.Product) error {
 	// Проверяем, существует ли продукт
 	if err := r.db.First(&models.Product{}, product.ID).Error; err != nil {
 		return err // Продукт не найден
 	}
+
+	// Дату начала акции запоминает и подписчиков уведомляет хук модели
 	return r.db.Save(product).Error
 }
 
@@ -88,11 +290,11 @@ func (r *ProductRepository) Delete(id uint) error {
 	return r.db.Delete(&models.Product{}, id).Error
 }
 
-// SearchByName поиск по названию с пагинацией
+// SearchByName поиск по названию без учета регистра с пагинацией
 func (r *ProductRepository) SearchByName(query string, limit, offset int) ([]models.Product, error) {
 	var products []models.Product
 	err := r.db.
-		Where("name LIKE ?", "%"+query+"%").
+		Scopes(nameContains(query)).
 		Limit(limit).
 		Offset(offset).
 		Preload("Brand").
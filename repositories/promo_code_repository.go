@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"cosmetics_catalog/models"
+
+	"gorm.io/gorm"
+)
+
+type PromoCodeRepository struct {
+	db *gorm.DB
+}
+
+// NewPromoCodeRepository создает новый экземпляр репозитория промокодов
+func NewPromoCodeRepository(db *gorm.DB) *PromoCodeRepository {
+	return &PromoCodeRepository{db: db}
+}
+
+// Create добавляет новый промокод
+func (r *PromoCodeRepository) Create(code *models.PromoCode) error {
+	return r.db.Create(code).Error
+}
+
+// GetByCode возвращает промокод вместе с ограничениями по брендам и категориям
+func (r *PromoCodeRepository) GetByCode(code string) (*models.PromoCode, error) {
+	var promoCode models.PromoCode
+	err := r.db.
+		Preload("Brands").
+		Preload("Categories").
+		Where("code = ?", code).
+		First(&promoCode).
+		Error
+	return &promoCode, err
+}
+
+// CountRedemptions возвращает, сколько раз промокод использован всего
+// и сколько раз — покупателем customerID
+func (r *PromoCodeRepository) CountRedemptions(codeID, customerID uint) (total, customer int64, err error) {
+	return countRedemptions(r.db, codeID, customerID)
+}
+
+func countRedemptions(db *gorm.DB, codeID, customerID uint) (total, customer int64, err error) {
+	err = db.
+		Model(&models.PromoRedemption{}).
+		Where("promo_code_id = ?", codeID).
+		Count(&total).
+		Error
+	if err != nil || customerID == 0 {
+		return total, 0, err
+	}
+
+	err = db.
+		Model(&models.PromoRedemption{}).
+		Where("promo_code_id = ? AND customer_id = ?", codeID, customerID).
+		Count(&customer).
+		Error
+	return total, customer, err
+}
@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Порядок списков брендов и категорий
+const (
+	// ListByName по алфавиту
+	ListByName = "name"
+	// ListByProducts по убыванию числа продуктов, при равенстве по алфавиту
+	ListByProducts = "products"
+)
+
+// listOrder возвращает выражение ORDER BY для списка записей table в порядке order.
+// Пустой или неизвестный порядок заменяется сортировкой по колонке byDefault
+func listOrder(order, table, byDefault string) string {
+	switch order {
+	case ListByName:
+		return table + ".name"
+	case ListByProducts:
+		return "product_count DESC, " + table + ".name"
+	}
+	return table + "." + byDefault
+}
+
+// nameContains отбирает записи, в названии которых есть query, без учета регистра.
+// LIKE в PostgreSQL учитывает регистр, там используется ILIKE. Встроенные LIKE
+// и LOWER в SQLite меняют регистр только у латиницы, поэтому название приводится
+// к нижнему регистру функцией unicode_lower, которую регистрирует database.Connect
+func nameContains(query string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		pattern := "%" + escapeLike(query) + "%"
+		if db.Dialector.Name() == "postgres" {
+			return db.Where("name ILIKE ? ESCAPE '\\'", pattern)
+		}
+		return db.Where("unicode_lower(name) LIKE ? ESCAPE '\\'", strings.ToLower(pattern))
+	}
+}
+
+// limitRows ограничивает число записей, если limit больше нуля
+func limitRows(limit int) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if limit > 0 {
+			return db.Limit(limit)
+		}
+		return db
+	}
+}
+
+// escapeLike экранирует спецсимволы шаблона LIKE
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
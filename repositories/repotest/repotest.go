@@ -0,0 +1,59 @@
+// Package repotest наполняет каталог одинаковыми данными для тестов
+// репозиториев и пакетов, которые с ними работают
+package repotest
+
+import (
+	"cosmetics_catalog/models"
+	"cosmetics_catalog/repositories"
+	"testing"
+)
+
+// Fixture небольшой каталог: у бренда Альфа два продукта, у Беты один, у Гаммы нет.
+// В категории Уход подкатегория Лицо с вложенной подкатегорией Кремы,
+// в категории Макияж подкатегория Глаза. Продукты не в акции
+type Fixture struct {
+	Alpha, Beta, Gamma models.Brand
+	Care, Makeup       models.Category
+	Face, Creams, Eyes models.Subcategory
+	Cream, Mask, Ink   models.Product
+}
+
+// Seed создает в каталоге данные Fixture
+func Seed(t testing.TB, catalog repositories.Catalog) *Fixture {
+	t.Helper()
+	f := &Fixture{
+		Alpha:  models.Brand{Name: "Альфа"},
+		Beta:   models.Brand{Name: "Бета"},
+		Gamma:  models.Brand{Name: "Гамма"},
+		Care:   models.Category{Name: "Уход"},
+		Makeup: models.Category{Name: "Макияж"},
+	}
+	for _, brand := range []*models.Brand{&f.Alpha, &f.Beta, &f.Gamma} {
+		mustOK(t, catalog.Brands.Create(brand))
+	}
+	for _, category := range []*models.Category{&f.Care, &f.Makeup} {
+		mustOK(t, catalog.Categories.Create(category))
+	}
+
+	f.Face = models.Subcategory{Name: "Лицо", CategoryID: f.Care.ID}
+	mustOK(t, catalog.Subcategories.Create(&f.Face))
+	f.Creams = models.Subcategory{Name: "Кремы", CategoryID: f.Care.ID, ParentID: &f.Face.ID}
+	mustOK(t, catalog.Subcategories.Create(&f.Creams))
+	f.Eyes = models.Subcategory{Name: "Глаза", CategoryID: f.Makeup.ID}
+	mustOK(t, catalog.Subcategories.Create(&f.Eyes))
+
+	f.Cream = models.Product{Name: "Крем для лица", BrandID: f.Alpha.ID, SubcategoryID: f.Creams.ID, Price: 1000}
+	f.Mask = models.Product{Name: "Маска КРЕМОВАЯ", BrandID: f.Alpha.ID, SubcategoryID: f.Face.ID, Price: 500}
+	f.Ink = models.Product{Name: "Тушь 100% объем", BrandID: f.Beta.ID, SubcategoryID: f.Eyes.ID, Price: 700}
+	for _, product := range []*models.Product{&f.Cream, &f.Mask, &f.Ink} {
+		mustOK(t, catalog.Products.Create(product))
+	}
+	return f
+}
+
+func mustOK(t testing.TB, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"cosmetics_catalog/models"
+
+	"gorm.io/gorm"
+)
+
+type ReviewRepository struct {
+	db *gorm.DB
+}
+
+// NewReviewRepository создает новый экземпляр репозитория отзывов
+func NewReviewRepository(db *gorm.DB) *ReviewRepository {
+	return &ReviewRepository{db: db}
+}
+
+// Create добавляет новый отзыв
+func (r *ReviewRepository) Create(review *models.Review) error {
+	return r.db.Create(review).Error
+}
+
+// GetByProduct возвращает отзывы о продукте, новые сверху
+func (r *ReviewRepository) GetByProduct(productID uint) ([]models.Review, error) {
+	var reviews []models.Review
+	err := r.db.
+		Where("product_id = ?", productID).
+		Order("created_at DESC").
+		Find(&reviews).
+		Error
+	return reviews, err
+}
+
+// GetByCustomer возвращает отзывы покупателя, новые сверху.
+// Продукты отзывов не загружаются, их берут из репозитория каталога
+func (r *ReviewRepository) GetByCustomer(customerID uint) ([]models.Review, error) {
+	var reviews []models.Review
+	err := r.db.
+		Where("customer_id = ?", customerID).
+		Order("created_at DESC").
+		Find(&reviews).
+		Error
+	return reviews, err
+}
+
+// GetRatings возвращает сводные оценки для списка продуктов.
+// Продукты без отзывов в результат не попадают
+func (r *ReviewRepository) GetRatings(productIDs []uint) (map[uint]models.Rating, error) {
+	var rows []struct {
+		ProductID uint
+		Average   float64
+		Count     int64
+	}
+	err := r.db.
+		Model(&models.Review{}).
+		Select("product_id, AVG(rating) AS average, COUNT(*) AS count").
+		Where("product_id IN ?", productIDs).
+		Group("product_id").
+		Scan(&rows).
+		Error
+
+	ratings := make(map[uint]models.Rating, len(rows))
+	for _, row := range rows {
+		ratings[row.ProductID] = models.Rating{Average: row.Average, Count: row.Count}
+	}
+	return ratings, err
+}
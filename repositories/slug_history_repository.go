@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"cosmetics_catalog/models"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type SlugHistoryRepository struct {
+	db *gorm.DB
+}
+
+// NewSlugHistoryRepository создает новый экземпляр репозитория истории слагов
+func NewSlugHistoryRepository(db *gorm.DB) *SlugHistoryRepository {
+	return &SlugHistoryRepository{db: db}
+}
+
+// FindProduct находит продукт по его прежнему слагу
+func (r *SlugHistoryRepository) FindProduct(slug string) (*models.Product, error) {
+	var product models.Product
+	id, err := r.findEntityID(models.SlugEntityProduct, slug, 0)
+	if err != nil {
+		return &product, err
+	}
+	err = r.db.Preload("Brand").Preload("Subcategory.Category").First(&product, id).Error
+	return &product, err
+}
+
+// FindBrand находит бренд по его прежнему слагу
+func (r *SlugHistoryRepository) FindBrand(slug string) (*models.Brand, error) {
+	var brand models.Brand
+	id, err := r.findEntityID(models.SlugEntityBrand, slug, 0)
+	if err != nil {
+		return &brand, err
+	}
+	err = r.db.First(&brand, id).Error
+	return &brand, err
+}
+
+// FindCategory находит категорию по ее прежнему слагу
+func (r *SlugHistoryRepository) FindCategory(slug string) (*models.Category, error) {
+	var category models.Category
+	id, err := r.findEntityID(models.SlugEntityCategory, slug, 0)
+	if err != nil {
+		return &category, err
+	}
+	err = r.db.First(&category, id).Error
+	return &category, err
+}
+
+// FindSubcategory находит подкатегорию по ее прежнему пути в категории
+func (r *SlugHistoryRepository) FindSubcategory(categoryID uint, path string) (*models.Subcategory, error) {
+	var subcategory models.Subcategory
+	id, err := r.findEntityID(models.SlugEntitySubcategory, path, categoryID)
+	if err != nil {
+		return &subcategory, err
+	}
+	err = r.db.Preload("Category").First(&subcategory, id).Error
+	return &subcategory, err
+}
+
+// findEntityID возвращает ID сущности, которой слаг принадлежал последним
+func (r *SlugHistoryRepository) findEntityID(entityType, slug string, categoryID uint) (uint, error) {
+	var history models.SlugHistory
+	err := r.db.
+		Where("entity_type = ? AND slug = ? AND category_id = ?", entityType, strings.ToLower(slug), categoryID).
+		Order("id DESC").
+		First(&history).
+		Error
+	return history.EntityID, err
+}
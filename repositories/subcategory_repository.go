@@ -0,0 +1,114 @@
+package repositories
+
+import (
+	"cosmetics_catalog/models"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type SubcategoryRepository struct {
+	db *gorm.DB
+}
+
+// NewSubcategoryRepository создает новый экземпляр репозитория подкатегорий
+func NewSubcategoryRepository(db *gorm.DB) *SubcategoryRepository {
+	return &SubcategoryRepository{db: db}
+}
+
+// Create добавляет новую подкатегорию. Путь вычисляется по родителю
+func (r *SubcategoryRepository) Create(subcategory *models.Subcategory) error {
+	return r.db.Create(subcategory).Error
+}
+
+// Update сохраняет подкатегорию и обновляет пути ее потомков
+func (r *SubcategoryRepository) Update(subcategory *models.Subcategory) error {
+	// Проверяем, существует ли подкатегория
+	if err := r.db.First(&models.Subcategory{}, subcategory.ID).Error; err != nil {
+		return err
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return tx.Save(subcategory).Error
+	})
+}
+
+// GetByID возвращает подкатегорию по ID вместе с категорией
+func (r *SubcategoryRepository) GetByID(id uint) (*models.Subcategory, error) {
+	var subcategory models.Subcategory
+	err := r.db.Preload("Category").First(&subcategory, id).Error
+	return &subcategory, err
+}
+
+// GetByPath находит подкатегорию категории по пути из слагов, например "uxod/liczo"
+func (r *SubcategoryRepository) GetByPath(categoryID uint, path string) (*models.Subcategory, error) {
+	var subcategory models.Subcategory
+	err := r.db.
+		Preload("Category").
+		Preload("Children", func(db *gorm.DB) *gorm.DB {
+			return db.Order("name")
+		}).
+		Where("category_id = ? AND path = ?", categoryID, strings.ToLower(path)).
+		First(&subcategory).
+		Error
+	return &subcategory, err
+}
+
+// GetAll возвращает все подкатегории с категориями, упорядоченные по категории и пути
+func (r *SubcategoryRepository) GetAll() ([]models.Subcategory, error) {
+	var subcategories []models.Subcategory
+	err := r.db.Preload("Category").Order("category_id, path").Find(&subcategories).Error
+	return subcategories, err
+}
+
+// GetRoots возвращает подкатегории верхнего уровня категории
+func (r *SubcategoryRepository) GetRoots(categoryID uint) ([]models.Subcategory, error) {
+	var subcategories []models.Subcategory
+	err := r.db.
+		Where("category_id = ? AND parent_id IS NULL", categoryID).
+		Find(&subcategories).
+		Error
+	return subcategories, err
+}
+
+// GetAncestors возвращает предков подкатегории от корня к родителю
+func (r *SubcategoryRepository) GetAncestors(subcategory *models.Subcategory) ([]models.Subcategory, error) {
+	paths := subcategory.AncestorPaths()
+	if len(paths) == 0 {
+		return nil, nil
+	}
+
+	var ancestors []models.Subcategory
+	err := r.db.
+		Preload("Category").
+		Where("category_id = ? AND path IN ?", subcategory.CategoryID, paths).
+		Find(&ancestors).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Упорядочиваем по глубине: путь предка короче пути потомка
+	byPath := make(map[string]models.Subcategory, len(ancestors))
+	for _, ancestor := range ancestors {
+		byPath[ancestor.Path] = ancestor
+	}
+	ordered := make([]models.Subcategory, 0, len(paths))
+	for _, path := range paths {
+		if ancestor, ok := byPath[path]; ok {
+			ordered = append(ordered, ancestor)
+		}
+	}
+	return ordered, nil
+}
+
+// GetDescendantIDs возвращает ID подкатегории и всех ее потомков
+func (r *SubcategoryRepository) GetDescendantIDs(subcategory *models.Subcategory) ([]uint, error) {
+	var ids []uint
+	err := r.db.
+		Model(&models.Subcategory{}).
+		Where("category_id = ?", subcategory.CategoryID).
+		Where("path = ? OR path LIKE ? ESCAPE '\\'", subcategory.Path, escapeLike(subcategory.Path)+"/%").
+		Pluck("id", &ids).
+		Error
+	return ids, err
+}
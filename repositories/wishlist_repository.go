@@ -0,0 +1,144 @@
+package repositories
+
+import (
+	"cosmetics_catalog/models"
+
+	"gorm.io/gorm"
+)
+
+// WishlistOwner владелец избранного: сессия посетителя
+// и покупатель, если посетитель вошел в аккаунт
+type WishlistOwner struct {
+	SessionID  string
+	CustomerID uint
+}
+
+// scope ограничивает запрос записями избранного владельца
+func (o WishlistOwner) scope(db *gorm.DB) *gorm.DB {
+	if o.CustomerID != 0 {
+		return db.Where("wishlist_items.customer_id = ?", o.CustomerID)
+	}
+	return db.Where("wishlist_items.session_id = ?", o.SessionID)
+}
+
+type WishlistRepository struct {
+	db *gorm.DB
+}
+
+// NewWishlistRepository создает новый экземпляр репозитория избранного
+func NewWishlistRepository(db *gorm.DB) *WishlistRepository {
+	return &WishlistRepository{db: db}
+}
+
+// Add добавляет товар в избранное. Существование товара проверяет
+// вызывающий код: каталог может храниться не в этой базе
+func (r *WishlistRepository) Add(owner WishlistOwner, productID uint) error {
+	var count int64
+	err := r.db.
+		Model(&models.WishlistItem{}).
+		Scopes(owner.scope).
+		Where("product_id = ?", productID).
+		Count(&count).
+		Error
+	if err != nil || count > 0 {
+		return err
+	}
+
+	item := models.WishlistItem{SessionID: owner.SessionID, ProductID: productID}
+	if owner.CustomerID != 0 {
+		item.CustomerID = &owner.CustomerID
+	}
+	return r.db.Create(&item).Error
+}
+
+// Remove удаляет товар из избранного
+func (r *WishlistRepository) Remove(owner WishlistOwner, productID uint) error {
+	return r.db.
+		Unscoped().
+		Scopes(owner.scope).
+		Where("product_id = ?", productID).
+		Delete(&models.WishlistItem{}).
+		Error
+}
+
+// Toggle добавляет товар в избранное или убирает его, если он уже там.
+// Возвращает true, если после вызова товар находится в избранном
+func (r *WishlistRepository) Toggle(owner WishlistOwner, productID uint) (bool, error) {
+	var count int64
+	err := r.db.
+		Model(&models.WishlistItem{}).
+		Scopes(owner.scope).
+		Where("product_id = ?", productID).
+		Count(&count).
+		Error
+	if err != nil {
+		return false, err
+	}
+
+	if count > 0 {
+		return false, r.Remove(owner, productID)
+	}
+	return true, r.Add(owner, productID)
+}
+
+// GetRecentProductIDs возвращает ID избранных товаров, новые сверху
+func (r *WishlistRepository) GetRecentProductIDs(owner WishlistOwner) ([]uint, error) {
+	var ids []uint
+	err := r.db.
+		Model(&models.WishlistItem{}).
+		Scopes(owner.scope).
+		Order("created_at DESC").
+		Pluck("product_id", &ids).
+		Error
+	return ids, err
+}
+
+// GetProductIDs возвращает множество ID избранных товаров
+func (r *WishlistRepository) GetProductIDs(owner WishlistOwner) (map[uint]bool, error) {
+	var ids []uint
+	err := r.db.
+		Model(&models.WishlistItem{}).
+		Scopes(owner.scope).
+		Pluck("product_id", &ids).
+		Error
+
+	result := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		result[id] = true
+	}
+	return result, err
+}
+
+// AssignToCustomer переносит избранное сессии в аккаунт покупателя.
+// Товары, которые уже есть в избранном покупателя, не дублируются
+func (r *WishlistRepository) AssignToCustomer(sessionID string, customerID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Удаляем из сессии товары, которые уже есть у покупателя
+		err := tx.
+			Unscoped().
+			Where("session_id = ? AND customer_id IS NULL", sessionID).
+			Where("product_id IN (?)", tx.
+				Model(&models.WishlistItem{}).
+				Select("product_id").
+				Where("customer_id = ?", customerID)).
+			Delete(&models.WishlistItem{}).
+			Error
+		if err != nil {
+			return err
+		}
+
+		return tx.
+			Model(&models.WishlistItem{}).
+			Where("session_id = ? AND customer_id IS NULL", sessionID).
+			Update("customer_id", customerID).
+			Error
+	})
+}
+
+// GetSubscribers возвращает записи избранного, в которых есть товар,
+// вместе с покупателями, если записи привязаны к аккаунту
+func (r *WishlistRepository) GetSubscribers(productID uint) ([]models.WishlistItem, error) {
+	var items []models.WishlistItem
+	err := r.db.Preload("Customer").Where("product_id = ?", productID).Find(&items).Error
+	return items, err
+}
@@ -0,0 +1,337 @@
+// Package router сопоставляет запросы с обработчиками по методу и шаблону пути
+// и строит адреса по именам маршрутов.
+//
+// Шаблон состоит из сегментов, разделенных "/". Сегмент может быть
+// фиксированным словом, параметром {name} или остатком пути {name...},
+// который захватывает один и более сегментов. Значения параметров
+// доступны обработчику через r.PathValue. Фиксированный сегмент важнее
+// параметра, а параметр важнее остатка пути, поэтому маршрут
+// /catalog/sales срабатывает раньше /catalog/{category}
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Виды сегментов шаблона в порядке убывания приоритета
+const (
+	segmentStatic = iota
+	segmentParam
+	segmentRest
+)
+
+type segment struct {
+	kind  int
+	value string // слово для фиксированного сегмента или имя параметра
+}
+
+// Route маршрут: метод, шаблон пути и обработчик
+type Route struct {
+	method   string
+	pattern  string
+	segments []segment
+	handler  http.Handler
+	router   *Router
+}
+
+// Router маршрутизатор запросов
+type Router struct {
+	routes []*Route
+	names  map[string]*Route
+
+	// NotFound вызывается, если ни один маршрут не подходит под путь
+	NotFound http.HandlerFunc
+	// MethodNotAllowed вызывается, если путь найден, но метод не поддерживается.
+	// Заголовок Allow к этому моменту уже заполнен
+	MethodNotAllowed http.HandlerFunc
+}
+
+// New создает маршрутизатор с ответами 404 и 405 по умолчанию
+func New() *Router {
+	return &Router{
+		names:    map[string]*Route{},
+		NotFound: http.NotFound,
+		MethodNotAllowed: func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		},
+	}
+}
+
+// Handle регистрирует обработчик для метода и шаблона пути.
+// Некорректный шаблон или повторная регистрация приводят к панике
+func (rt *Router) Handle(method, pattern string, handler http.Handler) *Route {
+	segments, err := parsePattern(pattern)
+	if err != nil {
+		panic(err)
+	}
+	for _, existing := range rt.routes {
+		if existing.method == method && samePattern(existing.segments, segments) {
+			panic(fmt.Sprintf("router: маршрут %s %s уже зарегистрирован", method, pattern))
+		}
+	}
+
+	route := &Route{
+		method:   method,
+		pattern:  pattern,
+		segments: segments,
+		handler:  handler,
+		router:   rt,
+	}
+	rt.routes = append(rt.routes, route)
+	return route
+}
+
+// Get регистрирует обработчик GET-запросов. HEAD-запросы обрабатываются им же
+func (rt *Router) Get(pattern string, handler http.HandlerFunc) *Route {
+	return rt.Handle(http.MethodGet, pattern, handler)
+}
+
+// Post регистрирует обработчик POST-запросов
+func (rt *Router) Post(pattern string, handler http.HandlerFunc) *Route {
+	return rt.Handle(http.MethodPost, pattern, handler)
+}
+
+// Name задает имя маршрута для построения адресов через Router.URL.
+// Маршруты с одинаковым шаблоном и разными методами могут носить одно имя
+func (r *Route) Name(name string) *Route {
+	if existing, ok := r.router.names[name]; ok && existing.pattern != r.pattern {
+		panic(fmt.Sprintf("router: имя маршрута %q уже занято шаблоном %s", name, existing.pattern))
+	}
+	r.router.names[name] = r
+	return r
+}
+
+// URL строит адрес именованного маршрута. Параметры передаются парами
+// имя-значение: URL("brand", "brand", "bioderma") вернет /catalog/brands/bioderma
+func (rt *Router) URL(name string, params ...string) (string, error) {
+	route, ok := rt.names[name]
+	if !ok {
+		return "", fmt.Errorf("router: маршрут %q не найден", name)
+	}
+	if len(params)%2 != 0 {
+		return "", fmt.Errorf("router: нечетное число параметров для маршрута %q", name)
+	}
+
+	values := make(map[string]string, len(params)/2)
+	for i := 0; i < len(params); i += 2 {
+		values[params[i]] = params[i+1]
+	}
+
+	parts := make([]string, len(route.segments))
+	for i, seg := range route.segments {
+		if seg.kind == segmentStatic {
+			parts[i] = seg.value
+			continue
+		}
+
+		value, ok := values[seg.value]
+		if !ok || value == "" {
+			return "", fmt.Errorf("router: не задан параметр %q маршрута %q", seg.value, name)
+		}
+		delete(values, seg.value)
+
+		if seg.kind == segmentRest {
+			// Остаток пути сохраняет разделители между сегментами
+			escaped := strings.Split(value, "/")
+			for j := range escaped {
+				escaped[j] = url.PathEscape(escaped[j])
+			}
+			parts[i] = strings.Join(escaped, "/")
+		} else {
+			parts[i] = url.PathEscape(value)
+		}
+	}
+	for param := range values {
+		return "", fmt.Errorf("router: маршрут %q не содержит параметра %q", name, param)
+	}
+
+	return "/" + strings.Join(parts, "/"), nil
+}
+
+// Reserved возвращает фиксированные слова, которые следуют за префиксом
+// в зарегистрированных маршрутах. Например, для префикса "/catalog/"
+// это "sales" и "brands": такие слаги не могут принадлежать категориям
+func (rt *Router) Reserved(prefix string) []string {
+	prefixSegments := splitPath(prefix)
+	if len(prefixSegments) > 0 && prefixSegments[len(prefixSegments)-1] == "" {
+		prefixSegments = prefixSegments[:len(prefixSegments)-1]
+	}
+
+	seen := map[string]bool{}
+	var words []string
+	for _, route := range rt.routes {
+		if len(route.segments) <= len(prefixSegments) {
+			continue
+		}
+		matches := true
+		for i, value := range prefixSegments {
+			if route.segments[i].kind != segmentStatic || route.segments[i].value != value {
+				matches = false
+				break
+			}
+		}
+
+		next := route.segments[len(prefixSegments)]
+		if matches && next.kind == segmentStatic && next.value != "" && !seen[next.value] {
+			seen[next.value] = true
+			words = append(words, next.value)
+		}
+	}
+
+	sort.Strings(words)
+	return words
+}
+
+// ServeHTTP находит подходящий маршрут и вызывает его обработчик
+func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := splitPath(r.URL.Path)
+
+	var best *Route
+	var bestValues map[string]string
+	var allowed []string
+
+	for _, route := range rt.routes {
+		values, ok := route.match(path)
+		if !ok {
+			continue
+		}
+
+		if route.method != r.Method && !(r.Method == http.MethodHead && route.method == http.MethodGet) {
+			allowed = append(allowed, route.method)
+			continue
+		}
+		if best == nil || route.moreSpecific(best) {
+			best = route
+			bestValues = values
+		}
+	}
+
+	if best == nil {
+		if len(allowed) > 0 {
+			w.Header().Set("Allow", strings.Join(uniqueMethods(allowed), ", "))
+			rt.MethodNotAllowed(w, r)
+			return
+		}
+		rt.NotFound(w, r)
+		return
+	}
+
+	for name, value := range bestValues {
+		r.SetPathValue(name, value)
+	}
+	best.handler.ServeHTTP(w, r)
+}
+
+// match сравнивает сегменты пути с шаблоном маршрута
+func (r *Route) match(path []string) (map[string]string, bool) {
+	values := map[string]string{}
+	for i, seg := range r.segments {
+		if seg.kind == segmentRest {
+			rest := path[i:]
+			if len(rest) == 0 || rest[0] == "" || rest[len(rest)-1] == "" {
+				return nil, false
+			}
+			values[seg.value] = strings.Join(rest, "/")
+			return values, true
+		}
+
+		if i >= len(path) {
+			return nil, false
+		}
+		switch seg.kind {
+		case segmentStatic:
+			if path[i] != seg.value {
+				return nil, false
+			}
+		case segmentParam:
+			if path[i] == "" {
+				return nil, false
+			}
+			values[seg.value] = path[i]
+		}
+	}
+	return values, len(path) == len(r.segments)
+}
+
+// moreSpecific сообщает, приоритетнее ли маршрут другого маршрута,
+// подходящего под тот же путь. Сравнение идет слева направо по сегментам
+func (r *Route) moreSpecific(other *Route) bool {
+	for i := 0; i < len(r.segments) && i < len(other.segments); i++ {
+		if r.segments[i].kind != other.segments[i].kind {
+			return r.segments[i].kind < other.segments[i].kind
+		}
+	}
+	return len(r.segments) > len(other.segments)
+}
+
+// parsePattern разбирает шаблон пути на сегменты
+func parsePattern(pattern string) ([]segment, error) {
+	if !strings.HasPrefix(pattern, "/") {
+		return nil, fmt.Errorf("router: шаблон %q должен начинаться с /", pattern)
+	}
+
+	parts := splitPath(pattern)
+	segments := make([]segment, len(parts))
+	names := map[string]bool{}
+	for i, part := range parts {
+		if !strings.HasPrefix(part, "{") || !strings.HasSuffix(part, "}") {
+			segments[i] = segment{kind: segmentStatic, value: part}
+			continue
+		}
+
+		name := strings.TrimSuffix(strings.TrimPrefix(part, "{"), "}")
+		kind := segmentParam
+		if strings.HasSuffix(name, "...") {
+			if i != len(parts)-1 {
+				return nil, fmt.Errorf("router: остаток пути {%s} должен быть последним сегментом шаблона %q", name, pattern)
+			}
+			name = strings.TrimSuffix(name, "...")
+			kind = segmentRest
+		}
+		if name == "" || names[name] {
+			return nil, fmt.Errorf("router: некорректный параметр в шаблоне %q", pattern)
+		}
+		names[name] = true
+		segments[i] = segment{kind: kind, value: name}
+	}
+	return segments, nil
+}
+
+// samePattern сообщает, совпадают ли шаблоны с точностью до имен параметров
+func samePattern(a, b []segment) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].kind != b[i].kind || (a[i].kind == segmentStatic && a[i].value != b[i].value) {
+			return false
+		}
+	}
+	return true
+}
+
+// splitPath делит путь на сегменты. Завершающий "/" дает пустой последний сегмент,
+// поэтому /catalog и /catalog/ — разные пути
+func splitPath(path string) []string {
+	return strings.Split(strings.TrimPrefix(path, "/"), "/")
+}
+
+// uniqueMethods убирает повторы из списка методов для заголовка Allow
+func uniqueMethods(methods []string) []string {
+	seen := map[string]bool{}
+	result := methods[:0]
+	for _, method := range methods {
+		if !seen[method] {
+			seen[method] = true
+			result = append(result, method)
+		}
+	}
+	if seen[http.MethodGet] && !seen[http.MethodHead] {
+		result = append(result, http.MethodHead)
+	}
+	return result
+}
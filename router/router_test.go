@@ -0,0 +1,253 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// testRoutes маршруты, устроенные как каталог приложения
+var testRoutes = []struct {
+	method, pattern, name string
+}{
+	{http.MethodGet, "/", "home"},
+	{http.MethodGet, "/catalog", "catalog.noslash"},
+	{http.MethodGet, "/catalog/", "catalog"},
+	{http.MethodGet, "/catalog/sales", "sales"},
+	{http.MethodGet, "/catalog/sales/feed", "sales.feed"},
+	{http.MethodGet, "/catalog/sales/{product}", "sales.product"},
+	{http.MethodGet, "/catalog/brands", "brands"},
+	{http.MethodGet, "/catalog/brands/{brand}", "brand"},
+	{http.MethodGet, "/catalog/brands/{brand}/feed", "brand.feed"},
+	{http.MethodGet, "/catalog/brands/{brand}/{product}", "brand.product"},
+	{http.MethodGet, "/catalog/{category}", "category"},
+	{http.MethodGet, "/catalog/{category}/{path...}", "catalog.path"},
+	{http.MethodGet, "/admin/prices", "admin.prices"},
+	{http.MethodPost, "/admin/prices", "admin.prices"},
+	{http.MethodGet, "/photos/{file...}", "photo"},
+}
+
+// newTestRouter регистрирует testRoutes. Обработчик отвечает именем маршрута
+// и значениями параметров, например "brand brand=dior".
+// Приоритет маршрутов не должен зависеть от порядка регистрации, поэтому
+// тесты проверяют и прямой, и обратный порядок
+func newTestRouter(reverse bool) *Router {
+	rt := New()
+	routes := slices.Clone(testRoutes)
+	if reverse {
+		slices.Reverse(routes)
+	}
+	for _, route := range routes {
+		segments, err := parsePattern(route.pattern)
+		if err != nil {
+			panic(err)
+		}
+		name := route.name
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			body := []string{name}
+			for _, seg := range segments {
+				if seg.kind != segmentStatic {
+					body = append(body, seg.value+"="+r.PathValue(seg.value))
+				}
+			}
+			w.Write([]byte(strings.Join(body, " ")))
+		}
+		rt.Handle(route.method, route.pattern, http.HandlerFunc(handler)).Name(route.name)
+	}
+	return rt
+}
+
+func serve(rt *Router, method, target string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	rt.ServeHTTP(w, httptest.NewRequest(method, target, nil))
+	return w
+}
+
+func TestServeHTTP(t *testing.T) {
+	tests := []struct {
+		method, target string
+		status         int
+		body           string // ответ обработчика или заголовок Allow для 405
+	}{
+		{"GET", "/", 200, "home"},
+		{"GET", "/catalog", 200, "catalog.noslash"},
+		{"GET", "/catalog/", 200, "catalog"},
+
+		// Фиксированный сегмент важнее параметра, параметр важнее остатка пути
+		{"GET", "/catalog/sales", 200, "sales"},
+		{"GET", "/catalog/sales/feed", 200, "sales.feed"},
+		{"GET", "/catalog/sales/krem", 200, "sales.product product=krem"},
+		{"GET", "/catalog/brands", 200, "brands"},
+		{"GET", "/catalog/brands/dior", 200, "brand brand=dior"},
+		{"GET", "/catalog/brands/dior/feed", 200, "brand.feed brand=dior"},
+		{"GET", "/catalog/brands/dior/krem", 200, "brand.product brand=dior product=krem"},
+		{"GET", "/catalog/uxod", 200, "category category=uxod"},
+		{"GET", "/catalog/uxod/liczo", 200, "catalog.path category=uxod path=liczo"},
+		{"GET", "/catalog/uxod/liczo/kremy/krem", 200, "catalog.path category=uxod path=liczo/kremy/krem"},
+		{"GET", "/catalog/brands/dior/krem/lishnee", 200, "catalog.path category=brands path=dior/krem/lishnee"},
+		{"GET", "/photos/a/b.jpg", 200, "photo file=a/b.jpg"},
+		{"GET", "/catalog/brands/%D0%B1%D0%B8%D0%BE", 200, "brand brand=био"},
+
+		// Пустой параметр или пустой сегмент в остатке пути не подходят
+		{"GET", "/catalog/brands/", 404, ""},
+		{"GET", "/catalog/uxod/", 404, ""},
+		{"GET", "/catalog/uxod/liczo/", 404, ""},
+		{"GET", "/catalog/uxod//krem", 404, ""},
+		{"GET", "/photos/", 404, ""},
+		{"GET", "/net", 404, ""},
+
+		// HEAD обрабатывается маршрутом GET
+		{"HEAD", "/catalog/sales", 200, "sales"},
+
+		{"POST", "/admin/prices", 200, "admin.prices"},
+		{"POST", "/catalog/sales", 405, "GET, HEAD"},
+		{"DELETE", "/admin/prices", 405, "GET, HEAD, POST"},
+	}
+	for _, reverse := range []bool{false, true} {
+		rt := newTestRouter(reverse)
+		for _, tt := range tests {
+			w := serve(rt, tt.method, tt.target)
+			body := w.Body.String()
+			switch tt.status {
+			case http.StatusNotFound:
+				body = ""
+			case http.StatusMethodNotAllowed:
+				// Порядок методов в Allow следует порядку регистрации
+				methods := strings.Split(w.Header().Get("Allow"), ", ")
+				slices.Sort(methods)
+				body = strings.Join(methods, ", ")
+			}
+			if w.Code != tt.status || body != tt.body {
+				t.Errorf("reverse=%v %s %s = %d %q, want %d %q", reverse, tt.method, tt.target, w.Code, body, tt.status, tt.body)
+			}
+		}
+	}
+}
+
+func TestServeHTTPCustomErrors(t *testing.T) {
+	rt := newTestRouter(false)
+	rt.NotFound = func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "нет такой страницы", http.StatusNotFound)
+	}
+	rt.MethodNotAllowed = func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "метод "+w.Header().Get("Allow"), http.StatusMethodNotAllowed)
+	}
+
+	if w := serve(rt, "GET", "/net"); w.Code != 404 || w.Body.String() != "нет такой страницы\n" {
+		t.Errorf("NotFound: %d %q", w.Code, w.Body.String())
+	}
+	if w := serve(rt, "PUT", "/catalog/"); w.Code != 405 || w.Body.String() != "метод GET, HEAD\n" {
+		t.Errorf("MethodNotAllowed: %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestURL(t *testing.T) {
+	tests := []struct {
+		name   string
+		params []string
+		want   string
+	}{
+		{"home", nil, "/"},
+		{"catalog", nil, "/catalog/"},
+		{"sales", nil, "/catalog/sales"},
+		{"brand", []string{"brand", "dior"}, "/catalog/brands/dior"},
+		{"brand.product", []string{"product", "krem", "brand", "dior"}, "/catalog/brands/dior/krem"},
+		{"brand", []string{"brand", "био крем"}, "/catalog/brands/%D0%B1%D0%B8%D0%BE%20%D0%BA%D1%80%D0%B5%D0%BC"},
+		{"category", []string{"category", "uxod"}, "/catalog/uxod"},
+		{"catalog.path", []string{"category", "uxod", "path", "liczo/kremy/krem"}, "/catalog/uxod/liczo/kremy/krem"},
+		{"photo", []string{"file", "a b/c.jpg"}, "/photos/a%20b/c.jpg"},
+		{"admin.prices", nil, "/admin/prices"},
+	}
+	rt := newTestRouter(false)
+	for _, tt := range tests {
+		got, err := rt.URL(tt.name, tt.params...)
+		if err != nil || got != tt.want {
+			t.Errorf("URL(%q, %q) = %q, %v, want %q", tt.name, tt.params, got, err, tt.want)
+			continue
+		}
+
+		// Построенный адрес приводит обратно к тому же маршруту и параметрам
+		body := serve(rt, "GET", got).Body.String()
+		want := []string{tt.name}
+		route := rt.names[tt.name]
+		for _, seg := range route.segments {
+			if seg.kind == segmentStatic {
+				continue
+			}
+			for i := 0; i < len(tt.params); i += 2 {
+				if tt.params[i] == seg.value {
+					want = append(want, seg.value+"="+tt.params[i+1])
+				}
+			}
+		}
+		if body != strings.Join(want, " ") {
+			t.Errorf("GET %s = %q, want %q", got, body, strings.Join(want, " "))
+		}
+	}
+}
+
+func TestURLErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		params []string
+	}{
+		{"net", nil},
+		{"brand", nil},
+		{"brand", []string{"brand"}},
+		{"brand", []string{"brand", ""}},
+		{"brand", []string{"brand", "dior", "product", "krem"}},
+		{"sales", []string{"page", "2"}},
+	}
+	rt := newTestRouter(false)
+	for _, tt := range tests {
+		if got, err := rt.URL(tt.name, tt.params...); err == nil {
+			t.Errorf("URL(%q, %q) = %q, ожидалась ошибка", tt.name, tt.params, got)
+		}
+	}
+}
+
+func TestReserved(t *testing.T) {
+	tests := []struct {
+		prefix string
+		want   []string
+	}{
+		{"/catalog/", []string{"brands", "sales"}},
+		{"/catalog", []string{"brands", "sales"}},
+		{"/catalog/sales/", []string{"feed"}},
+		{"/catalog/brands/", nil},
+		{"/", []string{"admin", "catalog", "photos"}},
+		{"/net/", nil},
+	}
+	rt := newTestRouter(false)
+	for _, tt := range tests {
+		if got := rt.Reserved(tt.prefix); !slices.Equal(got, tt.want) {
+			t.Errorf("Reserved(%q) = %q, want %q", tt.prefix, got, tt.want)
+		}
+	}
+}
+
+func TestHandlePanics(t *testing.T) {
+	tests := []struct {
+		name     string
+		register func(rt *Router)
+	}{
+		{"без начального /", func(rt *Router) { rt.Get("catalog", nil) }},
+		{"остаток пути не в конце", func(rt *Router) { rt.Get("/photos/{file...}/meta", nil) }},
+		{"пустое имя параметра", func(rt *Router) { rt.Get("/catalog/{}", nil) }},
+		{"повтор параметра", func(rt *Router) { rt.Get("/catalog/{slug}/{slug}", nil) }},
+		{"повтор шаблона", func(rt *Router) { rt.Get("/catalog/{slug}", nil) }},
+		{"имя занято другим шаблоном", func(rt *Router) { rt.Get("/catalog/all", nil).Name("sales") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("ожидалась паника")
+				}
+			}()
+			tt.register(newTestRouter(false))
+		})
+	}
+}
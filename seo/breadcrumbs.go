@@ -0,0 +1,101 @@
+// Package seo готовит данные для поисковых систем:
+// хлебные крошки и разметку Schema.org в формате JSON-LD
+package seo
+
+import (
+	"cosmetics_catalog/models"
+	"encoding/json"
+	"html/template"
+)
+
+// Breadcrumb элемент навигационной цепочки
+type Breadcrumb struct {
+	Name string
+	URL  string
+}
+
+// Breadcrumbs навигационная цепочка от главной страницы каталога
+// к текущей странице. Последний элемент соответствует текущей странице
+type Breadcrumbs struct {
+	Items []Breadcrumb
+
+	baseURL string
+}
+
+// NewBreadcrumbs начинает цепочку с главной страницы каталога.
+// baseURL нужен для абсолютных адресов в JSON-LD, например "https://example.com"
+func NewBreadcrumbs(baseURL string) Breadcrumbs {
+	return Breadcrumbs{
+		Items:   []Breadcrumb{{Name: "Каталог", URL: "/catalog/"}},
+		baseURL: baseURL,
+	}
+}
+
+// Add добавляет страницу в конец цепочки
+func (b Breadcrumbs) Add(name, url string) Breadcrumbs {
+	items := make([]Breadcrumb, len(b.Items), len(b.Items)+1)
+	copy(items, b.Items)
+	b.Items = append(items, Breadcrumb{Name: name, URL: url})
+	return b
+}
+
+// Category добавляет категорию
+func (b Breadcrumbs) Category(category models.Category) Breadcrumbs {
+	return b.Add(category.Name, category.URL())
+}
+
+// Subcategory добавляет категорию, предков подкатегории от корня и саму подкатегорию.
+// У подкатегорий должна быть загружена категория
+func (b Breadcrumbs) Subcategory(ancestors []models.Subcategory, subcategory models.Subcategory) Breadcrumbs {
+	b = b.Category(subcategory.Category)
+	for _, ancestor := range ancestors {
+		b = b.Add(ancestor.Name, ancestor.URL())
+	}
+	return b.Add(subcategory.Name, subcategory.URL())
+}
+
+// Brand добавляет список брендов и сам бренд
+func (b Breadcrumbs) Brand(brand models.Brand) Breadcrumbs {
+	return b.Add("Бренды", "/catalog/brands").Add(brand.Name, brand.URL())
+}
+
+// IsLast сообщает, является ли элемент с индексом i текущей страницей
+func (b Breadcrumbs) IsLast(i int) bool {
+	return i == len(b.Items)-1
+}
+
+// JSONLD возвращает цепочку в виде разметки Schema.org BreadcrumbList
+func (b Breadcrumbs) JSONLD() template.JS {
+	type listItem struct {
+		Type     string `json:"@type"`
+		Position int    `json:"position"`
+		Name     string `json:"name"`
+		Item     string `json:"item"`
+	}
+
+	items := make([]listItem, len(b.Items))
+	for i, crumb := range b.Items {
+		items[i] = listItem{
+			Type:     "ListItem",
+			Position: i + 1,
+			Name:     crumb.Name,
+			Item:     b.baseURL + crumb.URL,
+		}
+	}
+
+	return marshalJSONLD(map[string]any{
+		"@context":        "https://schema.org",
+		"@type":           "BreadcrumbList",
+		"itemListElement": items,
+	})
+}
+
+// marshalJSONLD кодирует разметку для вставки в <script type="application/ld+json">.
+// json.Marshal экранирует <, > и &, поэтому разметка не может закрыть тег script
+func marshalJSONLD(v any) template.JS {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return template.JS(data)
+}
@@ -0,0 +1,147 @@
+package seo
+
+import (
+	"cosmetics_catalog/models"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// SiteName название сайта, которое добавляется к сгенерированным заголовкам
+const SiteName = "Каталог косметики"
+
+// descriptionLength рекомендуемая длина meta description в символах
+const descriptionLength = 160
+
+// Meta метаданные страницы для тега title, meta description и OpenGraph
+type Meta struct {
+	Title       string
+	Description string
+	Image       string
+	URL         string
+	Type        string
+	// Feed адрес ленты Atom, на которую можно подписаться со страницы
+	Feed string
+}
+
+// NewMeta создает метаданные служебной страницы
+func NewMeta(title, description string) Meta {
+	return Meta{Title: title + " | " + SiteName, Description: description, Type: "website"}
+}
+
+// ProductMeta возвращает метаданные страницы продукта. Бренд продукта должен быть загружен
+func ProductMeta(product models.Product) Meta {
+	price := product.Price
+	if product.IsOnSale {
+		price = product.SalePrice
+	}
+
+	description := Truncate(product.Description)
+	if description == "" {
+		description = fmt.Sprintf("Купить %s %s по цене %s ₽ в каталоге косметики.",
+			product.Name, product.Brand.Name, formatPrice(price))
+	}
+
+	meta := Meta{
+		Title:       fmt.Sprintf("%s %s — купить за %s ₽ | %s", product.Name, product.Brand.Name, formatPrice(price), SiteName),
+		Description: description,
+		Image:       product.ImagePath,
+		Type:        "product",
+	}
+	return meta.override(product.SEO)
+}
+
+// BrandMeta возвращает метаданные страницы бренда
+func BrandMeta(brand models.Brand) Meta {
+	meta := NewMeta(
+		brand.Name+": косметика бренда",
+		fmt.Sprintf("Косметика %s в каталоге: цены, описания и товары по акции.", brand.Name),
+	)
+	return meta.override(brand.SEO)
+}
+
+// CategoryMeta возвращает метаданные страницы категории
+func CategoryMeta(category models.Category) Meta {
+	meta := NewMeta(
+		category.Name,
+		fmt.Sprintf("Раздел «%s» каталога косметики: подкатегории и товары с ценами и описаниями.", category.Name),
+	)
+	return meta.override(category.SEO)
+}
+
+// SubcategoryMeta возвращает метаданные страницы подкатегории.
+// Категория подкатегории должна быть загружена
+func SubcategoryMeta(subcategory models.Subcategory) Meta {
+	meta := NewMeta(
+		subcategory.Name+" — "+subcategory.Category.Name,
+		fmt.Sprintf("%s в разделе «%s»: товары с ценами, описаниями и акциями.", subcategory.Name, subcategory.Category.Name),
+	)
+	return meta.override(subcategory.SEO)
+}
+
+// WithImage задает изображение, если у страницы его еще нет
+func (m Meta) WithImage(image string) Meta {
+	if m.Image == "" {
+		m.Image = image
+	}
+	return m
+}
+
+// WithFeed задает адрес ленты Atom страницы
+func (m Meta) WithFeed(feedURL string) Meta {
+	m.Feed = feedURL
+	return m
+}
+
+// Absolute задает адрес страницы и переводит адрес изображения в абсолютный,
+// как того требует OpenGraph. baseURL указывается без завершающего "/"
+func (m Meta) Absolute(baseURL, path string) Meta {
+	m.URL = baseURL + path
+	m.Image = AbsoluteURL(baseURL, m.Image)
+	return m
+}
+
+// AbsoluteURL переводит путь, например к изображению, в абсолютный адрес
+// с экранированием. Пустые пути и полные адреса возвращаются без изменений
+func AbsoluteURL(baseURL, path string) string {
+	if path == "" || strings.Contains(path, "://") {
+		return path
+	}
+	return baseURL + (&url.URL{Path: "/" + strings.TrimPrefix(path, "/")}).EscapedPath()
+}
+
+// override заменяет сгенерированные значения заданными вручную
+func (m Meta) override(seo models.SEO) Meta {
+	if seo.MetaTitle != "" {
+		m.Title = seo.MetaTitle
+	}
+	if seo.MetaDescription != "" {
+		m.Description = seo.MetaDescription
+	}
+	if seo.OGImage != "" {
+		m.Image = seo.OGImage
+	}
+	return m
+}
+
+// Truncate сокращает текст до длины meta description по границе слова
+func Truncate(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) <= descriptionLength {
+		return text
+	}
+
+	runes := []rune(text)[:descriptionLength-1]
+	cut := string(runes)
+	if i := strings.LastIndexByte(cut, ' '); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " ,.;:—-") + "…"
+}
+
+// formatPrice выводит цену без копеек, если они нулевые
+func formatPrice(price float64) string {
+	return strings.TrimSuffix(strconv.FormatFloat(price, 'f', 2, 64), ".00")
+}
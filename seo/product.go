@@ -0,0 +1,97 @@
+package seo
+
+import (
+	"cosmetics_catalog/models"
+	"html/template"
+	"math"
+)
+
+// Currency валюта цен каталога по ISO 4217
+const Currency = "RUB"
+
+// ProductJSONLD возвращает разметку Schema.org Product для страницы продукта:
+// бренд, изображение, предложение с ценой и средний рейтинг, если есть отзывы.
+// Бренд, подкатегория и категория продукта должны быть загружены
+func ProductJSONLD(product models.Product, rating models.Rating, baseURL string) template.JS {
+	type brand struct {
+		Type string `json:"@type"`
+		Name string `json:"name"`
+	}
+	type priceSpecification struct {
+		Type          string  `json:"@type"`
+		Price         float64 `json:"price"`
+		PriceCurrency string  `json:"priceCurrency"`
+		PriceType     string  `json:"priceType"`
+	}
+	type offer struct {
+		Type               string               `json:"@type"`
+		URL                string               `json:"url"`
+		Price              float64              `json:"price"`
+		PriceCurrency      string               `json:"priceCurrency"`
+		Availability       string               `json:"availability"`
+		PriceSpecification []priceSpecification `json:"priceSpecification,omitempty"`
+	}
+	type aggregateRating struct {
+		Type        string  `json:"@type"`
+		RatingValue float64 `json:"ratingValue"`
+		ReviewCount int64   `json:"reviewCount"`
+		BestRating  int     `json:"bestRating"`
+		WorstRating int     `json:"worstRating"`
+	}
+	type productLD struct {
+		Context         string           `json:"@context"`
+		Type            string           `json:"@type"`
+		Name            string           `json:"name"`
+		Description     string           `json:"description,omitempty"`
+		Image           string           `json:"image,omitempty"`
+		URL             string           `json:"url"`
+		Brand           *brand           `json:"brand,omitempty"`
+		Offers          offer            `json:"offers"`
+		AggregateRating *aggregateRating `json:"aggregateRating,omitempty"`
+	}
+
+	url := baseURL + product.URL()
+	ld := productLD{
+		Context:     "https://schema.org",
+		Type:        "Product",
+		Name:        product.Name,
+		Description: product.Description,
+		Image:       AbsoluteURL(baseURL, product.ImagePath),
+		URL:         url,
+		Offers: offer{
+			Type:          "Offer",
+			URL:           url,
+			Price:         product.Price,
+			PriceCurrency: Currency,
+			// Остатки в каталоге не ведутся, поэтому товар всегда в наличии
+			Availability: "https://schema.org/InStock",
+		},
+	}
+	if product.Brand.Name != "" {
+		ld.Brand = &brand{Type: "Brand", Name: product.Brand.Name}
+	}
+
+	// Для товара по акции ценой предложения считается цена со скидкой,
+	// а обычная цена указывается как цена по прайс-листу
+	if product.IsOnSale {
+		ld.Offers.Price = product.SalePrice
+		ld.Offers.PriceSpecification = []priceSpecification{{
+			Type:          "UnitPriceSpecification",
+			Price:         product.Price,
+			PriceCurrency: Currency,
+			PriceType:     "https://schema.org/ListPrice",
+		}}
+	}
+
+	if rating.Count > 0 {
+		ld.AggregateRating = &aggregateRating{
+			Type:        "AggregateRating",
+			RatingValue: math.Round(rating.Average*10) / 10,
+			ReviewCount: rating.Count,
+			BestRating:  5,
+			WorstRating: 1,
+		}
+	}
+
+	return marshalJSONLD(ld)
+}
@@ -0,0 +1,72 @@
+package seo
+
+import (
+	"encoding/xml"
+	"io"
+	"time"
+)
+
+// MaxSitemapURLs ограничение протокола Sitemaps на число адресов в одном файле.
+// Если адресов больше, /sitemap.xml становится индексом из нескольких файлов
+const MaxSitemapURLs = 50000
+
+const sitemapNamespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// SitemapURL адрес страницы или файла карты сайта с датой последнего изменения
+type SitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+
+	modified time.Time
+}
+
+// NewSitemapURL создает запись карты сайта. Нулевая дата не выводится
+func NewSitemapURL(loc string, modified time.Time) SitemapURL {
+	u := SitemapURL{Loc: loc, modified: modified}
+	if !modified.IsZero() {
+		u.LastMod = modified.UTC().Format(time.RFC3339)
+	}
+	return u
+}
+
+// LatestModified возвращает самую позднюю дату изменения среди адресов
+func LatestModified(urls []SitemapURL) time.Time {
+	var latest time.Time
+	for _, u := range urls {
+		if u.modified.After(latest) {
+			latest = u.modified
+		}
+	}
+	return latest
+}
+
+// WriteURLSet записывает карту сайта со списком страниц
+func WriteURLSet(w io.Writer, urls []SitemapURL) error {
+	return writeSitemapXML(w, struct {
+		XMLName xml.Name     `xml:"urlset"`
+		Xmlns   string       `xml:"xmlns,attr"`
+		URLs    []SitemapURL `xml:"url"`
+	}{Xmlns: sitemapNamespace, URLs: urls})
+}
+
+// WriteSitemapIndex записывает индекс, ссылающийся на файлы карты сайта
+func WriteSitemapIndex(w io.Writer, sitemaps []SitemapURL) error {
+	return writeSitemapXML(w, struct {
+		XMLName  xml.Name     `xml:"sitemapindex"`
+		Xmlns    string       `xml:"xmlns,attr"`
+		Sitemaps []SitemapURL `xml:"sitemap"`
+	}{Xmlns: sitemapNamespace, Sitemaps: sitemaps})
+}
+
+func writeSitemapXML(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,76 @@
+package session
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// CookieName имя cookie, в которой хранится идентификатор сессии
+const CookieName = "session_id"
+
+// cookieMaxAge срок жизни cookie сессии (1 год)
+const cookieMaxAge = 60 * 60 * 24 * 365
+
+// idLength длина идентификатора сессии в байтах
+const idLength = 32
+
+// ID возвращает идентификатор сессии посетителя,
+// при необходимости выдает новый и устанавливает cookie
+func ID(w http.ResponseWriter, r *http.Request) string {
+	if id := Peek(r); id != "" {
+		return id
+	}
+
+	id := newID()
+	issue(w, r, id)
+	return id
+}
+
+// Peek возвращает идентификатор сессии без создания новой.
+// Пустая строка означает, что сессии у посетителя еще нет
+func Peek(r *http.Request) string {
+	cookie, err := r.Cookie(CookieName)
+	if err != nil || !validID(cookie.Value) {
+		return ""
+	}
+	return cookie.Value
+}
+
+// issue устанавливает cookie с идентификатором сессии в ответе
+// и подменяет его в запросе, чтобы повторные вызовы вернули тот же ID
+func issue(w http.ResponseWriter, r *http.Request, id string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     CookieName,
+		Value:    id,
+		Path:     "/",
+		MaxAge:   cookieMaxAge,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	cookies := r.Cookies()
+	r.Header.Del("Cookie")
+	for _, cookie := range cookies {
+		if cookie.Name != CookieName {
+			r.AddCookie(cookie)
+		}
+	}
+	r.AddCookie(&http.Cookie{Name: CookieName, Value: id})
+}
+
+// newID генерирует случайный идентификатор сессии
+func newID() string {
+	b := make([]byte, idLength)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// validID проверяет формат идентификатора из cookie
+func validID(id string) bool {
+	if len(id) != idLength*2 {
+		return false
+	}
+	_, err := hex.DecodeString(id)
+	return err == nil
+}
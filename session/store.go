@@ -0,0 +1,135 @@
+package session
+
+import (
+	"cosmetics_catalog/models"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Store хранит данные сессий в таблице sessions
+type Store struct {
+	db *gorm.DB
+}
+
+// NewStore создает хранилище сессий в базе db
+func NewStore(db *gorm.DB) *Store {
+	return &Store{db: db}
+}
+
+// Get читает значение key из данных сессии в dst.
+// Если сессии или значения нет, dst остается без изменений
+func (s *Store) Get(r *http.Request, key string, dst any) error {
+	id := Peek(r)
+	if id == "" {
+		return nil
+	}
+
+	values, err := s.load(id)
+	if err != nil {
+		return err
+	}
+
+	raw, ok := values[key]
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(raw, dst)
+}
+
+// Set сохраняет значение key в данных сессии, при необходимости создавая сессию
+func (s *Store) Set(w http.ResponseWriter, r *http.Request, key string, value any) error {
+	id := ID(w, r)
+
+	values, err := s.load(id)
+	if err != nil {
+		return err
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	values[key] = raw
+
+	return s.save(id, values)
+}
+
+// Delete удаляет значение key из данных сессии
+func (s *Store) Delete(r *http.Request, key string) error {
+	id := Peek(r)
+	if id == "" {
+		return nil
+	}
+
+	values, err := s.load(id)
+	if err != nil {
+		return err
+	}
+	if _, ok := values[key]; !ok {
+		return nil
+	}
+	delete(values, key)
+
+	return s.save(id, values)
+}
+
+// Regenerate выдает сессии новый идентификатор, сохраняя ее данные.
+// Вызывается при входе и выходе, чтобы старый идентификатор стал бесполезен
+func (s *Store) Regenerate(w http.ResponseWriter, r *http.Request) error {
+	values := map[string]json.RawMessage{}
+
+	if oldID := Peek(r); oldID != "" {
+		var err error
+		if values, err = s.load(oldID); err != nil {
+			return err
+		}
+		if err := s.db.Delete(&models.Session{}, "id = ?", oldID).Error; err != nil {
+			return err
+		}
+	}
+
+	id := newID()
+	issue(w, r, id)
+
+	return s.save(id, values)
+}
+
+// load загружает данные сессии из базы
+func (s *Store) load(id string) (map[string]json.RawMessage, error) {
+	values := map[string]json.RawMessage{}
+
+	var sess models.Session
+	err := s.db.Where("id = ?", id).First(&sess).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return values, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if sess.Data != "" {
+		if err := json.Unmarshal([]byte(sess.Data), &values); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// save сохраняет данные сессии в базу
+func (s *Store) save(id string, values map[string]json.RawMessage) error {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+	return s.db.
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"data", "updated_at"}),
+		}).
+		Create(&models.Session{ID: id, Data: string(data)}).
+		Error
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"cosmetics_catalog/seo"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Карта сайта. Если страниц больше seo.MaxSitemapURLs,
+// возвращается индекс со ссылками на /sitemaps/{n}.xml
+func (app *App) handleSitemap(w http.ResponseWriter, r *http.Request) {
+	urls, err := app.sitemapURLs()
+	if err != nil {
+		http.Error(w, "Ошибка построения карты сайта", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	if len(urls) <= seo.MaxSitemapURLs {
+		seo.WriteURLSet(w, urls)
+		return
+	}
+
+	var sitemaps []seo.SitemapURL
+	for page := 1; (page-1)*seo.MaxSitemapURLs < len(urls); page++ {
+		loc := app.absoluteURL(fmt.Sprintf("/sitemaps/%d.xml", page))
+		sitemaps = append(sitemaps, seo.NewSitemapURL(loc, seo.LatestModified(sitemapPage(urls, page))))
+	}
+	seo.WriteSitemapIndex(w, sitemaps)
+}
+
+// Часть карты сайта из индекса
+func (app *App) handleSitemapPage(w http.ResponseWriter, r *http.Request) {
+	page, err := strconv.Atoi(strings.TrimSuffix(r.PathValue("page"), ".xml"))
+	if err != nil || page < 1 {
+		http.NotFound(w, r)
+		return
+	}
+
+	urls, err := app.sitemapURLs()
+	if err != nil {
+		http.Error(w, "Ошибка построения карты сайта", http.StatusInternalServerError)
+		return
+	}
+	chunk := sitemapPage(urls, page)
+	if len(chunk) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	seo.WriteURLSet(w, chunk)
+}
+
+// Правила для поисковых роботов. Файл из настройки robots_file
+// отдается как есть, без нее закрываются личные страницы покупателя
+func (app *App) handleRobots(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+	if path := app.config.RobotsFile; path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			http.Error(w, "Ошибка чтения robots.txt", http.StatusInternalServerError)
+			return
+		}
+		w.Write(content)
+		return
+	}
+
+	fmt.Fprintf(w, "User-agent: *\n")
+	for _, path := range []string{"/account", "/admin", "/cart", "/compare", "/wishlist"} {
+		fmt.Fprintf(w, "Disallow: %s\n", path)
+	}
+	fmt.Fprintf(w, "\nSitemap: %s\n", app.absoluteURL("/sitemap.xml"))
+}
+
+// sitemapURLs собирает адреса всех страниц каталога:
+// разделы, бренды, категории, подкатегории и продукты
+func (app *App) sitemapURLs() ([]seo.SitemapURL, error) {
+	brands, err := app.brands.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	categories, err := app.categories.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	subcategories, err := app.subcategories.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	products, err := app.products.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	// Служебные разделы меняются вместе с товарами в них
+	var catalogModified, salesModified time.Time
+	for _, product := range products {
+		if product.UpdatedAt.After(catalogModified) {
+			catalogModified = product.UpdatedAt
+		}
+		if product.IsOnSale && product.UpdatedAt.After(salesModified) {
+			salesModified = product.UpdatedAt
+		}
+	}
+
+	urls := []seo.SitemapURL{
+		seo.NewSitemapURL(app.absoluteURL("/catalog/"), catalogModified),
+		seo.NewSitemapURL(app.absoluteURL("/catalog/sales"), salesModified),
+		seo.NewSitemapURL(app.absoluteURL("/catalog/brands"), time.Time{}),
+	}
+	for _, brand := range brands {
+		urls = append(urls, seo.NewSitemapURL(app.absoluteURL(brand.URL()), brand.UpdatedAt))
+	}
+	for _, category := range categories {
+		urls = append(urls, seo.NewSitemapURL(app.absoluteURL(category.URL()), category.UpdatedAt))
+	}
+	for _, subcategory := range subcategories {
+		urls = append(urls, seo.NewSitemapURL(app.absoluteURL(subcategory.URL()), subcategory.UpdatedAt))
+	}
+	for _, product := range products {
+		urls = append(urls, seo.NewSitemapURL(app.absoluteURL(product.URL()), product.UpdatedAt))
+	}
+	return urls, nil
+}
+
+// sitemapPage возвращает адреса части карты сайта с номером page, начиная с 1
+func sitemapPage(urls []seo.SitemapURL, page int) []seo.SitemapURL {
+	start := (page - 1) * seo.MaxSitemapURLs
+	if start >= len(urls) {
+		return nil
+	}
+	return urls[start:min(start+seo.MaxSitemapURLs, len(urls))]
+}
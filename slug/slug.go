@@ -0,0 +1,102 @@
+// Package slug формирует адреса страниц из названий товаров, брендов и категорий
+package slug
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// MaxLength максимальная длина слага без суффикса уникальности
+const MaxLength = 100
+
+// Транслитерация русских букв по ГОСТ 7.79-2000 (система Б).
+// Апострофы для ъ, ь, ы и э в адресах не используются
+var cyrillic = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo",
+	'ж': "zh", 'з': "z", 'и': "i", 'й': "j", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "x", 'ц': "cz", 'ч': "ch", 'ш': "sh", 'щ': "shh", 'ъ': "",
+	'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+}
+
+// Латинские буквы с диакритикой, которые встречаются в названиях косметики
+var diacritics = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ä': "a", 'ã': "a", 'å': "a", 'æ': "ae",
+	'ç': "c", 'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ì': "i", 'í': "i",
+	'î': "i", 'ï': "i", 'ñ': "n", 'ò': "o", 'ó': "o", 'ô': "o", 'ö': "o",
+	'õ': "o", 'ø': "o", 'œ': "oe", 'ù': "u", 'ú': "u", 'û': "u", 'ü': "u",
+	'ý': "y", 'ÿ': "y", 'ß': "ss",
+}
+
+// Make преобразует название в слаг: транслитерирует кириллицу,
+// приводит к нижнему регистру и заменяет остальные символы дефисами.
+// Например, "Очищающий крем Atoderm" превращается в "ochishhayushhij-krem-atoderm"
+func Make(name string) string {
+	runes := []rune(strings.ToLower(name))
+
+	var b strings.Builder
+	dash := false
+	for i, r := range runes {
+		part, ok := cyrillic[r]
+		if !ok {
+			part, ok = diacritics[r]
+		}
+		if !ok && r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			part, ok = string(r), true
+		}
+		if !ok {
+			// Пробелы и знаки препинания превращаются в один дефис
+			dash = b.Len() > 0
+			continue
+		}
+
+		// По ГОСТ перед е, и, ы, й буква ц пишется как c
+		if r == 'ц' && i+1 < len(runes) && strings.ContainsRune("еиый", runes[i+1]) {
+			part = "c"
+		}
+		if part == "" {
+			continue
+		}
+
+		if dash {
+			b.WriteByte('-')
+			dash = false
+		}
+		b.WriteString(part)
+	}
+
+	return truncate(b.String())
+}
+
+// Unique возвращает base, если он свободен, иначе добавляет к нему
+// суффикс -2, -3 и так далее. exists сообщает, занят ли слаг
+func Unique(base string, exists func(slug string) (bool, error)) (string, error) {
+	if base == "" {
+		base = "item"
+	}
+
+	candidate := base
+	for n := 2; ; n++ {
+		taken, err := exists(candidate)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return candidate, nil
+		}
+		candidate = base + "-" + strconv.Itoa(n)
+	}
+}
+
+// truncate обрезает слаг до MaxLength по границе слова
+func truncate(s string) string {
+	if len(s) <= MaxLength {
+		return s
+	}
+	s = s[:MaxLength]
+	if i := strings.LastIndexByte(s, '-'); i > 0 {
+		s = s[:i]
+	}
+	return strings.TrimSuffix(s, "-")
+}
@@ -0,0 +1,86 @@
+package slug
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMake(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Макияж", "makiyazh"},
+		{"Очищающий крем Atoderm", "ochishhayushhij-krem-atoderm"},
+		{"Щётка для лица", "shhyotka-dlya-licza"},
+		{"Цвет", "czvet"},
+		{"Цинк", "cink"},
+		{"Объём и подъём", "obyom-i-podyom"},
+		{"Съешь же ещё этих мягких булок", "sesh-zhe-eshhyo-etix-myagkix-bulok"},
+		{"ЖИДКАЯ ПОМАДА", "zhidkaya-pomada"},
+		{"Gel-Crème Sébium", "gel-creme-sebium"},
+		{"L'Oréal Paris", "l-oreal-paris"},
+		{"Dr. Jart+", "dr-jart"},
+		{"  --Тушь!!  для   ресниц?  ", "tush-dlya-resnicz"},
+		{"SPF 50+ 30 мл", "spf-50-30-ml"},
+		{"", ""},
+		{"!!! ???", ""},
+		{"Ъ", ""},
+		{"漢字", ""},
+	}
+	for _, tt := range tests {
+		if got := Make(tt.name); got != tt.want {
+			t.Errorf("Make(%q) = %q, ожидалось %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMakeTruncatesAtWordBoundary(t *testing.T) {
+	name := strings.Repeat("слово ", 40)
+	got := Make(name)
+	if len(got) > MaxLength {
+		t.Fatalf("длина слага %d больше %d", len(got), MaxLength)
+	}
+	if strings.HasSuffix(got, "-") || !strings.HasSuffix(got, "slovo") {
+		t.Errorf("слаг обрезан не по границе слова: %q", got)
+	}
+}
+
+func TestUnique(t *testing.T) {
+	tests := []struct {
+		base  string
+		taken []string
+		want  string
+	}{
+		{"krem", nil, "krem"},
+		{"krem", []string{"krem"}, "krem-2"},
+		{"krem", []string{"krem", "krem-2", "krem-3"}, "krem-4"},
+		{"krem", []string{"krem-2"}, "krem"},
+		{"", nil, "item"},
+		{"", []string{"item"}, "item-2"},
+	}
+	for _, tt := range tests {
+		taken := map[string]bool{}
+		for _, s := range tt.taken {
+			taken[s] = true
+		}
+		got, err := Unique(tt.base, func(candidate string) (bool, error) {
+			return taken[candidate], nil
+		})
+		if err != nil {
+			t.Fatalf("Unique(%q): %v", tt.base, err)
+		}
+		if got != tt.want {
+			t.Errorf("Unique(%q) при занятых %v = %q, ожидалось %q", tt.base, tt.taken, got, tt.want)
+		}
+	}
+}
+
+func TestUniqueReturnsLookupError(t *testing.T) {
+	errLookup := errors.New("база недоступна")
+	_, err := Unique("krem", func(string) (bool, error) { return false, errLookup })
+	if !errors.Is(err, errLookup) {
+		t.Errorf("Unique вернул %v, ожидалась ошибка поиска", err)
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"cosmetics_catalog/mailer"
+	"cosmetics_catalog/models"
+	"cosmetics_catalog/repositories"
+	"cosmetics_catalog/seo"
+	"cosmetics_catalog/session"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+// Страница избранных товаров посетителя
+func (app *App) handleWishlist(w http.ResponseWriter, r *http.Request) {
+	var products []models.Product
+	if owner := app.wishlistOwner(r); owner.SessionID != "" || owner.CustomerID != 0 {
+		ids, err := app.wishlist.GetRecentProductIDs(owner)
+		if err == nil {
+			products, err = app.products.GetByIDs(ids)
+		}
+		if err != nil {
+			http.Error(w, "Ошибка получения избранного", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	tmpl, err := app.parseTemplate("wishlist.html")
+	if err != nil {
+		http.Error(w, "Ошибка загрузки шаблона", http.StatusInternalServerError)
+		return
+	}
+
+	data := struct {
+		Products    []models.Product
+		Breadcrumbs seo.Breadcrumbs
+	}{
+		Products:    products,
+		Breadcrumbs: app.newBreadcrumbs().Add("Избранное", "/wishlist"),
+	}
+
+	if err := tmpl.Execute(w, data); err != nil {
+		http.Error(w, "Ошибка рендеринга", http.StatusInternalServerError)
+	}
+}
+
+// Добавление товара в избранное или удаление из него
+func (app *App) handleWishlistToggle(w http.ResponseWriter, r *http.Request) {
+	productID, err := strconv.ParseUint(r.FormValue("product_id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Некорректный идентификатор товара", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := app.products.GetByID(uint(productID)); errors.Is(err, gorm.ErrRecordNotFound) {
+		http.NotFound(w, r)
+		return
+	} else if err != nil {
+		log.Printf("Ошибка получения товара %d для избранного: %v", productID, err)
+		http.Error(w, "Ошибка получения товара", http.StatusInternalServerError)
+		return
+	}
+
+	session.ID(w, r)
+	if _, err := app.wishlist.Toggle(app.wishlistOwner(r), uint(productID)); err != nil {
+		log.Printf("Ошибка изменения избранного, товар %d: %v", productID, err)
+		http.Error(w, "Ошибка изменения избранного", http.StatusInternalServerError)
+		return
+	}
+
+	// Возвращаем посетителя на страницу, с которой он пришел
+	http.Redirect(w, r, backURL(r, "/wishlist"), http.StatusSeeOther)
+}
+
+// wishlistOwner возвращает владельца избранного для текущего посетителя
+func (app *App) wishlistOwner(r *http.Request) repositories.WishlistOwner {
+	owner := repositories.WishlistOwner{SessionID: session.Peek(r)}
+	if customer := app.currentCustomer(r); customer != nil {
+		owner.CustomerID = customer.ID
+	}
+	return owner
+}
+
+// wishlistProductIDs возвращает ID избранных товаров текущего посетителя
+func (app *App) wishlistProductIDs(r *http.Request) map[uint]bool {
+	owner := app.wishlistOwner(r)
+	if owner.SessionID == "" && owner.CustomerID == 0 {
+		return map[uint]bool{}
+	}
+
+	ids, err := app.wishlist.GetProductIDs(owner)
+	if err != nil {
+		log.Printf("Ошибка получения избранного: %v", err)
+		return map[uint]bool{}
+	}
+	return ids
+}
+
+// saleNotifier уведомляет посетителей, добавивших товар в избранное,
+// о том, что товар появился в акции. Покупателям с аккаунтом отправляется письмо
+type saleNotifier struct {
+	wishlist *repositories.WishlistRepository
+	mailer   mailer.Mailer
+}
+
+// notify вызывается хуком продукта после фиксации транзакции, см. models.WithSaleHook
+func (n saleNotifier) notify(product *models.Product) {
+	items, err := n.wishlist.GetSubscribers(product.ID)
+	if err != nil {
+		log.Printf("Ошибка получения подписчиков товара %d: %v", product.ID, err)
+		return
+	}
+
+	notified := map[uint]bool{}
+	for _, item := range items {
+		if item.CustomerID == nil {
+			// Идентификатор сессии не пишется в журнал: по нему можно войти в чужую сессию
+			log.Printf("Товар %q из избранного (запись %d) теперь со скидкой: %.2f ₽",
+				product.Name, item.ID, product.SalePrice)
+			continue
+		}
+		if notified[*item.CustomerID] {
+			continue
+		}
+		notified[*item.CustomerID] = true
+
+		customer := item.Customer
+		if customer == nil || !customer.IsVerified() {
+			continue
+		}
+
+		err = n.mailer.Send(mailer.Message{
+			To:      customer.Email,
+			Subject: "Товар из избранного со скидкой",
+			Body: fmt.Sprintf("Здравствуйте!\n\nТовар %q из вашего избранного теперь продается со скидкой: %.2f ₽ вместо %.2f ₽.",
+				product.Name, product.SalePrice, product.Price),
+		})
+		if err != nil {
+			log.Printf("Ошибка отправки уведомления покупателю %d: %v", customer.ID, err)
+		}
+	}
+}